@@ -5,7 +5,9 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -29,11 +31,20 @@ func main() {
 	appID := os.Getenv("APPLICATION_ID")
 	guildID := os.Getenv("GUILD_ID")
 	githubToken := os.Getenv("GITHUB_TOKEN")
+	webhookSecret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	retentionDays := os.Getenv("DELETED_TASKS_RETENTION_DAYS")
+	maxInProgressTasks := os.Getenv("MAX_IN_PROGRESS_TASKS")
+	notificationChannelID := os.Getenv("NOTIFICATION_CHANNEL_ID")
+	reviewReminderHours := os.Getenv("REVIEW_REMINDER_HOURS")
+	adminRoleID := os.Getenv("BOT_ADMIN_ROLE_ID")
+	messageTemplatesPath := os.Getenv("MESSAGE_TEMPLATES_PATH")
+	defaultLocale := os.Getenv("DEFAULT_LOCALE")
+	publicURL := os.Getenv("PUBLIC_URL")
 
 	log.Println("Checking environment variables...")
-	if discordToken == "" || dbUrl == "" || appID == "" || guildID == "" || githubToken == "" {
-		log.Fatalf("Missing required environment variables: DISCORD_TOKEN=%t, DB_URL=%t, APP_ID=%t, GUILD_ID=%t, GITHUB_TOKEN=%t",
-			discordToken != "", dbUrl != "", appID != "", guildID != "", githubToken != "")
+	if discordToken == "" || dbUrl == "" || appID == "" || guildID == "" || githubToken == "" || webhookSecret == "" {
+		log.Fatalf("Missing required environment variables: DISCORD_TOKEN=%t, DB_URL=%t, APP_ID=%t, GUILD_ID=%t, GITHUB_TOKEN=%t, GITHUB_WEBHOOK_SECRET=%t",
+			discordToken != "", dbUrl != "", appID != "", guildID != "", githubToken != "", webhookSecret != "")
 	}
 	log.Println("Environment variables validated successfully")
 
@@ -44,6 +55,54 @@ func main() {
 		log.Printf("Using address: %s", address)
 	}
 
+	botOptions := &discord.BotOptions{
+		DeletedTaskRetention:  30 * 24 * time.Hour,
+		NotificationChannelID: notificationChannelID,
+		AdminRoleID:           adminRoleID,
+		MessageTemplatesPath:  messageTemplatesPath,
+		DefaultLocale:         discordgo.Locale(defaultLocale),
+		PublicURL:             publicURL,
+		WebhookSecret:         webhookSecret,
+	}
+	if retentionDays != "" {
+		days, err := strconv.Atoi(retentionDays)
+		if err != nil || days < 0 {
+			log.Fatalf("Invalid DELETED_TASKS_RETENTION_DAYS: %q", retentionDays)
+		}
+		botOptions.DeletedTaskRetention = time.Duration(days) * 24 * time.Hour
+	}
+	log.Printf("Deleted tasks retention: %s", botOptions.DeletedTaskRetention)
+	if maxInProgressTasks != "" {
+		limit, err := strconv.Atoi(maxInProgressTasks)
+		if err != nil || limit < 0 {
+			log.Fatalf("Invalid MAX_IN_PROGRESS_TASKS: %q", maxInProgressTasks)
+		}
+		botOptions.MaxInProgressTasks = limit
+		log.Printf("Max in-progress tasks per member: %d", limit)
+	}
+	if reviewReminderHours != "" {
+		hours, err := strconv.Atoi(reviewReminderHours)
+		if err != nil || hours <= 0 {
+			log.Fatalf("Invalid REVIEW_REMINDER_HOURS: %q", reviewReminderHours)
+		}
+		botOptions.ReviewReminderThreshold = time.Duration(hours) * time.Hour
+		log.Printf("Review reminder threshold: %s", botOptions.ReviewReminderThreshold)
+	}
+	if messageTemplatesPath != "" {
+		log.Printf("Message templates: %s", messageTemplatesPath)
+	}
+	if defaultLocale != "" {
+		log.Printf("Default locale: %s", defaultLocale)
+	}
+	if publicURL != "" {
+		log.Printf("Public URL: %s", publicURL)
+	} else {
+		log.Println("Public URL not specified, calendar feeds are disabled")
+	}
+	if notificationChannelID == "" {
+		log.Println("Notification channel not specified, undeliverable notifications will be dropped")
+	}
+
 	log.Println("Initializing database connection...")
 	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{
 		DriverName: "libsql",
@@ -55,7 +114,7 @@ func main() {
 	log.Println("Database connection established successfully")
 
 	log.Println("Running database migrations...")
-	err = gormDB.AutoMigrate(&db.User{}, &db.Task{}, &db.TaskComment{}, &db.WebhookSubscription{}, &db.Repository{})
+	err = db.Migrate(gormDB)
 	if err != nil {
 		log.Fatalf("Failed to run database migrations: %v", err)
 	}
@@ -75,7 +134,7 @@ func main() {
 	gc := github.NewClient(nil).WithAuthToken(githubToken)
 
 	log.Println("Creating Discord bot instance...")
-	discordBot := discord.NewDiscordBot(session, DB, appID, guildID, router, gc)
+	discordBot := discord.NewDiscordBot(session, DB, appID, guildID, router, gc, botOptions)
 
 	log.Println("Starting Discord bot...")
 	close, err := discordBot.Start(ctx)
@@ -0,0 +1,367 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// Pages of tasks returned by the API
+const (
+	apiDefaultLimit = 50
+	apiMaxLimit     = 200
+)
+
+// apiUser is a user as returned by the API
+type apiUser struct {
+	DiscordID   string `json:"discord_id"`
+	Username    string `json:"username"`
+	GitHubLogin string `json:"github_login,omitempty"`
+}
+
+func newAPIUser(user db.User) apiUser {
+	u := apiUser{DiscordID: user.DiscordID, Username: user.Username}
+	if user.GitHubLogin != nil {
+		u.GitHubLogin = *user.GitHubLogin
+	}
+	return u
+}
+
+// apiTask is a task as returned by the API
+type apiTask struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	DueDate     string     `json:"due_date,omitempty"`
+	Author      apiUser    `json:"author"`
+	Assignees   []apiUser  `json:"assignees"`
+	IssueURL    string     `json:"issue_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func newAPITask(task db.Task) apiTask {
+	t := apiTask{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Role:        task.Role,
+		Status:      task.Status,
+		Author:      newAPIUser(task.Author),
+		Assignees:   make([]apiUser, 0, len(task.AssignedUsers)),
+		IssueURL:    task.IssueURL,
+		CreatedAt:   task.CreatedAt.UTC(),
+		UpdatedAt:   task.UpdatedAt.UTC(),
+	}
+	if task.DueDate != nil {
+		t.DueDate = task.DueDate.Format(db.DueDateFormat)
+	}
+	for _, user := range task.AssignedUsers {
+		t.Assignees = append(t.Assignees, newAPIUser(user))
+	}
+	if task.CompletedAt != nil {
+		completedAt := task.CompletedAt.UTC()
+		t.CompletedAt = &completedAt
+	}
+	return t
+}
+
+// apiTaskPage is a page of the tasks selected by a request
+type apiTaskPage struct {
+	Tasks  []apiTask `json:"tasks"`
+	Total  int64     `json:"total"`
+	Offset int       `json:"offset"`
+	Limit  int       `json:"limit"`
+}
+
+// apiError is the body of the API responses to failed requests
+type apiError struct {
+	Error string `json:"error"`
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error writing API response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, apiError{Error: message})
+}
+
+func (b *DiscordBot) initAPIHandlers() {
+	api := b.router.PathPrefix("/api").Subrouter()
+	api.Use(b.requireAPIToken)
+	api.HandleFunc("/tasks", b.apiTasks).Methods("GET")
+	api.HandleFunc("/tasks", b.apiCreateTask).Methods("POST")
+	api.HandleFunc("/tasks/{id:[0-9]+}", b.apiTask).Methods("GET")
+	api.HandleFunc("/tasks/{id:[0-9]+}", b.apiUpdateTask).Methods("PATCH")
+	api.HandleFunc("/users/{discordId}/tasks", b.apiUserTasks).Methods("GET")
+	api.HandleFunc("/roles/{role}/tasks", b.apiRoleTasks).Methods("GET")
+}
+
+// requireAPIToken rejects the requests without a valid API token, passed as
+// a bearer token
+func (b *DiscordBot) requireAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, "missing API token")
+			return
+		}
+
+		apiToken, err := b.db.AuthenticateAPIToken(strings.TrimSpace(token), time.Now())
+		if errors.Is(err, db.ErrAPITokenNotFound) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeAPIError(w, http.StatusUnauthorized, "invalid API token")
+			return
+		}
+		if err != nil {
+			log.Printf("Error authenticating API token: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "failed to authenticate API token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenContextKey{}, apiToken)))
+	})
+}
+
+// apiTokenContextKey is the context key of the token of an API request
+type apiTokenContextKey struct{}
+
+// requestAPIToken returns the token a request was authenticated with
+func requestAPIToken(r *http.Request) *db.APIToken {
+	apiToken, _ := r.Context().Value(apiTokenContextKey{}).(*db.APIToken)
+	return apiToken
+}
+
+// parseAPIStatus returns the status named by value, ignoring its case and
+// accepting dashes and underscores for spaces, e.g. "in-progress"
+func parseAPIStatus(value string) (string, bool) {
+	value = strings.NewReplacer("-", " ", "_", " ").Replace(strings.TrimSpace(value))
+	for _, status := range []string{db.TASK_NOT_STARTED, db.TASK_IN_PROGRESS, db.TASK_COMPLETED} {
+		if strings.EqualFold(value, status) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// parseAPIDate parses a date of a query parameter, as a due date or an RFC
+// 3339 time. Times are moved to the local time zone, the one of the dates
+// stored in the database, which compares them as text.
+func parseAPIDate(value string) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		t = t.In(time.Local)
+		return &t, nil
+	}
+	return parseDueDate(value)
+}
+
+// apiUserID returns the ID of the user with a Discord ID, or 0 if no user
+// has it
+func (b *DiscordBot) apiUserID(discordID string) (uint, error) {
+	user, err := b.db.GetUserByDiscordID(discordID, nil)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// taskQuery is a request for a page of tasks
+type taskQuery struct {
+	filter db.TaskFilter
+	// assignee and author are Discord IDs, resolved to users by
+	// resolveTaskQuery
+	assignee string
+	author   string
+	offset   int
+	limit    int
+}
+
+// parseTaskQuery reads the filter and the page of a request for tasks. The
+// filter adds to the given one, whose fields can't be changed by the query.
+func parseTaskQuery(r *http.Request, filter db.TaskFilter) (*taskQuery, error) {
+	query := r.URL.Query()
+	q := &taskQuery{filter: filter, limit: apiDefaultLimit}
+
+	for _, values := range query["status"] {
+		for _, value := range strings.Split(values, ",") {
+			status, ok := parseAPIStatus(value)
+			if !ok {
+				return nil, fmt.Errorf("invalid status %q", value)
+			}
+			q.filter.Statuses = append(q.filter.Statuses, status)
+		}
+	}
+
+	if q.filter.Role == "" {
+		q.filter.Role = query.Get("role")
+	}
+	if q.filter.AssigneeID == 0 {
+		q.assignee = query.Get("assignee")
+	}
+	q.author = query.Get("author")
+
+	if value := query.Get("unassigned"); value != "" {
+		unassigned, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unassigned %q", value)
+		}
+		q.filter.Unassigned = unassigned
+	}
+
+	for _, param := range []struct {
+		name string
+		date **time.Time
+	}{
+		{"due_after", &q.filter.DueAfter},
+		{"due_before", &q.filter.DueBefore},
+		{"updated_since", &q.filter.UpdatedSince},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := parseAPIDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD or an RFC 3339 time", param.name, value)
+		}
+		*param.date = date
+	}
+
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid offset %q", value)
+		}
+		q.offset = offset
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > apiMaxLimit {
+			return nil, fmt.Errorf("invalid limit %q, expected 1 to %d", value, apiMaxLimit)
+		}
+		q.limit = limit
+	}
+
+	return q, nil
+}
+
+// resolveTaskQuery resolves the users of a query. It returns false if one of
+// them doesn't exist, as the query then selects no task.
+func (b *DiscordBot) resolveTaskQuery(q *taskQuery) (bool, error) {
+	for _, user := range []struct {
+		discordID string
+		id        *uint
+	}{
+		{q.assignee, &q.filter.AssigneeID},
+		{q.author, &q.filter.AuthorID},
+	} {
+		if user.discordID == "" {
+			continue
+		}
+		id, err := b.apiUserID(user.discordID)
+		if err != nil || id == 0 {
+			return false, err
+		}
+		*user.id = id
+	}
+	return true, nil
+}
+
+// writeTaskPage writes the page of tasks selected by a request and a filter
+func (b *DiscordBot) writeTaskPage(w http.ResponseWriter, r *http.Request, filter db.TaskFilter) {
+	q, err := parseTaskQuery(r, filter)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page := apiTaskPage{Tasks: make([]apiTask, 0), Offset: q.offset, Limit: q.limit}
+	found, err := b.resolveTaskQuery(q)
+	if err != nil {
+		log.Printf("Error getting users for the API: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to get tasks")
+		return
+	}
+	if !found {
+		writeAPIJSON(w, http.StatusOK, page)
+		return
+	}
+
+	tasks, total, err := b.db.FindTasks(q.filter, q.offset, q.limit)
+	if err != nil {
+		log.Printf("Error finding tasks for the API: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to get tasks")
+		return
+	}
+	for _, task := range tasks {
+		page.Tasks = append(page.Tasks, newAPITask(task))
+	}
+	page.Total = total
+	writeAPIJSON(w, http.StatusOK, page)
+}
+
+func (b *DiscordBot) apiTasks(w http.ResponseWriter, r *http.Request) {
+	b.writeTaskPage(w, r, db.TaskFilter{})
+}
+
+func (b *DiscordBot) apiTask(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	task, err := b.db.GetTaskByID(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeAPIError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting task %d for the API: %v", id, err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to get task")
+		return
+	}
+
+	writeAPIJSON(w, http.StatusOK, newAPITask(*task))
+}
+
+func (b *DiscordBot) apiUserTasks(w http.ResponseWriter, r *http.Request) {
+	userID, err := b.apiUserID(mux.Vars(r)["discordId"])
+	if err != nil {
+		log.Printf("Error getting user for the API: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to get user")
+		return
+	}
+	if userID == 0 {
+		writeAPIError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	b.writeTaskPage(w, r, db.TaskFilter{AssigneeID: userID})
+}
+
+func (b *DiscordBot) apiRoleTasks(w http.ResponseWriter, r *http.Request) {
+	b.writeTaskPage(w, r, db.TaskFilter{Role: mux.Vars(r)["role"]})
+}
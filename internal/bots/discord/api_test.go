@@ -0,0 +1,402 @@
+package discord
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIToken      = "king_0123456789abcdef"
+	testWriteAPIToken = "king_fedcba9876543210"
+)
+
+// newTestAPI returns a bot serving the API, with a read-only and a write
+// token of the author of the tasks
+func newTestAPI(t *testing.T) (*DiscordBot, *fakeSession, []*db.Task) {
+	bot, session := newTestBot(t)
+	bot.router = mux.NewRouter()
+	bot.initAPIHandlers()
+
+	author := createTestUser(t, bot.db, "111", "author")
+	createTestUser(t, bot.db, "2", "bob")
+	_, err := bot.db.CreateAPIToken("tests", testAPIToken, author.ID, false)
+	require.NoError(t, err)
+	_, err = bot.db.CreateAPIToken("scripts", testWriteAPIToken, author.ID, true)
+	require.NoError(t, err)
+
+	tasks := []*db.Task{
+		createTestTask(t, bot.db, "Wire the BMS", testRole.Name, "2"),
+		createTestTask(t, bot.db, "Calibrate the IMU", testRole.Name, ""),
+		createTestTask(t, bot.db, "Paint the livery", "Design", "2"),
+	}
+	_, err = bot.db.UpdateTaskStatus(tasks[2].ID, db.TASK_COMPLETED)
+	require.NoError(t, err)
+	return bot, session, tasks
+}
+
+// apiRequest serves a GET request to the API with a token
+func apiRequest(bot *DiscordBot, path string, token string) *httptest.ResponseRecorder {
+	return apiWriteRequest(bot, http.MethodGet, path, token, "")
+}
+
+// apiWriteRequest serves a request with a JSON body to the API with a token
+func apiWriteRequest(bot *DiscordBot, method string, path string, token string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	bot.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// getTaskPage requests a page of tasks and returns the titles in it
+func getTaskPage(t *testing.T, bot *DiscordBot, path string) (apiTaskPage, []string) {
+	t.Helper()
+	rec := apiRequest(bot, path, testAPIToken)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	page := apiTaskPage{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	titles := make([]string, 0, len(page.Tasks))
+	for _, task := range page.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return page, titles
+}
+
+func TestAPIAuthentication(t *testing.T) {
+	bot, _, _ := newTestAPI(t)
+
+	rec := apiRequest(bot, "/api/tasks", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+
+	rec = apiRequest(bot, "/api/tasks", "king_wrong")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid API token")
+
+	rec = apiRequest(bot, "/api/tasks", testAPIToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Revoked tokens stop working
+	require.NoError(t, bot.db.DeleteAPIToken("tests"))
+	rec = apiRequest(bot, "/api/tasks", testAPIToken)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAPITasks(t *testing.T) {
+	bot, _, _ := newTestAPI(t)
+
+	for _, tt := range []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{"all", "/api/tasks", []string{"Wire the BMS", "Calibrate the IMU", "Paint the livery"}},
+		{"role", "/api/tasks?role=Design", []string{"Paint the livery"}},
+		{"statuses", "/api/tasks?status=not-started,completed", []string{"Wire the BMS", "Calibrate the IMU", "Paint the livery"}},
+		{"repeated status", "/api/tasks?status=Completed&status=in_progress", []string{"Paint the livery"}},
+		{"assignee", "/api/tasks?assignee=2", []string{"Wire the BMS", "Paint the livery"}},
+		{"unknown assignee", "/api/tasks?assignee=404", []string{}},
+		{"author", "/api/tasks?author=111&status=not-started", []string{"Wire the BMS", "Calibrate the IMU"}},
+		{"unassigned", "/api/tasks?unassigned=true", []string{"Calibrate the IMU"}},
+		{"due before", "/api/tasks?due_before=2000-01-01", []string{}},
+		{"updated since", "/api/tasks?updated_since=2000-01-01T00:00:00Z&role=Electronics", []string{"Wire the BMS", "Calibrate the IMU"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, titles := getTaskPage(t, bot, tt.path)
+			assert.Equal(t, tt.expected, titles)
+		})
+	}
+
+	t.Run("task fields", func(t *testing.T) {
+		page, _ := getTaskPage(t, bot, "/api/tasks?status=completed")
+		require.Len(t, page.Tasks, 1)
+		task := page.Tasks[0]
+		assert.Equal(t, db.TASK_COMPLETED, task.Status)
+		assert.Equal(t, "author", task.Author.Username)
+		assert.Equal(t, []apiUser{{DiscordID: "2", Username: "bob"}}, task.Assignees)
+		assert.NotNil(t, task.CompletedAt)
+	})
+
+	for _, path := range []string{
+		"/api/tasks?status=blocked",
+		"/api/tasks?limit=0",
+		"/api/tasks?limit=1000",
+		"/api/tasks?offset=-1",
+		"/api/tasks?due_after=02/11/2026",
+		"/api/tasks?unassigned=maybe",
+	} {
+		t.Run(path, func(t *testing.T) {
+			rec := apiRequest(bot, path, testAPIToken)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.True(t, strings.HasPrefix(rec.Body.String(), `{"error":"invalid `), rec.Body.String())
+		})
+	}
+}
+
+func TestAPIDateOffset(t *testing.T) {
+	bot, _, tasks := newTestAPI(t)
+	dueDate, err := parseDueDate("2026-11-02")
+	require.NoError(t, err)
+	_, _, err = bot.db.UpdateTask(tasks[0].ID, tasks[0].AuthorID, &db.TaskUpdateOptions{DueDate: dueDate})
+	require.NoError(t, err)
+
+	// An hour before the due date, in a time zone ahead of the local one, so
+	// that its text sorts after the stored due date
+	_, offset := dueDate.Zone()
+	zone := time.FixedZone("ahead", offset+3*60*60)
+	before := url.QueryEscape(dueDate.Add(-time.Hour).In(zone).Format(time.RFC3339))
+	after := url.QueryEscape(dueDate.Add(time.Hour).In(zone).Format(time.RFC3339))
+
+	_, titles := getTaskPage(t, bot, "/api/tasks?due_after="+before)
+	assert.Equal(t, []string{"Wire the BMS"}, titles)
+	_, titles = getTaskPage(t, bot, "/api/tasks?due_before="+after)
+	assert.Equal(t, []string{"Wire the BMS"}, titles)
+	_, titles = getTaskPage(t, bot, "/api/tasks?due_after="+after)
+	assert.Empty(t, titles)
+}
+
+func TestAPIPagination(t *testing.T) {
+	bot, _, _ := newTestAPI(t)
+
+	page, titles := getTaskPage(t, bot, "/api/tasks?limit=2")
+	assert.Equal(t, []string{"Wire the BMS", "Calibrate the IMU"}, titles)
+	assert.Equal(t, int64(3), page.Total)
+	assert.Equal(t, 0, page.Offset)
+	assert.Equal(t, 2, page.Limit)
+
+	page, titles = getTaskPage(t, bot, "/api/tasks?limit=2&offset=2")
+	assert.Equal(t, []string{"Paint the livery"}, titles)
+	assert.Equal(t, int64(3), page.Total)
+
+	page, titles = getTaskPage(t, bot, "/api/tasks?offset=10")
+	assert.Empty(t, titles)
+	assert.Equal(t, apiDefaultLimit, page.Limit)
+}
+
+func TestAPITask(t *testing.T) {
+	bot, _, tasks := newTestAPI(t)
+
+	rec := apiRequest(bot, fmt.Sprintf("/api/tasks/%d", tasks[0].ID), testAPIToken)
+	require.Equal(t, http.StatusOK, rec.Code)
+	task := apiTask{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+	assert.Equal(t, tasks[0].ID, task.ID)
+	assert.Equal(t, "Wire the BMS", task.Title)
+	assert.Equal(t, testRole.Name, task.Role)
+
+	rec = apiRequest(bot, "/api/tasks/999", testAPIToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPIUserAndRoleTasks(t *testing.T) {
+	bot, _, _ := newTestAPI(t)
+
+	_, titles := getTaskPage(t, bot, "/api/users/2/tasks")
+	assert.Equal(t, []string{"Wire the BMS", "Paint the livery"}, titles)
+
+	// The user of the path can't be changed by the query
+	_, titles = getTaskPage(t, bot, "/api/users/2/tasks?assignee=111&status=completed")
+	assert.Equal(t, []string{"Paint the livery"}, titles)
+
+	rec := apiRequest(bot, "/api/users/404/tasks", testAPIToken)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	_, titles = getTaskPage(t, bot, "/api/roles/Electronics/tasks?role=Design")
+	assert.Equal(t, []string{"Wire the BMS", "Calibrate the IMU"}, titles)
+
+	_, titles = getTaskPage(t, bot, "/api/roles/Aero/tasks")
+	assert.Empty(t, titles)
+}
+
+func TestAPITokenCommands(t *testing.T) {
+	bot, session := newTestBot(t)
+	bot.router = mux.NewRouter()
+	bot.initAPIHandlers()
+	admin := testMember("9", "root", discordgo.PermissionAdministrator)
+
+	bot.handleInteraction(session, commandInteraction(CommandCreateAPIToken, admin, stringOption("name", "grafana")))
+	content := session.content(t)
+	assert.Contains(t, content, "API token created")
+	token := apiTokenPrefix + strings.Split(strings.SplitN(content, "`"+apiTokenPrefix, 2)[1], "`")[0]
+
+	assert.Equal(t, http.StatusOK, apiRequest(bot, "/api/tasks", token).Code)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandListAPITokens, admin))
+	assert.Contains(t, session.content(t), token[:12])
+	assert.NotContains(t, session.content(t), token)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRevokeAPIToken, admin, stringOption("name", "grafana")))
+	assert.Contains(t, session.content(t), "API token revoked")
+	assert.Equal(t, http.StatusUnauthorized, apiRequest(bot, "/api/tasks", token).Code)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandCreateAPIToken, testMember("1", "alice", 0), stringOption("name", "grafana")))
+	assert.Contains(t, session.content(t), "Access denied")
+	tokens, err := bot.db.GetAPITokens()
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+// decodeAPITask decodes a task returned by the API
+func decodeAPITask(t *testing.T, rec *httptest.ResponseRecorder) apiTask {
+	t.Helper()
+	task := apiTask{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task), rec.Body.String())
+	return task
+}
+
+func TestAPICreateTask(t *testing.T) {
+	bot, session, _ := newTestAPI(t)
+	_, err := bot.db.CreateTaskWebhook("https://sheets.example.com/hook", "secret", 1)
+	require.NoError(t, err)
+
+	t.Run("read-only token", func(t *testing.T) {
+		rec := apiWriteRequest(bot, http.MethodPost, "/api/tasks", testAPIToken, `{"title":"Order bolts"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("created", func(t *testing.T) {
+		session.reset()
+		rec := apiWriteRequest(bot, http.MethodPost, "/api/tasks", testWriteAPIToken,
+			`{"title":" Order bolts ","description":"M6, 50 pieces","role":"Electronics","due_date":"2026-11-02","assignee":"2"}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		task := decodeAPITask(t, rec)
+		assert.Equal(t, fmt.Sprintf("/api/tasks/%d", task.ID), rec.Header().Get("Location"))
+		assert.Equal(t, "Order bolts", task.Title)
+		assert.Equal(t, db.TASK_NOT_STARTED, task.Status)
+		assert.Equal(t, "2026-11-02", task.DueDate)
+		// The author defaults to the user who created the token
+		assert.Equal(t, "111", task.Author.DiscordID)
+		assert.Equal(t, []apiUser{{DiscordID: "2", Username: "bob"}}, task.Assignees)
+
+		// The assignee is notified like with /create-task
+		require.Len(t, session.messagesTo(dmChannelID("2")), 1)
+		assert.Contains(t, session.messagesTo(dmChannelID("2"))[0].Content, "Order bolts")
+
+		deliveries, err := bot.db.GetDueTaskWebhookDeliveries(time.Now(), 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, db.TASK_EVENT_CREATED, deliveries[0].Event)
+		assert.Contains(t, deliveries[0].Payload, `"title":"Order bolts"`)
+	})
+
+	t.Run("author", func(t *testing.T) {
+		rec := apiWriteRequest(bot, http.MethodPost, "/api/tasks", testWriteAPIToken, `{"title":"Order nuts","author":"2"}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Equal(t, "bob", decodeAPITask(t, rec).Author.Username)
+	})
+
+	for _, tt := range []struct {
+		name  string
+		body  string
+		error string
+	}{
+		{"missing title", `{"description":"No title"}`, "title is required"},
+		{"long title", `{"title":"` + strings.Repeat("a", taskTitleMaxLength+1) + `"}`, "title is longer than 256 characters"},
+		{"long description", `{"title":"Order bolts","description":"` + strings.Repeat("a", taskDescriptionMaxLength+1) + `"}`, "description is longer than 4000 characters"},
+		{"unknown field", `{"title":"Order bolts","priority":"high"}`, "invalid request body"},
+		{"malformed", `{"title":`, "invalid request body"},
+		{"invalid due date", `{"title":"Order bolts","due_date":"02/11/2026"}`, "invalid due_date"},
+		{"unknown assignee", `{"title":"Order bolts","assignee":"404"}`, `unknown assignee \"404\"`},
+		{"unknown author", `{"title":"Order bolts","author":"404"}`, `unknown author \"404\"`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiWriteRequest(bot, http.MethodPost, "/api/tasks", testWriteAPIToken, tt.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.error)
+		})
+	}
+}
+
+func TestAPIUpdateTask(t *testing.T) {
+	bot, session, tasks := newTestAPI(t)
+	path := fmt.Sprintf("/api/tasks/%d", tasks[1].ID)
+	createTestUser(t, bot.db, "3", "carol")
+
+	t.Run("read-only token", func(t *testing.T) {
+		rec := apiWriteRequest(bot, http.MethodPatch, path, testAPIToken, `{"status":"completed"}`)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("status and assignee", func(t *testing.T) {
+		session.reset()
+		rec := apiWriteRequest(bot, http.MethodPatch, path, testWriteAPIToken, `{"status":"in-progress","assignee":"3"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		task := decodeAPITask(t, rec)
+		assert.Equal(t, db.TASK_IN_PROGRESS, task.Status)
+		assert.Equal(t, []apiUser{{DiscordID: "3", Username: "carol"}}, task.Assignees)
+
+		// The author hears about the status, the new assignee about both
+		assert.Len(t, session.messagesTo(dmChannelID("111")), 1)
+		assert.Len(t, session.messagesTo(dmChannelID("3")), 2)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		session.reset()
+		rec := apiWriteRequest(bot, http.MethodPatch, path, testWriteAPIToken, `{"status":"In Progress","assignee":"3"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Len(t, decodeAPITask(t, rec).Assignees, 1)
+		assert.Empty(t, session.messagesTo(dmChannelID("3")))
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		session.reset()
+		rec := apiWriteRequest(bot, http.MethodPatch, path, testWriteAPIToken, `{"title":"Calibrate the IMU again","due_date":"2026-11-02"}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		task := decodeAPITask(t, rec)
+		assert.Equal(t, "Calibrate the IMU again", task.Title)
+		assert.Equal(t, "2026-11-02", task.DueDate)
+		assert.Len(t, session.messagesTo(dmChannelID("3")), 1)
+
+		rec = apiWriteRequest(bot, http.MethodPatch, path, testWriteAPIToken, `{"due_date":""}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Empty(t, decodeAPITask(t, rec).DueDate)
+	})
+
+	for _, tt := range []struct {
+		name  string
+		body  string
+		error string
+	}{
+		{"nothing", `{}`, "nothing to update"},
+		{"empty title", `{"title":" "}`, "title cannot be empty"},
+		{"long title", `{"title":"` + strings.Repeat("è", taskTitleMaxLength+1) + `"}`, "title is longer than 256 characters"},
+		{"long description", `{"description":"` + strings.Repeat("a", taskDescriptionMaxLength+1) + `"}`, "description is longer than 4000 characters"},
+		{"invalid status", `{"status":"blocked"}`, "invalid status"},
+		{"unknown assignee", `{"status":"completed","assignee":"404"}`, "unknown assignee"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiWriteRequest(bot, http.MethodPatch, path, testWriteAPIToken, tt.body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.error)
+		})
+	}
+
+	// Invalid requests change nothing
+	task, err := bot.db.GetTaskByID(tasks[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.TASK_IN_PROGRESS, task.Status)
+
+	rec := apiWriteRequest(bot, http.MethodPatch, "/api/tasks/999", testWriteAPIToken, `{"status":"completed"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
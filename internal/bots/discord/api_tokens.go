@@ -0,0 +1,197 @@
+package discord
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+// apiTokenPrefix starts the API tokens, so that leaked ones are easy to spot
+const apiTokenPrefix = "king_"
+
+func newAPIToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(buf), nil
+}
+
+func (b *DiscordBot) createAPITokenCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandCreateAPIToken {
+		return
+	}
+
+	if !isAdmin(i) || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "api-token.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	name := ""
+	write := false
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "name":
+			name = opt.StringValue()
+		case "write":
+			write = opt.BoolValue()
+		}
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[create-api-token] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "api-token.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	token, err := newAPIToken()
+	if err == nil {
+		_, err = b.db.CreateAPIToken(name, token, user.ID, write)
+	}
+	if errors.Is(err, db.ErrAPITokenNameTaken) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "create-api-token.name-taken", messageData{"Name": name}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	if err != nil {
+		fmt.Printf("[create-api-token] Failed to create API token %q: %v\n", name, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "api-token.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[create-api-token] API token %q created by %s, write access: %t\n", name, i.Member.User.Username, write)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: b.render(i.Locale, "create-api-token.success", messageData{"Name": name, "Token": token, "Write": write}),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (b *DiscordBot) listAPITokensCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandListAPITokens {
+		return
+	}
+
+	if !isAdmin(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "api-token.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	tokens, err := b.db.GetAPITokens()
+	if err != nil {
+		fmt.Printf("[list-api-tokens] Failed to get API tokens: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "api-token.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	respContent := b.render(i.Locale, "list-api-tokens.none", nil)
+	if len(tokens) > 0 {
+		respContent = b.render(i.Locale, "list-api-tokens.list", messageData{"Tokens": tokens})
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (b *DiscordBot) revokeAPITokenCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandRevokeAPIToken {
+		return
+	}
+
+	if !isAdmin(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "api-token.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	name := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "name" {
+			name = opt.StringValue()
+		}
+	}
+
+	var respContent string
+	err := b.db.DeleteAPIToken(name)
+	switch {
+	case errors.Is(err, db.ErrAPITokenNotFound):
+		respContent = b.render(i.Locale, "revoke-api-token.not-found", messageData{"Name": name})
+	case err != nil:
+		fmt.Printf("[revoke-api-token] Failed to delete API token %q: %v\n", name, err)
+		respContent = b.render(i.Locale, "api-token.failed", nil)
+	default:
+		fmt.Printf("[revoke-api-token] API token %q revoked\n", name)
+		respContent = b.render(i.Locale, "revoke-api-token.success", messageData{"Name": name})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,286 @@
+package discord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// apiMaxBodySize limits the body of the API requests
+const apiMaxBodySize = 64 << 10
+
+// apiCreateTaskRequest is the body of a request creating a task. The author
+// and the assignee are Discord IDs, and the author defaults to the user who
+// created the API token.
+type apiCreateTaskRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Role        string `json:"role"`
+	DueDate     string `json:"due_date"`
+	Author      string `json:"author"`
+	Assignee    string `json:"assignee"`
+}
+
+// apiUpdateTaskRequest is the body of a request updating a task. The fields
+// left out are not changed, an empty due date clears it, and the assignee
+// is the Discord ID of a user added to the assignees.
+type apiUpdateTaskRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	DueDate     *string `json:"due_date"`
+	Status      *string `json:"status"`
+	Assignee    *string `json:"assignee"`
+}
+
+// readAPIRequest decodes the JSON body of a request, rejecting unknown fields
+func readAPIRequest(w http.ResponseWriter, r *http.Request, value any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+// requireAPIWrite rejects the requests of read-only tokens, and returns the
+// token of the others
+func requireAPIWrite(w http.ResponseWriter, r *http.Request) (*db.APIToken, bool) {
+	apiToken := requestAPIToken(r)
+	if apiToken == nil || !apiToken.Write {
+		writeAPIError(w, http.StatusForbidden, "the API token is read-only")
+		return nil, false
+	}
+	return apiToken, true
+}
+
+// writeAPITextTooLong rejects a request with a title or a description longer
+// than its limit
+func writeAPITextTooLong(w http.ResponseWriter, field string) {
+	limit := taskTitleMaxLength
+	if field == "description" {
+		limit = taskDescriptionMaxLength
+	}
+	writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("%s is longer than %d characters", field, limit))
+}
+
+func (b *DiscordBot) apiCreateTask(w http.ResponseWriter, r *http.Request) {
+	apiToken, ok := requireAPIWrite(w, r)
+	if !ok {
+		return
+	}
+
+	req := apiCreateTaskRequest{}
+	if err := readAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task := &db.Task{Title: strings.TrimSpace(req.Title), Description: req.Description, Role: req.Role}
+	if task.Title == "" {
+		writeAPIError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	if field := taskTextTooLong(task.Title, task.Description); field != "" {
+		writeAPITextTooLong(w, field)
+		return
+	}
+	if req.DueDate != "" {
+		dueDate, err := parseDueDate(req.DueDate)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid due_date %q, expected YYYY-MM-DD", req.DueDate))
+			return
+		}
+		task.DueDate = dueDate
+	}
+
+	author := req.Author
+	if author == "" {
+		author = apiToken.CreatedBy.DiscordID
+	}
+	for _, user := range []struct {
+		field     string
+		discordID string
+	}{
+		{"author", author},
+		{"assignee", req.Assignee},
+	} {
+		if user.discordID == "" {
+			continue
+		}
+		userID, err := b.apiUserID(user.discordID)
+		if err != nil {
+			log.Printf("Error getting user for the API: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "failed to create task")
+			return
+		}
+		if userID == 0 {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("unknown %s %q", user.field, user.discordID))
+			return
+		}
+	}
+
+	if err := b.db.CreateTaskWithUserDiscordID(task, author, req.Assignee); err != nil {
+		log.Printf("Error creating task through the API: %v", err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to create task")
+		return
+	}
+	created, err := b.db.GetTaskByID(task.ID)
+	if err != nil {
+		log.Printf("Error getting task %d created through the API: %v", task.ID, err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to get task")
+		return
+	}
+
+	log.Printf("Task %d created through the API with token %q", created.ID, apiToken.Name)
+	w.Header().Set("Location", fmt.Sprintf("/api/tasks/%d", created.ID))
+	writeAPIJSON(w, http.StatusCreated, newAPITask(*created))
+
+	if req.Assignee != "" {
+		b.notifyTaskAssigned(created, req.Assignee)
+	}
+	b.emitTaskEvent(db.TASK_EVENT_CREATED, created)
+}
+
+func (b *DiscordBot) apiUpdateTask(w http.ResponseWriter, r *http.Request) {
+	apiToken, ok := requireAPIWrite(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	req := apiUpdateTaskRequest{}
+	if err := readAPIRequest(w, r, &req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := b.db.GetTaskByID(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeAPIError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting task %d for the API: %v", id, err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to get task")
+		return
+	}
+
+	// Everything is validated before the task is changed
+	patch := &db.TaskPatch{}
+	updateOptions := &patch.TaskUpdateOptions
+	edited := false
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			writeAPIError(w, http.StatusBadRequest, "title cannot be empty")
+			return
+		}
+		if taskTextTooLong(title, "") != "" {
+			writeAPITextTooLong(w, "title")
+			return
+		}
+		updateOptions.Title = &title
+		edited = true
+	}
+	if req.Description != nil {
+		if taskTextTooLong("", *req.Description) != "" {
+			writeAPITextTooLong(w, "description")
+			return
+		}
+		updateOptions.Description = req.Description
+		edited = true
+	}
+	if req.DueDate != nil {
+		if *req.DueDate == "" {
+			updateOptions.ClearDueDate = true
+		} else {
+			dueDate, err := parseDueDate(*req.DueDate)
+			if err != nil {
+				writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid due_date %q, expected YYYY-MM-DD", *req.DueDate))
+				return
+			}
+			updateOptions.DueDate = dueDate
+		}
+		edited = true
+	}
+
+	status := task.Status
+	if req.Status != nil {
+		if status, ok = parseAPIStatus(*req.Status); !ok {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("invalid status %q", *req.Status))
+			return
+		}
+	}
+
+	var assignee *db.User
+	if req.Assignee != nil {
+		assignee, err = b.db.GetUserByDiscordID(*req.Assignee, nil)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("unknown assignee %q", *req.Assignee))
+			return
+		}
+		if err != nil {
+			log.Printf("Error getting user for the API: %v", err)
+			writeAPIError(w, http.StatusInternalServerError, "failed to update task")
+			return
+		}
+		if slices.ContainsFunc(task.AssignedUsers, func(user db.User) bool { return user.ID == assignee.ID }) {
+			assignee = nil
+		}
+	}
+
+	if !edited && req.Status == nil && req.Assignee == nil {
+		writeAPIError(w, http.StatusBadRequest, "nothing to update")
+		return
+	}
+
+	// The changes are made on behalf of the user who created the API token,
+	// all at once, so that a failure doesn't leave some of them made without
+	// their notifications
+	editor := apiToken.CreatedBy
+
+	statusChanged := status != task.Status
+	if statusChanged {
+		patch.Status = &status
+	}
+	if assignee != nil {
+		patch.AssigneeID = assignee.ID
+	}
+
+	task, changes, err := b.db.PatchTask(task.ID, editor.ID, patch)
+	if err != nil {
+		log.Printf("Error updating task %d through the API: %v", id, err)
+		writeAPIError(w, http.StatusInternalServerError, "failed to update task")
+		return
+	}
+
+	log.Printf("Task %d updated through the API with token %q", task.ID, apiToken.Name)
+	writeAPIJSON(w, http.StatusOK, newAPITask(*task))
+
+	if len(changes) > 0 {
+		b.notifyTaskEdited(task, changes, editor.DiscordID)
+	}
+	if statusChanged {
+		b.notifyTaskStatusUpdate(task, editor.DiscordID)
+		b.onTaskStatusChanged(task)
+		b.emitTaskEvent(db.TASK_EVENT_STATUS_CHANGED, task)
+	}
+	if assignee != nil {
+		b.notifyTaskAssigned(task, assignee.DiscordID)
+		b.emitTaskEvent(db.TASK_EVENT_ASSIGNED, task)
+	}
+}
@@ -0,0 +1,222 @@
+package discord
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/mux"
+)
+
+// The calendar feeds are iCalendar (RFC 5545) files with an all-day event
+// on the due date of each open task. The events keep the ID of their task,
+// so calendar apps move them when the due date changes, and drop them when
+// the task is completed or deleted and leaves the feed.
+const (
+	calendarMaxEvents = 500
+
+	// calendarLineLength is the maximum length of a line in octets, without
+	// the line break
+	calendarLineLength = 75
+	calendarDateFormat = "20060102"
+	calendarTimeFormat = "20060102T150405Z"
+)
+
+func newCalendarToken() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// calendarURL returns the URL of the feed with a token
+func (b *DiscordBot) calendarURL(token string) string {
+	return strings.TrimSuffix(b.options.PublicURL, "/") + "/calendar/" + token + ".ics"
+}
+
+func (b *DiscordBot) initCalendarHandlers() {
+	b.router.HandleFunc("/calendar/{token:[0-9a-f]+}.ics", b.serveCalendar).Methods("GET")
+}
+
+func (b *DiscordBot) serveCalendar(w http.ResponseWriter, r *http.Request) {
+	feed, err := b.db.GetCalendarFeed(mux.Vars(r)["token"], time.Now())
+	if errors.Is(err, db.ErrCalendarFeedNotFound) {
+		http.Error(w, "calendar not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error getting calendar feed: %v", err)
+		http.Error(w, "failed to get calendar", http.StatusInternalServerError)
+		return
+	}
+
+	filter := db.TaskFilter{
+		Statuses:   []string{db.TASK_NOT_STARTED, db.TASK_IN_PROGRESS},
+		HasDueDate: true,
+	}
+	if feed.Role != "" {
+		filter.Role = feed.Role
+	} else {
+		filter.AssigneeID = feed.UserID
+	}
+	tasks, _, err := b.db.FindTasks(filter, 0, calendarMaxEvents)
+	if err != nil {
+		log.Printf("Error getting tasks of calendar feed %d: %v", feed.ID, err)
+		http.Error(w, "failed to get calendar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="tasks.ics"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	if _, err := w.Write([]byte(b.calendar(feed, tasks))); err != nil {
+		log.Printf("Error writing calendar feed %d: %v", feed.ID, err)
+	}
+}
+
+// calendar returns the iCalendar file of a feed, in the locale of its user
+func (b *DiscordBot) calendar(feed *db.CalendarFeed, tasks []db.Task) string {
+	locale := discordgo.Locale(feed.User.Locale)
+	name := b.render(locale, "calendar.user-name", messageData{"Username": feed.User.Username})
+	if feed.Role != "" {
+		name = b.render(locale, "calendar.role-name", messageData{"Role": feed.Role})
+	}
+
+	c := &calendarBuilder{}
+	c.line("BEGIN:VCALENDAR")
+	c.line("VERSION:2.0")
+	c.line("PRODID:-//ApexCorse//King//EN")
+	c.line("CALSCALE:GREGORIAN")
+	c.line("METHOD:PUBLISH")
+	c.line("X-WR-CALNAME:" + escapeCalendarText(name))
+	c.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	c.line("X-PUBLISHED-TTL:PT1H")
+	for _, task := range tasks {
+		c.line("BEGIN:VEVENT")
+		c.line(fmt.Sprintf("UID:task-%d@king", task.ID))
+		// The last change of the task stamps the event, so that the feed
+		// only changes with its tasks
+		c.line("DTSTAMP:" + task.UpdatedAt.UTC().Format(calendarTimeFormat))
+		c.line("CREATED:" + task.CreatedAt.UTC().Format(calendarTimeFormat))
+		c.line("LAST-MODIFIED:" + task.UpdatedAt.UTC().Format(calendarTimeFormat))
+		c.line("DTSTART;VALUE=DATE:" + task.DueDate.Format(calendarDateFormat))
+		c.line("DTEND;VALUE=DATE:" + task.DueDate.AddDate(0, 0, 1).Format(calendarDateFormat))
+		c.line("SUMMARY:" + escapeCalendarText(task.Title))
+		c.line("DESCRIPTION:" + escapeCalendarText(b.render(locale, "calendar.event-description", messageData{"Task": task})))
+		if task.Role != "" {
+			c.line("CATEGORIES:" + escapeCalendarText(task.Role))
+		}
+		if task.IssueURL != "" {
+			c.line("URL:" + task.IssueURL)
+		}
+		c.line("TRANSP:TRANSPARENT")
+		c.line("END:VEVENT")
+	}
+	c.line("END:VCALENDAR")
+	return c.String()
+}
+
+// calendarBuilder writes the lines of an iCalendar file, ending them with
+// CRLF and folding the long ones
+type calendarBuilder struct {
+	strings.Builder
+}
+
+// line writes a content line, folded into lines of at most 75 octets that
+// continue on the next one after a space. Lines aren't folded within a
+// UTF-8 sequence.
+func (c *calendarBuilder) line(line string) {
+	limit := calendarLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		c.WriteString(line[:cut])
+		c.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the length of the next line
+		limit = calendarLineLength - 1
+	}
+	c.WriteString(line)
+	c.WriteString("\r\n")
+}
+
+var calendarTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", "",
+)
+
+// escapeCalendarText escapes a TEXT value of an iCalendar property
+func escapeCalendarText(text string) string {
+	return calendarTextEscaper.Replace(text)
+}
+
+func (b *DiscordBot) calendarLinkCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandCalendarLink {
+		return
+	}
+
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if b.options.PublicURL == "" {
+		respond(b.render(i.Locale, "calendar-link.disabled", nil))
+		return
+	}
+	if i.Member == nil || i.Member.User == nil {
+		respond(b.render(i.Locale, "common.not-member", nil))
+		return
+	}
+
+	role := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "role" {
+			role = resolvedRole(i, opt).Name
+		}
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[calendar-link] Failed to get or create user: %v\n", err)
+		respond(b.render(i.Locale, "calendar-link.failed", nil))
+		return
+	}
+
+	token, err := newCalendarToken()
+	if err == nil {
+		_, err = b.db.CreateCalendarFeed(user.ID, role, token)
+	}
+	if err != nil {
+		fmt.Printf("[calendar-link] Failed to create calendar feed of %s: %v\n", i.Member.User.Username, err)
+		respond(b.render(i.Locale, "calendar-link.failed", nil))
+		return
+	}
+
+	fmt.Printf("[calendar-link] Calendar link created by %s, role: %q\n", i.Member.User.Username, role)
+	respond(b.render(i.Locale, "calendar-link.success", messageData{"URL": b.calendarURL(token), "Role": role}))
+}
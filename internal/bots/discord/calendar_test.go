@@ -0,0 +1,178 @@
+package discord
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCalendar returns a bot serving the calendar feeds
+func newTestCalendar(t *testing.T) (*DiscordBot, *fakeSession) {
+	bot, session := newTestBot(t)
+	bot.router = mux.NewRouter()
+	bot.initCalendarHandlers()
+	bot.options.PublicURL = "https://king.example.com/"
+	return bot, session
+}
+
+// getCalendar fetches a calendar feed
+func getCalendar(bot *DiscordBot, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	bot.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+// calendarEvents returns the unfolded content lines of the events of a
+// calendar, one slice per event
+func calendarEvents(t *testing.T, calendar string) [][]string {
+	t.Helper()
+	require.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+
+	events := make([][]string, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(calendar, "\r\n ", ""), "\r\n") {
+		switch {
+		case line == "BEGIN:VEVENT":
+			events = append(events, []string{})
+		case line == "END:VEVENT":
+		case len(events) > 0 && !strings.HasPrefix(line, "END:VCALENDAR"):
+			events[len(events)-1] = append(events[len(events)-1], line)
+		}
+	}
+	return events
+}
+
+func TestCalendarFeed(t *testing.T) {
+	bot, _ := newTestCalendar(t)
+	createTestUser(t, bot.db, "111", "author")
+	alice := createTestUser(t, bot.db, "1", "alice")
+
+	dueDate := func(value string) *time.Time {
+		date, err := parseDueDate(value)
+		require.NoError(t, err)
+		return date
+	}
+	tasks := []*db.Task{
+		createTestTask(t, bot.db, "Wire the BMS", testRole.Name, "1"),
+		createTestTask(t, bot.db, "Calibrate the IMU", testRole.Name, ""),
+		createTestTask(t, bot.db, "Paint the livery", "Design", "1"),
+		createTestTask(t, bot.db, "Order bolts", testRole.Name, "1"),
+	}
+	for i, date := range []string{"2026-11-02", "2026-11-05", "2026-11-09", ""} {
+		if date == "" {
+			continue
+		}
+		_, _, err := bot.db.UpdateTask(tasks[i].ID, alice.ID, &db.TaskUpdateOptions{DueDate: dueDate(date)})
+		require.NoError(t, err)
+	}
+
+	const userToken, roleToken = "0123456789abcdef", "fedcba9876543210"
+	_, err := bot.db.CreateCalendarFeed(alice.ID, "", userToken)
+	require.NoError(t, err)
+	_, err = bot.db.CreateCalendarFeed(alice.ID, testRole.Name, roleToken)
+	require.NoError(t, err)
+
+	rec := getCalendar(bot, "/calendar/"+userToken+".ics")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "X-WR-CALNAME:King: tasks of alice\r\n")
+
+	// The tasks without a due date aren't in the feed
+	events := calendarEvents(t, rec.Body.String())
+	require.Len(t, events, 2)
+	assert.Contains(t, events[0], "UID:task-1@king")
+	assert.Contains(t, events[0], "SUMMARY:Wire the BMS")
+	assert.Contains(t, events[0], "DTSTART;VALUE=DATE:20261102")
+	assert.Contains(t, events[0], "DTEND;VALUE=DATE:20261103")
+	assert.Contains(t, events[0], `DESCRIPTION:Task #1\, Not Started\nAssigned to alice`)
+	assert.Contains(t, events[0], "CATEGORIES:Electronics")
+	assert.Contains(t, events[1], "SUMMARY:Paint the livery")
+
+	rec = getCalendar(bot, "/calendar/"+roleToken+".ics")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), "X-WR-CALNAME:King: Electronics tasks\r\n")
+	assert.Len(t, calendarEvents(t, rec.Body.String()), 2)
+
+	t.Run("due date changes", func(t *testing.T) {
+		_, _, err := bot.db.UpdateTask(tasks[0].ID, alice.ID, &db.TaskUpdateOptions{DueDate: dueDate("2026-11-20")})
+		require.NoError(t, err)
+
+		events := calendarEvents(t, getCalendar(bot, "/calendar/"+userToken+".ics").Body.String())
+		require.Len(t, events, 2)
+		assert.Contains(t, events[0], "UID:task-1@king")
+		assert.Contains(t, events[0], "DTSTART;VALUE=DATE:20261120")
+	})
+
+	t.Run("completed and deleted tasks", func(t *testing.T) {
+		_, err := bot.db.UpdateTaskStatus(tasks[0].ID, db.TASK_COMPLETED)
+		require.NoError(t, err)
+		require.NoError(t, bot.db.DeleteTask(tasks[1].ID))
+
+		events := calendarEvents(t, getCalendar(bot, "/calendar/"+userToken+".ics").Body.String())
+		require.Len(t, events, 1)
+		assert.Contains(t, events[0], "SUMMARY:Paint the livery")
+		assert.Empty(t, calendarEvents(t, getCalendar(bot, "/calendar/"+roleToken+".ics").Body.String()))
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, getCalendar(bot, "/calendar/abcdef.ics").Code)
+		assert.Equal(t, http.StatusNotFound, getCalendar(bot, "/calendar/not-a-token.ics").Code)
+	})
+}
+
+func TestCalendarLines(t *testing.T) {
+	assert.Equal(t, `Brakes\, pads\; rotors \\ calipers\nthen bleed`, escapeCalendarText("Brakes, pads; rotors \\ calipers\r\nthen bleed"))
+
+	line := "DESCRIPTION:" + strings.Repeat("Cablaggio è pronto ", 12)
+	c := &calendarBuilder{}
+	c.line(line)
+	folded := c.String()
+
+	require.True(t, strings.HasSuffix(folded, "\r\n"))
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(part), calendarLineLength)
+	}
+	assert.Equal(t, line, strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""))
+}
+
+func TestCalendarLinkCommand(t *testing.T) {
+	bot, session := newTestCalendar(t)
+	member := testMember("1", "alice", 0)
+	link := regexp.MustCompile(`https://king\.example\.com(/calendar/[0-9a-f]{40}\.ics)`)
+
+	bot.handleInteraction(session, commandInteraction(CommandCalendarLink, member))
+	content := session.content(t)
+	assert.Contains(t, content, "Calendar of your tasks")
+	match := link.FindStringSubmatch(content)
+	require.NotNil(t, match, content)
+	assert.Equal(t, http.StatusOK, getCalendar(bot, match[1]).Code)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandCalendarLink, member, roleOption("role", testRole)))
+	content = session.content(t)
+	assert.Contains(t, content, "Calendar of the Electronics tasks")
+	roleMatch := link.FindStringSubmatch(content)
+	require.NotNil(t, roleMatch, content)
+	assert.Contains(t, getCalendar(bot, roleMatch[1]).Body.String(), "King: Electronics tasks")
+
+	// A new link replaces the previous one
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandCalendarLink, member))
+	newMatch := link.FindStringSubmatch(session.content(t))
+	require.NotNil(t, newMatch)
+	assert.NotEqual(t, match[1], newMatch[1])
+	assert.Equal(t, http.StatusNotFound, getCalendar(bot, match[1]).Code)
+	assert.Equal(t, http.StatusOK, getCalendar(bot, roleMatch[1]).Code)
+
+	session.reset()
+	bot.options.PublicURL = ""
+	bot.handleInteraction(session, commandInteraction(CommandCalendarLink, member))
+	assert.Contains(t, session.content(t), "Calendar links are not available")
+}
@@ -0,0 +1,488 @@
+package discord
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/Formula-SAE/discord/internal/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Task card custom IDs have the form "task-card:<action>:<task ID>", except for
+// the select menu of task listings whose values carry the task ID.
+const (
+	ComponentTaskCard  = "task-card"
+	ModalTaskComment   = "task-comment-modal"
+	SelectOpenTaskCard = "task-card-open"
+
+	TaskCardActionStart    = "start"
+	TaskCardActionComplete = "complete"
+	TaskCardActionAssign   = "assign"
+	TaskCardActionComment  = "comment"
+)
+
+// Discord allows at most 25 options in a select menu
+const maxSelectMenuOptions = 25
+
+// Number of latest comments shown on a task card
+const taskCardComments = 3
+
+var taskCardActions = []string{
+	TaskCardActionStart,
+	TaskCardActionComplete,
+	TaskCardActionAssign,
+	TaskCardActionComment,
+}
+
+func taskCardCustomID(action string, taskID uint) string {
+	return fmt.Sprintf("%s:%s:%d", ComponentTaskCard, action, taskID)
+}
+
+// parseTaskCardCustomID validates the custom ID of a task card button and
+// returns its action and task ID
+func parseTaskCardCustomID(customID string) (string, uint, bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != ComponentTaskCard {
+		return "", 0, false
+	}
+
+	if !slices.Contains(taskCardActions, parts[1]) {
+		return "", 0, false
+	}
+
+	taskID, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || taskID == 0 {
+		return "", 0, false
+	}
+
+	return parts[1], uint(taskID), true
+}
+
+// getStatusColor returns the embed color for a task status
+func getStatusColor(status string) int {
+	switch status {
+	case db.TASK_NOT_STARTED:
+		return 0x95A5A6 // Grey
+	case db.TASK_IN_PROGRESS:
+		return 0x3498DB // Blue
+	case db.TASK_COMPLETED:
+		return 0x2ECC71 // Green
+	default:
+		return 0x000000
+	}
+}
+
+// taskEmbed renders a task, with its latest comments, as a message embed
+func (b *DiscordBot) taskEmbed(locale discordgo.Locale, task *db.Task, comments []db.TaskComment) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       truncate(b.render(locale, "task-card.title", messageData{"Task": task}), 256),
+		Description: truncate(utils.EscapeMarkdown(task.Description), 4096),
+		Color:       getStatusColor(task.Status),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   b.render(locale, "task-card.status-field", nil),
+				Value:  fmt.Sprintf("%s %s", getStatusIcon(task.Status), b.render(locale, "task.status", messageData{"Status": task.Status})),
+				Inline: true,
+			},
+			{
+				Name:   b.render(locale, "task-card.author-field", nil),
+				Value:  fmt.Sprintf("<@%s>", task.Author.DiscordID),
+				Inline: true,
+			},
+		},
+	}
+
+	if task.Role != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   b.render(locale, "task-card.role-field", nil),
+			Value:  utils.EscapeMarkdown(task.Role),
+			Inline: true,
+		})
+	}
+
+	if task.DueDate != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   b.render(locale, "task-card.due-field", nil),
+			Value:  task.DueDate.Format(db.DueDateFormat),
+			Inline: true,
+		})
+	}
+
+	if task.IssueNumber != 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   b.render(locale, "task-card.issue-field", nil),
+			Value:  b.render(locale, "task-to-issue.issue-link", messageData{"Task": task}),
+			Inline: true,
+		})
+	}
+
+	if len(task.AssignedUsers) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  b.render(locale, "task-card.assignees-field", nil),
+			Value: truncate(formatAssignees(task.AssignedUsers), 1024),
+		})
+	}
+
+	if len(comments) > 0 {
+		latest := comments[max(0, len(comments)-taskCardComments):]
+		lines := make([]string, len(latest))
+		for i, comment := range latest {
+			// Commit comments are written by the bot, with their own markdown
+			text := comment.Text
+			if comment.CommitSHA == "" {
+				text = utils.EscapeMarkdown(text)
+			}
+			lines[i] = truncate(fmt.Sprintf("<@%s>: %s", comment.Author.DiscordID, text), 300)
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  b.render(locale, "task-card.comments-field", messageData{"Count": len(comments)}),
+			Value: truncate(strings.Join(lines, "\n"), 1024),
+		})
+	}
+
+	return embed
+}
+
+// taskCardComponents returns the action buttons of a task card
+func (b *DiscordBot) taskCardComponents(locale discordgo.Locale, task *db.Task) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    b.render(locale, "task-card.start-button", nil),
+					Style:    discordgo.PrimaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "🔄"},
+					CustomID: taskCardCustomID(TaskCardActionStart, task.ID),
+					Disabled: task.Status != db.TASK_NOT_STARTED,
+				},
+				discordgo.Button{
+					Label:    b.render(locale, "task-card.complete-button", nil),
+					Style:    discordgo.SuccessButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "✅"},
+					CustomID: taskCardCustomID(TaskCardActionComplete, task.ID),
+					Disabled: task.Status == db.TASK_COMPLETED,
+				},
+				discordgo.Button{
+					Label:    b.render(locale, "task-card.assign-button", nil),
+					Style:    discordgo.SecondaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "🙋"},
+					CustomID: taskCardCustomID(TaskCardActionAssign, task.ID),
+				},
+				discordgo.Button{
+					Label:    b.render(locale, "task-card.comment-button", nil),
+					Style:    discordgo.SecondaryButton,
+					Emoji:    &discordgo.ComponentEmoji{Name: "💬"},
+					CustomID: taskCardCustomID(TaskCardActionComment, task.ID),
+				},
+			},
+		},
+	}
+}
+
+// taskSelectMenu returns a select menu that opens the card of one of the listed tasks
+func (b *DiscordBot) taskSelectMenu(locale discordgo.Locale, tasks []db.Task) discordgo.MessageComponent {
+	options := make([]discordgo.SelectMenuOption, 0, min(len(tasks), maxSelectMenuOptions))
+	for _, task := range tasks[:min(len(tasks), maxSelectMenuOptions)] {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncate(fmt.Sprintf("#%d %s", task.ID, task.Title), 100),
+			Value:       strconv.FormatUint(uint64(task.ID), 10),
+			Description: b.render(locale, "task.status", messageData{"Status": task.Status}),
+			Emoji:       &discordgo.ComponentEmoji{Name: getStatusIcon(task.Status)},
+		})
+	}
+
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.StringSelectMenu,
+				CustomID:    SelectOpenTaskCard,
+				Placeholder: b.render(locale, "task-card.select-placeholder", nil),
+				Options:     options,
+			},
+		},
+	}
+}
+
+// taskCardResponseData builds the response data of a task card, fetching the task comments
+func (b *DiscordBot) taskCardResponseData(locale discordgo.Locale, task *db.Task) *discordgo.InteractionResponseData {
+	comments, err := b.db.GetTaskComments(task.ID)
+	if err != nil {
+		fmt.Printf("[task-card] Failed to get comments of task %d: %v\n", task.ID, err)
+	}
+
+	return &discordgo.InteractionResponseData{
+		Embeds:     []*discordgo.MessageEmbed{b.taskEmbed(locale, task, comments)},
+		Components: b.taskCardComponents(locale, task),
+		Flags:      discordgo.MessageFlagsEphemeral,
+	}
+}
+
+func (b *DiscordBot) openTaskCardComponent(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	data := i.MessageComponentData()
+	if data.CustomID != SelectOpenTaskCard {
+		return
+	}
+
+	if len(data.Values) != 1 {
+		fmt.Printf("[task-card] Invalid number of selected values: %d\n", len(data.Values))
+		return
+	}
+
+	taskID, err := strconv.ParseUint(data.Values[0], 10, 64)
+	if err != nil {
+		fmt.Printf("[task-card] Invalid selected task ID: %s\n", data.Values[0])
+		return
+	}
+
+	task, err := b.db.GetTaskByID(uint(taskID))
+	if err != nil {
+		fmt.Printf("[task-card] Failed to get task: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.not-found", messageData{"TaskID": data.Values[0]}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: b.taskCardResponseData(i.Locale, task),
+	})
+}
+
+func (b *DiscordBot) taskCardComponent(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+	if !strings.HasPrefix(customID, ComponentTaskCard+":") {
+		return
+	}
+
+	action, taskID, ok := parseTaskCardCustomID(customID)
+	if !ok {
+		fmt.Printf("[task-card] Invalid custom ID: %s\n", customID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.invalid-button", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	fmt.Printf("[task-card] Action %s on task %d by user %s\n", action, taskID, i.Member.User.Username)
+
+	task, err := b.db.GetTaskByID(taskID)
+	if err != nil {
+		fmt.Printf("[task-card] Failed to get task: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.not-found", messageData{"TaskID": taskID}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	switch action {
+	case TaskCardActionStart, TaskCardActionComplete:
+		status := db.TASK_IN_PROGRESS
+		if action == TaskCardActionComplete {
+			status = db.TASK_COMPLETED
+		}
+
+		task, err = b.db.UpdateTaskStatus(task.ID, status)
+		if err != nil {
+			fmt.Printf("[task-card] Failed to update task status: %v\n", err)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "common.status-update-failed", messageData{"Error": err.Error()}),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: b.taskCardResponseData(i.Locale, task),
+		})
+		b.notifyTaskStatusUpdate(task, i.Member.User.ID)
+		b.onTaskStatusChanged(task)
+		b.emitTaskEvent(db.TASK_EVENT_STATUS_CHANGED, task)
+
+	case TaskCardActionAssign:
+		for _, user := range task.AssignedUsers {
+			if user.DiscordID == i.Member.User.ID {
+				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: b.render(i.Locale, "task-card.already-assigned", nil),
+						Flags:   discordgo.MessageFlagsEphemeral,
+					},
+				})
+				return
+			}
+		}
+
+		user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+		if err == nil {
+			err = b.db.AssignTask(task.ID, user.ID)
+		}
+		if err == nil {
+			task, err = b.db.GetTaskByID(task.ID)
+		}
+		if err != nil {
+			fmt.Printf("[task-card] Failed to assign task: %v\n", err)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "common.assign-failed", nil),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: b.taskCardResponseData(i.Locale, task),
+		})
+		// Assigning oneself claims the task, which its author is told about
+		b.notifyTaskAssigned(task, i.Member.User.ID)
+		b.notifyTaskClaimed(task, i.Member.User.ID)
+		b.emitTaskEvent(db.TASK_EVENT_ASSIGNED, task)
+
+	case TaskCardActionComment:
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: &discordgo.InteractionResponseData{
+				CustomID: fmt.Sprintf("%s:%d", ModalTaskComment, task.ID),
+				Title:    truncate(b.render(i.Locale, "task-card.comment-modal-title", messageData{"TaskID": task.ID}), 45),
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							discordgo.TextInput{
+								CustomID:  "comment",
+								Label:     b.render(i.Locale, "task-card.comment-label", nil),
+								Style:     discordgo.TextInputParagraph,
+								Required:  true,
+								MaxLength: 1000,
+							},
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			fmt.Printf("[task-card] Failed to open comment modal: %v\n", err)
+		}
+	}
+}
+
+func (b *DiscordBot) taskCommentModalSubmit(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionModalSubmit {
+		return
+	}
+
+	data := i.ModalSubmitData()
+	rawTaskID, ok := strings.CutPrefix(data.CustomID, ModalTaskComment+":")
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseUint(rawTaskID, 10, 64)
+	if err != nil || i.Member == nil || i.Member.User == nil {
+		fmt.Printf("[task-card] Invalid comment modal submission: %s\n", data.CustomID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.comment-invalid", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	commenter, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[task-card] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.comment-failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	comment, err := b.db.AddTaskComment(uint(taskID), commenter.ID, strings.TrimSpace(getModalValues(data)["comment"]))
+	if err != nil {
+		fmt.Printf("[task-card] Failed to add comment: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.comment-rejected", messageData{"Error": err.Error()}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	task, err := b.db.GetTaskByID(uint(taskID))
+	if err != nil {
+		fmt.Printf("[task-card] Failed to get task: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.comment-added", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// The modal is opened from a task card, so the card can be refreshed in place
+	fmt.Printf("[task-card] Comment added to task %d by user %s\n", task.ID, i.Member.User.Username)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: b.taskCardResponseData(i.Locale, task),
+	})
+
+	b.notifyTaskComment(task, comment, i.Member.User.ID)
+}
+
+// truncate shortens text to at most limit characters, adding an ellipsis when cut
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
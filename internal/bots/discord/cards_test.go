@@ -0,0 +1,86 @@
+package discord
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenTaskCardComponent(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+
+	bot.handleInteraction(session, componentInteraction(SelectOpenTaskCard, testMember("1", "alice", 0), fmt.Sprintf("%d", task.ID)))
+
+	resp := session.response(t)
+	require.Len(t, resp.Data.Embeds, 1)
+	assert.Contains(t, resp.Data.Embeds[0].Title, "Wire the BMS")
+	assert.NotEmpty(t, resp.Data.Components)
+}
+
+func TestTaskCardComponent(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+	member := testMember("1", "alice", 0)
+
+	bot.handleInteraction(session, componentInteraction(taskCardCustomID(TaskCardActionStart, task.ID), member))
+	assert.Equal(t, discordgo.InteractionResponseUpdateMessage, session.response(t).Type)
+	updated, err := bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.TASK_IN_PROGRESS, updated.Status)
+	assert.Len(t, session.messagesTo(dmChannelID("111")), 1)
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(taskCardCustomID(TaskCardActionAssign, task.ID), member))
+	assert.Equal(t, discordgo.InteractionResponseUpdateMessage, session.response(t).Type)
+	updated, err = bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	require.Len(t, updated.AssignedUsers, 1)
+	assert.Equal(t, "1", updated.AssignedUsers[0].DiscordID)
+	// As with /assign-task, the assignee and the author are notified
+	assert.Len(t, session.messagesTo(dmChannelID("1")), 1)
+	assert.Len(t, session.messagesTo(dmChannelID("111")), 1)
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(taskCardCustomID(TaskCardActionAssign, task.ID), member))
+	assert.Contains(t, session.content(t), "Already assigned")
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(taskCardCustomID(TaskCardActionComment, task.ID), member))
+	resp := session.response(t)
+	assert.Equal(t, discordgo.InteractionResponseModal, resp.Type)
+	assert.Equal(t, fmt.Sprintf("%s:%d", ModalTaskComment, task.ID), resp.Data.CustomID)
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(taskCardCustomID(TaskCardActionComplete, 42), member))
+	assert.Contains(t, session.content(t), "Task not found!")
+}
+
+func TestTaskCommentModalSubmit(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+
+	bot.handleInteraction(session, modalInteraction(fmt.Sprintf("%s:%d", ModalTaskComment, task.ID), testMember("1", "alice", 0), map[string]string{
+		"comment": " The harness is ready ",
+	}))
+
+	resp := session.response(t)
+	assert.Equal(t, discordgo.InteractionResponseUpdateMessage, resp.Type)
+	require.Len(t, resp.Data.Embeds, 1)
+
+	comments, err := bot.db.GetTaskComments(task.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "The harness is ready", comments[0].Text)
+
+	dms := session.messagesTo(dmChannelID("111"))
+	require.Len(t, dms, 1)
+	assert.Contains(t, dms[0].Content, "The harness is ready")
+}
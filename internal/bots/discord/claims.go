@@ -0,0 +1,160 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+const ButtonClaimTask = "claim-task"
+
+// Discord allows at most 5 buttons per row and 5 rows per message; one row is
+// taken by the task select menu of the listing.
+const (
+	maxButtonsPerRow = 5
+	maxClaimButtons  = 4 * maxButtonsPerRow
+)
+
+// claimButtons returns rows of buttons that claim the listed tasks
+func (b *DiscordBot) claimButtons(locale discordgo.Locale, tasks []db.Task) []discordgo.MessageComponent {
+	rows := make([]discordgo.MessageComponent, 0)
+	buttons := make([]discordgo.MessageComponent, 0, maxButtonsPerRow)
+
+	for _, task := range tasks[:min(len(tasks), maxClaimButtons)] {
+		buttons = append(buttons, discordgo.Button{
+			Label:    b.render(locale, "claim-task.button", messageData{"TaskID": task.ID}),
+			Style:    discordgo.PrimaryButton,
+			Emoji:    &discordgo.ComponentEmoji{Name: "🙋"},
+			CustomID: fmt.Sprintf("%s:%d", ButtonClaimTask, task.ID),
+		})
+		if len(buttons) == maxButtonsPerRow {
+			rows = append(rows, discordgo.ActionsRow{Components: buttons})
+			buttons = make([]discordgo.MessageComponent, 0, maxButtonsPerRow)
+		}
+	}
+	if len(buttons) > 0 {
+		rows = append(rows, discordgo.ActionsRow{Components: buttons})
+	}
+
+	return rows
+}
+
+func (b *DiscordBot) claimTaskCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandClaimTask {
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) != 1 {
+		fmt.Printf("[claim-task] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.expect-task-id", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	b.claimTask(s, i, uint(options[0].IntValue()))
+}
+
+func (b *DiscordBot) claimTaskComponent(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	rawTaskID, ok := strings.CutPrefix(i.MessageComponentData().CustomID, ButtonClaimTask+":")
+	if !ok {
+		return
+	}
+
+	taskID, err := strconv.ParseUint(rawTaskID, 10, 64)
+	if err != nil {
+		fmt.Printf("[claim-task] Invalid task ID in button: %s\n", rawTaskID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.invalid-button", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	b.claimTask(s, i, uint(taskID))
+}
+
+// claimTask assigns the task to the member who triggered the interaction and notifies the task author
+func (b *DiscordBot) claimTask(s Session, i *discordgo.InteractionCreate, taskID uint) {
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	fmt.Printf("[claim-task] User %s is claiming task %d\n", i.Member.User.Username, taskID)
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[claim-task] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "claim-task.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	task, err := b.db.ClaimTask(taskID, user.ID, b.options.MaxInProgressTasks)
+	if err != nil {
+		fmt.Printf("[claim-task] Failed to claim task: %v\n", err)
+
+		var respContent string
+		switch {
+		case errors.Is(err, db.ErrTaskAlreadyAssigned):
+			respContent = b.render(i.Locale, "claim-task.already-claimed", nil)
+		case errors.Is(err, db.ErrTaskCompleted):
+			respContent = b.render(i.Locale, "claim-task.completed", nil)
+		case errors.Is(err, db.ErrInProgressLimitReached):
+			respContent = b.render(i.Locale, "claim-task.limit-reached", messageData{"Limit": b.options.MaxInProgressTasks})
+		default:
+			respContent = b.render(i.Locale, "claim-task.not-found", messageData{"TaskID": taskID})
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: respContent,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[claim-task] Task %d claimed by user %s\n", task.ID, i.Member.User.Username)
+	respData := b.taskCardResponseData(i.Locale, task)
+	respData.Content = b.render(i.Locale, "claim-task.success", nil)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: respData,
+	})
+
+	b.notifyTaskClaimed(task, i.Member.User.ID)
+	b.emitTaskEvent(db.TASK_EVENT_ASSIGNED, task)
+}
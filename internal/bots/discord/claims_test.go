@@ -0,0 +1,37 @@
+package discord
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimTask(t *testing.T) {
+	bot, session := newTestBot(t)
+	bot.options.MaxInProgressTasks = 1
+	createTestUser(t, bot.db, "111", "author")
+	first := createTestTask(t, bot.db, "Wire the BMS", "Electronics", "")
+	second := createTestTask(t, bot.db, "Route the CAN bus", "Electronics", "")
+
+	bot.handleInteraction(session, commandInteraction(CommandClaimTask, testMember("1", "alice", 0), intOption("id", int(first.ID))))
+
+	assert.Contains(t, session.content(t), "Task claimed successfully!")
+	claimed, err := bot.db.GetTaskByID(first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.TASK_IN_PROGRESS, claimed.Status)
+	require.Len(t, claimed.AssignedUsers, 1)
+	assert.Len(t, session.messagesTo(dmChannelID("111")), 1)
+
+	// The same task can't be claimed twice
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(fmt.Sprintf("%s:%d", ButtonClaimTask, first.ID), testMember("2", "bob", 0)))
+	assert.Contains(t, session.content(t), "Someone has already claimed this task.")
+
+	// Nor can a member exceed the limit of tasks in progress
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(fmt.Sprintf("%s:%d", ButtonClaimTask, second.ID), testMember("1", "alice", 0)))
+	assert.Contains(t, session.content(t), "You already have 1 tasks in progress.")
+}
@@ -1,12 +1,16 @@
 package discord
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/Formula-SAE/discord/internal/db"
-	"github.com/Formula-SAE/discord/internal/utils"
 	"github.com/bwmarrin/discordgo"
 	"gorm.io/gorm"
 )
@@ -25,9 +29,62 @@ const (
 	CommandSubscribeChannelToPush     = "subscribe-channel-to-push"
 	CommandUnsubscribeChannelFromPush = "unsubscribe-channel-from-push"
 	CommandGetSubscriptionsOfChannel  = "get-subscriptions"
+	CommandEditTask                   = "edit-task"
+	CommandDeletedTasks               = "deleted-tasks"
+	CommandRestoreTask                = "restore-task"
+	CommandClaimTask                  = "claim-task"
+	CommandAddDigest                  = "add-digest"
+	CommandRemoveDigest               = "remove-digest"
+	CommandListDigests                = "list-digests"
+	CommandNotifications              = "notifications"
+	CommandQueueStatus                = "queue-status"
+	CommandWebhookDeliveries          = "webhook-deliveries"
+	CommandLinkGitHub                 = "link-github"
+	CommandVerifyGitHub               = "verify-github"
+	CommandUnlinkGitHub               = "unlink-github"
+	CommandMyReviews                  = "my-reviews"
+	CommandTaskToIssue                = "task-to-issue"
+	CommandRepoInfo                   = "repo-info"
+	CommandRecentCommits              = "recent-commits"
+	CommandCIStatus                   = "ci-status"
+	CommandAllSubscriptions           = "all-subscriptions"
+	CommandLanguage                   = "language"
+	CommandWorkload                   = "workload"
+	CommandStats                      = "stats"
+	CommandExportTasks                = "export-tasks"
+	CommandImportTasks                = "import-tasks"
+	CommandCreateAPIToken             = "create-api-token"
+	CommandListAPITokens              = "list-api-tokens"
+	CommandRevokeAPIToken             = "revoke-api-token"
+	CommandAddTaskWebhook             = "add-task-webhook"
+	CommandListTaskWebhooks           = "list-task-webhooks"
+	CommandRemoveTaskWebhook          = "remove-task-webhook"
+	CommandCalendarLink               = "calendar-link"
 )
 
-func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+// Maximum lengths of the title and the description of a task, in characters.
+// They are the limits of the inputs of the edit modal, which can't be opened
+// with longer values, so every way of creating or editing a task keeps them.
+const (
+	taskTitleMaxLength       = 256
+	taskDescriptionMaxLength = 4000
+)
+
+// Button custom ID constants
+const (
+	ButtonConfirmDeleteTask = "delete-task-confirm"
+	ButtonCancelDeleteTask  = "delete-task-cancel"
+	ButtonUnsubscribe       = "subscription-unsubscribe"
+	ButtonConfirmImport     = "import-tasks-confirm"
+	ButtonCancelImport      = "import-tasks-cancel"
+)
+
+// Modal custom ID constants
+const (
+	ModalEditTask = "edit-task-modal"
+)
+
+func (b *DiscordBot) createTaskCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -42,7 +99,7 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide at least a title for the task.", utils.Bold("Missing title")),
+				Content: b.render(i.Locale, "create-task.missing-title", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -53,7 +110,7 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("🚫 %s\n\nYou must be a member of a server to use this command.", utils.Bold("Access denied")),
+				Content: b.render(i.Locale, "common.not-member", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -63,7 +120,6 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 
 	task := &db.Task{}
 	assigneeId := ""
-	respContent := ""
 
 	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
 	for _, opt := range options {
@@ -76,7 +132,7 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide a title for the task.", utils.Bold("Missing title")),
+				Content: b.render(i.Locale, "create-task.missing-title", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -87,30 +143,75 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 			title.StringValue(),
 		)
 		task.Title = title.StringValue()
-		respContent = fmt.Sprintf("✅ %s\n\n%s: %s", utils.Bold("Task created successfully!"), utils.Italic("Title"), title.StringValue())
 	}
 
 	description := optionMap["description"]
 	assignee := optionMap["assignee"]
+	assigneeGitHub := optionMap["assignee-github"]
 	role := optionMap["role"]
+	dueDate := optionMap["due-date"]
 	author := i.Member.User
 
 	if description != nil {
 		fmt.Printf(", description: %s", description.StringValue())
 		task.Description = description.StringValue()
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Description"), description.StringValue())
 	}
 
 	if assignee != nil {
-		fmt.Printf(", assignee: %s", assignee.UserValue(s).ID)
-		assigneeId = assignee.UserValue(s).ID
-		respContent += fmt.Sprintf("\n%s: <@%s>", utils.Italic("Assignee"), assigneeId)
+		fmt.Printf(", assignee: %s", resolvedUser(i, assignee).ID)
+		assigneeId = resolvedUser(i, assignee).ID
+	}
+
+	if assigneeGitHub != nil {
+		if assignee != nil {
+			fmt.Printf("\n[create-task] Both assignee and assignee-github provided\n")
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "create-task.conflicting-assignees", nil),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		githubUser, err := b.db.GetUserByGitHubLogin(assigneeGitHub.StringValue())
+		if err != nil {
+			fmt.Printf("\n[create-task] No member linked to GitHub account %s: %v\n", assigneeGitHub.StringValue(), err)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "common.unknown-github-account", messageData{"Login": assigneeGitHub.StringValue()}),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		fmt.Printf(", assignee: %s (GitHub %s)", githubUser.DiscordID, assigneeGitHub.StringValue())
+		assigneeId = githubUser.DiscordID
 	}
 
 	if role != nil {
-		fmt.Printf(", role: %s", role.RoleValue(s, b.guildID).Name)
-		task.Role = role.RoleValue(s, b.guildID).Name
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Role"), role.RoleValue(s, b.guildID).Name)
+		fmt.Printf(", role: %s", resolvedRole(i, role).Name)
+		task.Role = resolvedRole(i, role).Name
+	}
+
+	if dueDate != nil {
+		parsedDueDate, err := parseDueDate(dueDate.StringValue())
+		if err != nil {
+			fmt.Printf("\n[create-task] Invalid due date: %s\n", dueDate.StringValue())
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "common.invalid-due-date", nil),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		fmt.Printf(", due date: %s", parsedDueDate.Format(db.DueDateFormat))
+		task.DueDate = parsedDueDate
 	}
 	fmt.Printf("\n")
 
@@ -127,8 +228,8 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 	if assignee != nil {
 		go func() {
 			b.db.CreateUser(&db.User{
-				Username:  assignee.UserValue(s).Username,
-				DiscordID: assignee.UserValue(s).ID,
+				Username:  resolvedUser(i, assignee).Username,
+				DiscordID: resolvedUser(i, assignee).ID,
 			})
 			wg.Done()
 		}()
@@ -149,7 +250,7 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while creating the task. Please try again or contact an administrator.", utils.Bold("Failed to create task")),
+				Content: b.render(i.Locale, "create-task.failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -157,33 +258,32 @@ func (b *DiscordBot) createTaskCommand(s *discordgo.Session, i *discordgo.Intera
 	}
 
 	fmt.Printf("[create-task] Task created successfully with ID: %d\n", task.ID)
-	respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Task ID"), utils.InlineCode(fmt.Sprintf("%d", task.ID)))
-	respContent += fmt.Sprintf("\n%s: %s %s", utils.Italic("Status"), getStatusIcon(task.Status), task.Status)
+	var respData *discordgo.InteractionResponseData
+	createdTask, err := b.db.GetTaskByID(task.ID)
+	if err == nil {
+		respData = b.taskCardResponseData(i.Locale, createdTask)
+		respData.Content = b.render(i.Locale, "create-task.success", nil)
+	} else {
+		fmt.Printf("[create-task] Failed to reload task: %v\n", err)
+		respData = &discordgo.InteractionResponseData{
+			Content: b.render(i.Locale, "create-task.success-details", messageData{"Task": task, "AssigneeID": assigneeId}),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		}
+	}
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
+		Data: respData,
 	})
 
-	if assignee != nil {
-		message := fmt.Sprintf("👋 Hi <@%s>! You have been assigned a new task:\n\n%s", assigneeId, utils.Bold(task.Title))
-
-		if task.Description != "" {
-			message += fmt.Sprintf("\n%s", task.Description)
-		}
-
-		message += fmt.Sprintf("\n\n🔗 %s: %s\n\nGood luck! 🚀", utils.Italic("Task ID"), utils.InlineCode(fmt.Sprintf("%d", task.ID)))
-
-		err = b.sendPrivateMessage(assigneeId, message)
-		if err != nil {
-			fmt.Printf("[create-task] Failed to send private message to assignee: %v\n", err)
-		}
+	if assigneeId != "" {
+		b.notifyTaskAssigned(task, assigneeId)
+	}
+	if createdTask != nil {
+		b.emitTaskEvent(db.TASK_EVENT_CREATED, createdTask)
 	}
 }
 
-func (b *DiscordBot) getAssignedTasksCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) getAssignedTasksCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -197,7 +297,7 @@ func (b *DiscordBot) getAssignedTasksCommand(s *discordgo.Session, i *discordgo.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "You must be a member of a server to use this command",
+				Content: b.render(i.Locale, "common.not-member", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -213,7 +313,7 @@ func (b *DiscordBot) getAssignedTasksCommand(s *discordgo.Session, i *discordgo.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while fetching your assigned tasks. Please try again or contact an administrator.", utils.Bold("Failed to retrieve tasks")),
+				Content: b.render(i.Locale, "assigned-tasks.failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -222,7 +322,7 @@ func (b *DiscordBot) getAssignedTasksCommand(s *discordgo.Session, i *discordgo.
 	tasks := user.AssignedTasks
 
 	if len(tasks) == 0 {
-		respContent := "🎉 You have no tasks assigned to you at the moment."
+		respContent := b.render(i.Locale, "assigned-tasks.none", nil)
 		fmt.Printf("[assigned-tasks] No tasks found for user %s\n", userDiscordID)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -234,28 +334,20 @@ func (b *DiscordBot) getAssignedTasksCommand(s *discordgo.Session, i *discordgo.
 		return
 	}
 
-	respContent := fmt.Sprintf("📋 %s\n", utils.Bold("Your assigned tasks:"))
-	for _, task := range tasks {
-		respContent += fmt.Sprintf("\n%s: %d", utils.Italic("ID"), task.ID)
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Title"), task.Title)
-		if task.Description != "" {
-			respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Description"), task.Description)
-		}
-		respContent += fmt.Sprintf("\n%s: <@%s>", utils.Italic("Author"), task.Author.DiscordID)
-		respContent += fmt.Sprintf("\n%s: %s %s\n", utils.Italic("Status"), getStatusIcon(task.Status), task.Status)
-	}
+	respContent := b.render(i.Locale, "assigned-tasks.list", messageData{"Tasks": tasks})
 
 	fmt.Printf("[assigned-tasks] Retrieved %d tasks for user %s\n", len(tasks), userDiscordID)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Content:    respContent,
+			Flags:      discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{b.taskSelectMenu(i.Locale, tasks)},
 		},
 	})
 }
 
-func (b *DiscordBot) getTaskCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) getTaskCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -270,7 +362,7 @@ func (b *DiscordBot) getTaskCommand(s *discordgo.Session, i *discordgo.Interacti
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide exactly one option (task ID).", utils.Bold("Invalid options")),
+				Content: b.render(i.Locale, "common.expect-task-id", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -282,7 +374,7 @@ func (b *DiscordBot) getTaskCommand(s *discordgo.Session, i *discordgo.Interacti
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide a valid integer ID for the task.", utils.Bold("Invalid ID format")),
+				Content: b.render(i.Locale, "common.invalid-task-id", nil),
 			},
 		})
 		return
@@ -292,7 +384,7 @@ func (b *DiscordBot) getTaskCommand(s *discordgo.Session, i *discordgo.Interacti
 	task, err := b.db.GetTaskByID(uint(taskID))
 	if err != nil {
 		fmt.Printf("[get-task] Failed to get task: %v\n", err)
-		respContent := fmt.Sprintf("❌ %s\n\nTask with ID %s doesn't exist or has been deleted. Please check the ID and try again.", utils.Bold("Task not found!"), utils.InlineCode(fmt.Sprintf("%d", taskID)))
+		respContent := b.render(i.Locale, "common.task-not-found", messageData{"TaskID": taskID})
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -303,33 +395,14 @@ func (b *DiscordBot) getTaskCommand(s *discordgo.Session, i *discordgo.Interacti
 		return
 	}
 
-	respContent := fmt.Sprintf("📋 %s\n\n%s: %s", utils.Bold(fmt.Sprintf("Task #%d", task.ID)), utils.Italic("Title"), task.Title)
-	if task.Description != "" {
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Description"), task.Description)
-	}
-	if task.Role != "" {
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Role"), task.Role)
-	}
-	if len(task.AssignedUsers) > 0 {
-		respContent += fmt.Sprintf("\n%s:\n", utils.Italic("Assigned to"))
-		for _, user := range task.AssignedUsers {
-			respContent += fmt.Sprintf("  - <@%s>\n", user.DiscordID)
-		}
-	}
-	respContent += fmt.Sprintf("\n%s: <@%s>", utils.Italic("Author"), task.Author.DiscordID)
-	respContent += fmt.Sprintf("\n%s: %s %s", utils.Italic("Status"), getStatusIcon(task.Status), task.Status)
-
-	fmt.Printf("[get-task] Retrieved task %d: %s\n", task.ID, respContent)
+	fmt.Printf("[get-task] Retrieved task %d: %s\n", task.ID, task.Title)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
+		Data: b.taskCardResponseData(i.Locale, task),
 	})
 }
 
-func (b *DiscordBot) getTasksByRoleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) getTasksByRoleCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -344,7 +417,7 @@ func (b *DiscordBot) getTasksByRoleCommand(s *discordgo.Session, i *discordgo.In
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid options**\n\nYou must provide exactly one option (role).",
+				Content: b.render(i.Locale, "common.expect-role", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -356,18 +429,18 @@ func (b *DiscordBot) getTasksByRoleCommand(s *discordgo.Session, i *discordgo.In
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid role**\n\nYou must provide a valid role.",
+				Content: b.render(i.Locale, "common.invalid-role", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	role := options[0].RoleValue(s, b.guildID).Name
+	role := resolvedRole(i, options[0]).Name
 	tasks, err := b.db.GetTasksByRole(role)
 	if err != nil {
 		fmt.Printf("[get-tasks-by-role] Failed to get tasks: %v\n", err)
-		respContent := fmt.Sprintf("❌ **Failed to retrieve tasks for role `%s`**\n\nAn error occurred while fetching tasks. Please try again or contact an administrator.", role)
+		respContent := b.render(i.Locale, "tasks-by-role.failed", messageData{"Role": role})
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -379,7 +452,7 @@ func (b *DiscordBot) getTasksByRoleCommand(s *discordgo.Session, i *discordgo.In
 	}
 
 	if len(tasks) == 0 {
-		respContent := fmt.Sprintf("🔍 %s\n\nThis role currently has no active tasks.", utils.Bold(fmt.Sprintf("No tasks found for role %s", utils.InlineCode(role))))
+		respContent := b.render(i.Locale, "tasks-by-role.none", messageData{"Role": role})
 		fmt.Printf("[get-tasks-by-role] No tasks found for role %s\n", role)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -391,36 +464,20 @@ func (b *DiscordBot) getTasksByRoleCommand(s *discordgo.Session, i *discordgo.In
 		return
 	}
 
-	respContent := fmt.Sprintf("📋 %s:\n", utils.Bold(fmt.Sprintf("Tasks for role %s", utils.InlineCode(role))))
-	for _, task := range tasks {
-		respContent += "--------------------------------\n"
-		respContent += fmt.Sprintf("%s: %d", utils.Italic("ID"), task.ID)
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Title"), task.Title)
-		if task.Description != "" {
-			respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Description"), task.Description)
-		}
-		respContent += fmt.Sprintf("\n%s: <@%s>", utils.Italic("Author"), task.Author.DiscordID)
-		if len(task.AssignedUsers) > 0 {
-			respContent += fmt.Sprintf("\n%s:\n", utils.Italic("Assigned to"))
-			for _, user := range task.AssignedUsers {
-				respContent += fmt.Sprintf("  - <@%s>\n", user.DiscordID)
-			}
-		}
-		respContent += fmt.Sprintf("\n%s: %s %s", utils.Italic("Status"), getStatusIcon(task.Status), task.Status)
-		respContent += "\n"
-	}
+	respContent := b.render(i.Locale, "tasks-by-role.list", messageData{"Role": role, "Tasks": tasks})
 
 	fmt.Printf("[get-tasks-by-role] Retrieved %d tasks for role %s\n", len(tasks), role)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Content:    respContent,
+			Flags:      discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{b.taskSelectMenu(i.Locale, tasks)},
 		},
 	})
 }
 
-func (b *DiscordBot) getUnassignedTasksByRoleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) getUnassignedTasksByRoleCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -435,7 +492,7 @@ func (b *DiscordBot) getUnassignedTasksByRoleCommand(s *discordgo.Session, i *di
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid options**\n\nYou must provide exactly one option (role).",
+				Content: b.render(i.Locale, "common.expect-role", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -447,18 +504,18 @@ func (b *DiscordBot) getUnassignedTasksByRoleCommand(s *discordgo.Session, i *di
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid role**\n\nYou must provide a valid role.",
+				Content: b.render(i.Locale, "common.invalid-role", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	role := options[0].RoleValue(s, b.guildID).Name
+	role := resolvedRole(i, options[0]).Name
 	tasks, err := b.db.GetUnassignedTasksByRole(role)
 	if err != nil {
 		fmt.Printf("[unassigned-tasks-by-role] Failed to get tasks: %v\n", err)
-		respContent := fmt.Sprintf("❌ **Failed to retrieve tasks for role `%s`**\n\nAn error occurred while fetching tasks. Please try again or contact an administrator.", role)
+		respContent := b.render(i.Locale, "tasks-by-role.failed", messageData{"Role": role})
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -470,7 +527,7 @@ func (b *DiscordBot) getUnassignedTasksByRoleCommand(s *discordgo.Session, i *di
 	}
 
 	if len(tasks) == 0 {
-		respContent := fmt.Sprintf("🔍 %s\n\nThis role currently has no unassigned tasks.", utils.Bold(fmt.Sprintf("No unassigned tasks found for role %s", utils.InlineCode(role))))
+		respContent := b.render(i.Locale, "unassigned-tasks-by-role.none", messageData{"Role": role})
 		fmt.Printf("[unassigned-tasks-by-role] No unassigned tasks found for role %s\n", role)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -482,30 +539,20 @@ func (b *DiscordBot) getUnassignedTasksByRoleCommand(s *discordgo.Session, i *di
 		return
 	}
 
-	respContent := fmt.Sprintf("📋 %s:\n", utils.Bold(fmt.Sprintf("Unassigned tasks for role %s", utils.InlineCode(role))))
-	for _, task := range tasks {
-		respContent += "--------------------------------\n"
-		respContent += fmt.Sprintf("%s: %d", utils.Italic("ID"), task.ID)
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Title"), task.Title)
-		if task.Description != "" {
-			respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Description"), task.Description)
-		}
-		respContent += fmt.Sprintf("\n%s: <@%s>", utils.Italic("Author"), task.Author.DiscordID)
-		respContent += fmt.Sprintf("\n%s: %s %s", utils.Italic("Status"), getStatusIcon(task.Status), task.Status)
-		respContent += "\n"
-	}
+	respContent := b.render(i.Locale, "unassigned-tasks-by-role.list", messageData{"Role": role, "Tasks": tasks})
 
 	fmt.Printf("[unassigned-tasks-by-role] Retrieved %d unassigned tasks for role %s\n", len(tasks), role)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Content:    respContent,
+			Flags:      discordgo.MessageFlagsEphemeral,
+			Components: append([]discordgo.MessageComponent{b.taskSelectMenu(i.Locale, tasks)}, b.claimButtons(i.Locale, tasks)...),
 		},
 	})
 }
 
-func (b *DiscordBot) assignTaskCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) assignTaskCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -514,57 +561,58 @@ func (b *DiscordBot) assignTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		return
 	}
 
-	options := i.ApplicationCommandData().Options
-	if len(options) != 2 {
-		fmt.Printf("[assign-task] Invalid number of options provided: %d\n", len(options))
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide exactly two options (task ID and user ID).", utils.Bold("Invalid options")),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
-		return
-	}
-
-	if options[0].Name != "task-id" && options[0].Type != discordgo.ApplicationCommandOptionInteger {
-		fmt.Printf("[assign-task] Invalid option provided: %s\n", options[0].Name)
-		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid task ID**\n\nYou must provide a valid integer ID for the task.",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range i.ApplicationCommandData().Options {
+		optionMap[option.Name] = option
 	}
 
-	if options[1].Name != "user-id" && options[1].Type != discordgo.ApplicationCommandOptionUser {
-		fmt.Printf("[assign-task] Invalid option provided: %s\n", options[1].Name)
+	taskOption, okTask := optionMap["task-id"]
+	userOption, okUser := optionMap["user-id"]
+	githubOption, okGitHub := optionMap["github"]
+	if !okTask || okUser == okGitHub {
+		fmt.Printf("[assign-task] Invalid options provided\n")
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide a valid user ID.", utils.Bold("Invalid user ID")),
+				Content: b.render(i.Locale, "assign-task.invalid-options", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	taskID := options[0].IntValue()
-	userDiscordID := options[1].UserValue(s).ID
+	taskID := taskOption.IntValue()
 
-	user, err := b.getOrCreateUser(userDiscordID, options[1].UserValue(s).Username)
+	var user *db.User
+	var err error
+	if okUser {
+		user, err = b.getOrCreateUser(resolvedUser(i, userOption).ID, resolvedUser(i, userOption).Username)
+	} else {
+		user, err = b.db.GetUserByGitHubLogin(githubOption.StringValue())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			fmt.Printf("[assign-task] No member linked to GitHub account %s\n", githubOption.StringValue())
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "common.unknown-github-account", messageData{"Login": githubOption.StringValue()}),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+	}
 	if err != nil {
 		fmt.Printf("[assign-task] Failed to get user: %v\n", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "❌ **Failed to assign task**\n\nAn error occurred while assigning the task. Please try again or contact an administrator.",
+				Content: b.render(i.Locale, "common.assign-failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
+	userDiscordID := user.DiscordID
 
 	err = b.db.AssignTask(uint(taskID), user.ID)
 	if err != nil {
@@ -572,14 +620,14 @@ func (b *DiscordBot) assignTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "❌ **Failed to assign task**\n\nAn error occurred while assigning the task. Please try again or contact an administrator.",
+				Content: b.render(i.Locale, "common.assign-failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	respContent := fmt.Sprintf("✅ %s\n\n%s: %s\n%s: <@%s>", utils.Bold("Task assigned successfully!"), utils.Italic("Task ID"), utils.InlineCode(fmt.Sprintf("%d", taskID)), utils.Italic("Assigned to"), userDiscordID)
+	respContent := b.render(i.Locale, "assign-task.success", messageData{"TaskID": taskID, "AssigneeID": userDiscordID})
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -593,18 +641,11 @@ func (b *DiscordBot) assignTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		fmt.Printf("[assign-task] Failed to get task: %v\n", err)
 		return
 	}
-	message := fmt.Sprintf("👋 Hi <@%s>! You have been assigned a new task:\n\n%s", userDiscordID, utils.Bold(task.Title))
-	if task.Description != "" {
-		message += fmt.Sprintf("\n%s", task.Description)
-	}
-	message += fmt.Sprintf("\n\n🔗 %s: %s", utils.Italic("Task ID"), utils.InlineCode(fmt.Sprintf("%d", task.ID)))
-	err = b.sendPrivateMessage(userDiscordID, message)
-	if err != nil {
-		fmt.Printf("[create-task] Failed to send private message to assignee: %v\n", err)
-	}
+	b.notifyTaskAssigned(task, userDiscordID)
+	b.emitTaskEvent(db.TASK_EVENT_ASSIGNED, task)
 }
 
-func (b *DiscordBot) updateTaskStatusCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) updateTaskStatusCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -619,7 +660,7 @@ func (b *DiscordBot) updateTaskStatusCommand(s *discordgo.Session, i *discordgo.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide exactly two options (task ID and status).", utils.Bold("Invalid options")),
+				Content: b.render(i.Locale, "update-task-status.invalid-options", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -631,7 +672,7 @@ func (b *DiscordBot) updateTaskStatusCommand(s *discordgo.Session, i *discordgo.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid task ID**\n\nYou must provide a valid integer ID for the task.",
+				Content: b.render(i.Locale, "common.invalid-task-id", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -643,7 +684,7 @@ func (b *DiscordBot) updateTaskStatusCommand(s *discordgo.Session, i *discordgo.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide a valid status string.", utils.Bold("Invalid status")),
+				Content: b.render(i.Locale, "update-task-status.invalid-status", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -659,14 +700,14 @@ func (b *DiscordBot) updateTaskStatusCommand(s *discordgo.Session, i *discordgo.
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\n%s", utils.Bold("Failed to update task status"), err.Error()),
+				Content: b.render(i.Locale, "common.status-update-failed", messageData{"Error": err.Error()}),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	respContent := fmt.Sprintf("✅ %s\n\n%s: %s\n%s: %s\n%s: %s %s", utils.Bold("Task status updated successfully!"), utils.Italic("Task ID"), utils.InlineCode(fmt.Sprintf("%d", taskID)), utils.Italic("Title"), task.Title, utils.Italic("New Status"), getStatusIcon(status), status)
+	respContent := b.render(i.Locale, "update-task-status.success", messageData{"Task": task})
 	fmt.Printf("[update-task-status] Task %d status updated to: %s\n", taskID, status)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -676,29 +717,13 @@ func (b *DiscordBot) updateTaskStatusCommand(s *discordgo.Session, i *discordgo.
 		},
 	})
 
-	allUsers := []db.User{task.Author}
-	allUsers = append(allUsers, task.AssignedUsers...)
 	// Notify all assigned users about the status update
-	for _, user := range allUsers {
-		message := fmt.Sprintf(
-			"📢 %s\n\nYour task has been updated:\n\n%s\n\n🔗 %s: %s\n📊 %s: %s %s\n\nUpdated by: <@%s>",
-			utils.Bold("Task Status Update"),
-			utils.Bold(task.Title),
-			utils.Italic("Task ID"),
-			utils.InlineCode(fmt.Sprintf("%d", task.ID)),
-			utils.Italic("New Status"),
-			getStatusIcon(status),
-			status,
-			i.Member.User.ID)
-
-		err = b.sendPrivateMessage(user.DiscordID, message)
-		if err != nil {
-			fmt.Printf("[update-task-status] Failed to send notification to assignee %s: %v\n", user.DiscordID, err)
-		}
-	}
+	b.notifyTaskStatusUpdate(task, i.Member.User.ID)
+	b.onTaskStatusChanged(task)
+	b.emitTaskEvent(db.TASK_EVENT_STATUS_CHANGED, task)
 }
 
-func (b *DiscordBot) getCompletedTasksByRoleCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) getCompletedTasksByRoleCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -713,7 +738,7 @@ func (b *DiscordBot) getCompletedTasksByRoleCommand(s *discordgo.Session, i *dis
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid options**\n\nYou must provide exactly one option (role).",
+				Content: b.render(i.Locale, "common.expect-role", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -725,18 +750,18 @@ func (b *DiscordBot) getCompletedTasksByRoleCommand(s *discordgo.Session, i *dis
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid role**\n\nYou must provide a valid role.",
+				Content: b.render(i.Locale, "common.invalid-role", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	role := options[0].RoleValue(s, b.guildID).Name
+	role := resolvedRole(i, options[0]).Name
 	tasks, err := b.db.GetCompletedTasksByRole(role)
 	if err != nil {
 		fmt.Printf("[completed-tasks-by-role] Failed to get tasks: %v\n", err)
-		respContent := fmt.Sprintf("❌ %s\n\nAn error occurred while fetching tasks. Please try again or contact an administrator.", utils.Bold(fmt.Sprintf("Failed to retrieve completed tasks for role %s", utils.InlineCode(role))))
+		respContent := b.render(i.Locale, "completed-tasks-by-role.failed", messageData{"Role": role})
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
@@ -748,7 +773,7 @@ func (b *DiscordBot) getCompletedTasksByRoleCommand(s *discordgo.Session, i *dis
 	}
 
 	if len(tasks) == 0 {
-		respContent := fmt.Sprintf("🔍 %s\n\nThis role currently has no completed tasks.", utils.Bold(fmt.Sprintf("No completed tasks found for role %s", utils.InlineCode(role))))
+		respContent := b.render(i.Locale, "completed-tasks-by-role.none", messageData{"Role": role})
 		fmt.Printf("[completed-tasks-by-role] No completed tasks found for role %s\n", role)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -760,36 +785,20 @@ func (b *DiscordBot) getCompletedTasksByRoleCommand(s *discordgo.Session, i *dis
 		return
 	}
 
-	respContent := fmt.Sprintf("✅ %s:\n", utils.Bold(fmt.Sprintf("Completed tasks for role %s", utils.InlineCode(role))))
-	for _, task := range tasks {
-		respContent += "--------------------------------\n"
-		respContent += fmt.Sprintf("%s: %d", utils.Italic("ID"), task.ID)
-		respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Title"), task.Title)
-		if task.Description != "" {
-			respContent += fmt.Sprintf("\n%s: %s", utils.Italic("Description"), task.Description)
-		}
-		respContent += fmt.Sprintf("\n%s: <@%s>", utils.Italic("Author"), task.Author.DiscordID)
-		if len(task.AssignedUsers) > 0 {
-			respContent += fmt.Sprintf("\n%s:\n", utils.Italic("Assigned to"))
-			for _, user := range task.AssignedUsers {
-				respContent += fmt.Sprintf("  - <@%s>\n", user.DiscordID)
-			}
-		}
-		respContent += fmt.Sprintf("\n%s: %s %s", utils.Italic("Status"), getStatusIcon(task.Status), task.Status)
-		respContent += "\n"
-	}
+	respContent := b.render(i.Locale, "completed-tasks-by-role.list", messageData{"Role": role, "Tasks": tasks})
 
 	fmt.Printf("[completed-tasks-by-role] Retrieved %d completed tasks for role %s\n", len(tasks), role)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Content:    respContent,
+			Flags:      discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{b.taskSelectMenu(i.Locale, tasks)},
 		},
 	})
 }
 
-func (b *DiscordBot) deleteTaskCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) deleteTaskCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -804,7 +813,7 @@ func (b *DiscordBot) deleteTaskCommand(s *discordgo.Session, i *discordgo.Intera
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("⚠️ %s\n\nYou must provide exactly one option (task ID).", utils.Bold("Invalid options")),
+				Content: b.render(i.Locale, "common.expect-task-id", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
@@ -813,35 +822,188 @@ func (b *DiscordBot) deleteTaskCommand(s *discordgo.Session, i *discordgo.Intera
 
 	taskID := options[0].IntValue()
 
-	// Get task details before deletion for notifications
+	// Make sure the task exists before asking for confirmation
 	task, err := b.db.GetTaskByID(uint(taskID))
 	if err != nil {
 		fmt.Printf("[delete-task] Failed to get task details: %v\n", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while retrieving task details. Please try again or contact an administrator.", utils.Bold("Failed to delete task")),
+				Content: b.render(i.Locale, "delete-task.lookup-failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	err = b.db.DeleteTask(uint(taskID))
+	respContent := b.render(i.Locale, "delete-task.confirm", messageData{
+		"Task":          task,
+		"RetentionDays": int(b.options.DeletedTaskRetention.Hours() / 24),
+	})
+	fmt.Printf("[delete-task] Asking confirmation to delete task %d\n", task.ID)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    b.render(i.Locale, "delete-task.confirm-button", nil),
+							Style:    discordgo.DangerButton,
+							CustomID: fmt.Sprintf("%s:%d", ButtonConfirmDeleteTask, task.ID),
+						},
+						discordgo.Button{
+							Label:    b.render(i.Locale, "delete-task.cancel-button", nil),
+							Style:    discordgo.SecondaryButton,
+							CustomID: fmt.Sprintf("%s:%d", ButtonCancelDeleteTask, task.ID),
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (b *DiscordBot) deleteTaskConfirmationComponent(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+	rawTaskID, confirmed := strings.CutPrefix(customID, ButtonConfirmDeleteTask+":")
+	if !confirmed {
+		var cancelled bool
+		rawTaskID, cancelled = strings.CutPrefix(customID, ButtonCancelDeleteTask+":")
+		if !cancelled {
+			return
+		}
+	}
+
+	taskID, err := strconv.ParseUint(rawTaskID, 10, 64)
+	if err != nil || i.Member == nil || i.Member.User == nil {
+		fmt.Printf("[delete-task] Invalid confirmation button: %s\n", customID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    b.render(i.Locale, "delete-task.invalid-confirmation", nil),
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+
+	if !confirmed {
+		fmt.Printf("[delete-task] Deletion of task %d cancelled\n", taskID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    b.render(i.Locale, "delete-task.cancelled", messageData{"TaskID": taskID}),
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+
+	// Get task details before deletion for notifications
+	task, err := b.db.GetTaskByID(uint(taskID))
+	if err != nil {
+		fmt.Printf("[delete-task] Failed to get task details: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    b.render(i.Locale, "delete-task.not-found", nil),
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+
+	err = b.db.DeleteTask(task.ID)
 	if err != nil {
 		fmt.Printf("[delete-task] Failed to delete task: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    b.render(i.Locale, "delete-task.failed", nil),
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+		return
+	}
+
+	respContent := b.render(i.Locale, "delete-task.success", messageData{"TaskID": task.ID})
+	fmt.Printf("[delete-task] Task %d deleted successfully\n", task.ID)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    respContent,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+
+	b.notifyTaskDeleted(task, i.Member.User.ID)
+	b.emitTaskEvent(db.TASK_EVENT_DELETED, task)
+}
+
+func (b *DiscordBot) getDeletedTasksCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandDeletedTasks {
+		return
+	}
+
+	if !isAdmin(i) {
+		fmt.Printf("[deleted-tasks] User is not an administrator\n")
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while deleting the task. Please try again or contact an administrator.", utils.Bold("Failed to delete task")),
+				Content: b.render(i.Locale, "common.admin-only", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	respContent := fmt.Sprintf("✅ %s\n\n%s: %s", utils.Bold("Task deleted successfully!"), utils.Italic("Task ID"), utils.InlineCode(fmt.Sprintf("%d", taskID)))
-	fmt.Printf("[delete-task] Task %d deleted successfully\n", taskID)
+	tasks, err := b.db.GetDeletedTasks()
+	if err != nil {
+		fmt.Printf("[deleted-tasks] Failed to get deleted tasks: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "deleted-tasks.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if len(tasks) == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "deleted-tasks.none", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	deletedTasks := make([]messageData, 0, len(tasks))
+	for _, task := range tasks {
+		var purgeAt *time.Time
+		if b.options.DeletedTaskRetention > 0 {
+			purge := task.DeletedAt.Time.Add(b.options.DeletedTaskRetention)
+			purgeAt = &purge
+		}
+		deletedTasks = append(deletedTasks, messageData{"Task": task, "DeletedAt": task.DeletedAt.Time, "PurgeAt": purgeAt})
+	}
+	respContent := b.render(i.Locale, "deleted-tasks.list", messageData{"Tasks": deletedTasks})
+
+	fmt.Printf("[deleted-tasks] Retrieved %d deleted tasks\n", len(tasks))
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -849,71 +1011,391 @@ func (b *DiscordBot) deleteTaskCommand(s *discordgo.Session, i *discordgo.Intera
 			Flags:   discordgo.MessageFlagsEphemeral,
 		},
 	})
+}
 
-	// Notify all users involved about the task deletion
-	allUsers := []db.User{task.Author}
-	allUsers = append(allUsers, task.AssignedUsers...)
+func (b *DiscordBot) restoreTaskCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
 
-	for _, user := range allUsers {
-		message := fmt.Sprintf(
-			"🗑️ %s\n\nA task you were involved with has been deleted:\n\n%s\n\n🔗 %s: %s\n\nDeleted by: <@%s>",
-			utils.Bold("Task Deleted"),
-			utils.Bold(task.Title),
-			utils.Italic("Task ID"),
-			utils.InlineCode(fmt.Sprintf("%d", task.ID)),
-			i.Member.User.ID)
+	if i.ApplicationCommandData().Name != CommandRestoreTask {
+		return
+	}
 
-		err = b.sendPrivateMessage(user.DiscordID, message)
-		if err != nil {
-			fmt.Printf("[delete-task] Failed to send notification to user %s: %v\n", user.DiscordID, err)
-		}
+	if !isAdmin(i) {
+		fmt.Printf("[restore-task] User is not an administrator\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
 	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) != 1 {
+		fmt.Printf("[restore-task] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.expect-task-id", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	taskID := options[0].IntValue()
+	task, err := b.db.RestoreTask(uint(taskID))
+	if err != nil {
+		fmt.Printf("[restore-task] Failed to restore task: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "restore-task.not-in-trash", messageData{"TaskID": taskID}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	respContent := b.render(i.Locale, "restore-task.success", messageData{"Task": task})
+	fmt.Printf("[restore-task] Task %d restored\n", task.ID)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	b.notifyTaskRestored(task, i.Member.User.ID)
 }
 
-func (b *DiscordBot) subscribeChannelToPushWebhookCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) editTaskCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
 
-	if i.ApplicationCommandData().Name != CommandSubscribeChannelToPush {
+	if i.ApplicationCommandData().Name != CommandEditTask {
 		return
 	}
 
 	options := i.ApplicationCommandData().Options
 	if len(options) != 1 {
-		fmt.Printf("[subscribe-channel-to-push] Invalid number of options provided: %d\n", len(options))
+		fmt.Printf("[edit-task] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.expect-task-id", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	taskID := options[0].IntValue()
+	task, err := b.db.GetTaskByID(uint(taskID))
+	if err != nil {
+		fmt.Printf("[edit-task] Failed to get task: %v\n", err)
+		respContent := b.render(i.Locale, "common.task-not-found", messageData{"TaskID": taskID})
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: respContent,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Discord rejects a modal whose values are longer than its inputs allow,
+	// as the text of tasks created before the limits can be
+	if field := taskTextTooLong(task.Title, task.Description); field != "" {
+		limit := taskTitleMaxLength
+		if field == "description" {
+			limit = taskDescriptionMaxLength
+		}
+		fmt.Printf("[edit-task] The %s of task %d is too long for the edit modal\n", field, task.ID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "edit-task.too-long", messageData{"TaskID": task.ID, "Field": field, "Limit": limit}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// All fields are optional: a blank title keeps the current one, while the
+	// description and due date are prefilled so that clearing them removes them.
+	currentDueDate := ""
+	if task.DueDate != nil {
+		currentDueDate = task.DueDate.Format(db.DueDateFormat)
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: fmt.Sprintf("%s:%d", ModalEditTask, task.ID),
+			Title:    truncate(b.render(i.Locale, "edit-task.modal-title", messageData{"TaskID": task.ID}), 45),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "title",
+							Label:       b.render(i.Locale, "edit-task.title-label", nil),
+							Style:       discordgo.TextInputShort,
+							Placeholder: b.render(i.Locale, "edit-task.title-placeholder", nil),
+							Value:       task.Title,
+							Required:    false,
+							MaxLength:   taskTitleMaxLength,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "description",
+							Label:       b.render(i.Locale, "edit-task.description-label", nil),
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: b.render(i.Locale, "edit-task.description-placeholder", nil),
+							Value:       task.Description,
+							Required:    false,
+							MaxLength:   taskDescriptionMaxLength,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "due-date",
+							Label:       b.render(i.Locale, "edit-task.due-date-label", nil),
+							Style:       discordgo.TextInputShort,
+							Placeholder: b.render(i.Locale, "edit-task.due-date-placeholder", nil),
+							Value:       currentDueDate,
+							Required:    false,
+							MaxLength:   len(db.DueDateFormat),
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		fmt.Printf("[edit-task] Failed to open edit modal: %v\n", err)
+	}
+}
+
+func (b *DiscordBot) editTaskModalSubmit(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionModalSubmit {
+		return
+	}
+
+	data := i.ModalSubmitData()
+	rawTaskID, ok := strings.CutPrefix(data.CustomID, ModalEditTask+":")
+	if !ok {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	taskID, err := strconv.ParseUint(rawTaskID, 10, 64)
+	if err != nil {
+		fmt.Printf("[edit-task] Invalid task ID in modal: %s\n", rawTaskID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.invalid-task-id", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	fmt.Printf("[edit-task] Modal submitted by user %s for task %d\n", i.Member.User.Username, taskID)
+
+	editor, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[edit-task] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "edit-task.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	values := getModalValues(data)
+	updateOptions := &db.TaskUpdateOptions{}
+	if title := strings.TrimSpace(values["title"]); title != "" {
+		updateOptions.Title = &title
+	}
+	if description, ok := values["description"]; ok {
+		description = strings.TrimSpace(description)
+		updateOptions.Description = &description
+	}
+	if rawDueDate, ok := values["due-date"]; ok {
+		if rawDueDate = strings.TrimSpace(rawDueDate); rawDueDate == "" {
+			updateOptions.ClearDueDate = true
+		} else {
+			dueDate, err := parseDueDate(rawDueDate)
+			if err != nil {
+				fmt.Printf("[edit-task] Invalid due date: %s\n", rawDueDate)
+				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: b.render(i.Locale, "common.invalid-due-date", nil),
+						Flags:   discordgo.MessageFlagsEphemeral,
+					},
+				})
+				return
+			}
+			updateOptions.DueDate = dueDate
+		}
+	}
+
+	task, changes, err := b.db.UpdateTask(uint(taskID), editor.ID, updateOptions)
+	if err != nil {
+		fmt.Printf("[edit-task] Failed to update task: %v\n", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid options**\n\nYou must provide exactly one option (repository).",
+				Content: b.render(i.Locale, "edit-task.rejected", messageData{"Error": err.Error()}),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	if options[0].Name != "repository" && options[0].Type != discordgo.ApplicationCommandOptionString {
-		fmt.Printf("[subscribe-channel-to-push] Invalid option provided: %s\n", options[0].Name)
+	if len(changes) == 0 {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid repository**\n\nYou must provide a valid repository name.",
+				Content: b.render(i.Locale, "edit-task.unchanged", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	repo := options[0].StringValue()
+	respContent := b.render(i.Locale, "edit-task.success", messageData{"Task": task})
+	fmt.Printf("[edit-task] Task %d edited, %d field(s) changed\n", task.ID, len(changes))
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	b.notifyTaskEdited(task, changes, i.Member.User.ID)
+}
+
+func (b *DiscordBot) subscribeChannelToPushWebhookCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandSubscribeChannelToPush {
+		return
+	}
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range i.ApplicationCommandData().Options {
+		optionMap[option.Name] = option
+	}
+
+	repoOption, ok := optionMap["repository"]
+	if !ok {
+		fmt.Printf("[subscribe-channel-to-push] Repository option not found\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.invalid-repository", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	repo := repoOption.StringValue()
+
+	channelID := i.ChannelID
+	if channel, ok := optionMap["channel"]; ok {
+		channelID = channel.ChannelValue(nil).ID
+	}
+
+	if !b.canManageSubscriptionsIn(s, i, channelID) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.cannot-manage-subscriptions", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	branchOption, hasBranchFilter := optionMap["branch"]
+	branchFilter := ""
+	if hasBranchFilter {
+		branchFilter = strings.TrimSpace(branchOption.StringValue())
+		if !isValidBranchFilter(branchFilter) {
+			fmt.Printf("[subscribe-channel-to-push] Invalid branch filter: %s\n", branchFilter)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "subscribe.invalid-branch-filter", nil),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+	}
+
+	// Pushes would only pile up as failed deliveries otherwise
+	if err := b.checkBotCanPost(s, channelID); err != nil {
+		fmt.Printf("[subscribe-channel-to-push] Bot can't post in channel %s: %v\n", channelID, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "subscribe.missing-permissions", messageData{"ChannelID": channelID, "Error": err.Error()}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
 
-	_, err := b.db.CreateWebhookSubscription(repo, i.ChannelID)
+	_, err := b.db.CreateWebhookSubscription(repo, channelID)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			fmt.Printf("[subscribe-channel-to-push] Channel %s already subscribed to push webhook for repository %s\n", i.ChannelID, repo)
+			fmt.Printf("[subscribe-channel-to-push] Channel %s already subscribed to push webhook for repository %s\n", channelID, repo)
+			respContent := b.render(i.Locale, "subscribe.already-subscribed", nil)
+
+			// Subscribing again with a branch filter changes the filter
+			if hasBranchFilter {
+				if err := b.db.SetWebhookSubscriptionBranchFilter(repo, channelID, branchFilter); err != nil {
+					fmt.Printf("[subscribe-channel-to-push] Failed to set branch filter: %v\n", err)
+				} else {
+					respContent = b.render(i.Locale, "subscribe.branch-filter-updated", messageData{"Repository": repo, "BranchFilter": branchFilter})
+				}
+			}
+
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Content: fmt.Sprintf("✅ %s\n\nThe channel is already subscribed to the push webhook for the repository.", utils.Bold("Channel already subscribed to push webhook")),
+					Content: respContent,
 					Flags:   discordgo.MessageFlagsEphemeral,
 				},
 			})
@@ -923,15 +1405,22 @@ func (b *DiscordBot) subscribeChannelToPushWebhookCommand(s *discordgo.Session,
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while subscribing the channel to the push webhook. Please try again or contact an administrator.", utils.Bold("Failed to subscribe channel to push")),
+				Content: b.render(i.Locale, "subscribe.failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	respContent := fmt.Sprintf("✅ %s\n\n%s: %s\n%s: %s", utils.Bold(fmt.Sprintf("Channel subscribed to push webhook for repository %s", utils.InlineCode(repo))), utils.Italic("Repository"), utils.InlineCode(repo), utils.Italic("Channel"), utils.InlineCode(i.ChannelID))
-	fmt.Printf("[subscribe-channel-to-push] Channel %s subscribed to push webhook for repository %s\n", i.ChannelID, repo)
+	// A re-enabled subscription keeps its old filter unless a new one is given
+	if hasBranchFilter {
+		if err := b.db.SetWebhookSubscriptionBranchFilter(repo, channelID, branchFilter); err != nil {
+			fmt.Printf("[subscribe-channel-to-push] Failed to set branch filter: %v\n", err)
+		}
+	}
+
+	respContent := b.render(i.Locale, "subscribe.success", messageData{"Repository": repo, "ChannelID": channelID, "BranchFilter": branchFilter})
+	fmt.Printf("[subscribe-channel-to-push] Channel %s subscribed to push webhook for repository %s\n", channelID, repo)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -940,7 +1429,7 @@ func (b *DiscordBot) subscribeChannelToPushWebhookCommand(s *discordgo.Session,
 	})
 }
 
-func (b *DiscordBot) unsubscribeChannelFromPushWebhookCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) unsubscribeChannelFromPushWebhookCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -949,48 +1438,57 @@ func (b *DiscordBot) unsubscribeChannelFromPushWebhookCommand(s *discordgo.Sessi
 		return
 	}
 
-	options := i.ApplicationCommandData().Options
-	if len(options) != 1 {
-		fmt.Printf("[unsubscribe-channel-from-push] Invalid number of options provided: %d\n", len(options))
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range i.ApplicationCommandData().Options {
+		optionMap[option.Name] = option
+	}
+
+	repoOption, ok := optionMap["repository"]
+	if !ok {
+		fmt.Printf("[unsubscribe-channel-from-push] Repository option not found\n")
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid options**\n\nYou must provide exactly one option (repository).",
+				Content: b.render(i.Locale, "common.invalid-repository", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
+	repo := repoOption.StringValue()
+
+	channelID := i.ChannelID
+	if channel, ok := optionMap["channel"]; ok {
+		channelID = channel.ChannelValue(nil).ID
+	}
 
-	if options[0].Name != "repository" && options[0].Type != discordgo.ApplicationCommandOptionString {
-		fmt.Printf("[unsubscribe-channel-from-push] Invalid option provided: %s\n", options[0].Name)
+	if !b.canManageSubscriptionsIn(s, i, channelID) {
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "⚠️ **Invalid repository**\n\nYou must provide a valid repository name.",
+				Content: b.render(i.Locale, "common.cannot-manage-subscriptions", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	repo := options[0].StringValue()
-
-	err := b.db.DeleteWebhookSubscription(repo, i.ChannelID)
+	err := b.db.DeleteWebhookSubscription(repo, channelID)
 	if err != nil {
 		fmt.Printf("[unsubscribe-channel-from-push] Failed to delete webhook subscription: %v\n", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while unsubscribing the channel from the push webhook. Please try again or contact an administrator.", utils.Bold("Failed to unsubscribe channel from push")),
+				Content: b.render(i.Locale, "unsubscribe.failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	respContent := fmt.Sprintf("✅ %s\n\n%s: %s\n%s: %s", utils.Bold(fmt.Sprintf("Channel unsubscribed from push webhook for repository %s", utils.InlineCode(repo))), utils.Italic("Repository"), utils.InlineCode(repo), utils.Italic("Channel"), utils.InlineCode(i.ChannelID))
-	fmt.Printf("[unsubscribe-channel-from-push] Channel %s unsubscribed from push webhook for repository %s\n", i.ChannelID, repo)
+	respContent := b.render(i.Locale, "unsubscribe.success", messageData{"Repository": repo, "ChannelID": channelID})
+
+	fmt.Printf("[unsubscribe-channel-from-push] Channel %s unsubscribed from push webhook for repository %s\n", channelID, repo)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
@@ -999,7 +1497,7 @@ func (b *DiscordBot) unsubscribeChannelFromPushWebhookCommand(s *discordgo.Sessi
 	})
 }
 
-func (b *DiscordBot) getSubscriptionsOfChannelCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+func (b *DiscordBot) getSubscriptionsOfChannelCommand(s Session, i *discordgo.InteractionCreate) {
 	if i.Type != discordgo.InteractionApplicationCommand {
 		return
 	}
@@ -1008,31 +1506,23 @@ func (b *DiscordBot) getSubscriptionsOfChannelCommand(s *discordgo.Session, i *d
 		return
 	}
 
-	subscriptions, err := b.db.GetWebhookSubscriptionsByRepository(i.ChannelID)
+	respData, err := b.subscriptionsResponseData(i.Locale, subscriptionScopeChannel, i.ChannelID)
 	if err != nil {
-		fmt.Printf("[get-subscriptions-of-channel] Failed to get webhook subscriptions by repository: %v\n", err)
+		fmt.Printf("[get-subscriptions-of-channel] Failed to get webhook subscriptions of channel: %v\n", err)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("❌ %s\n\nAn error occurred while fetching the webhook subscriptions. Please try again or contact an administrator.", utils.Bold("Failed to get webhook subscriptions by repository")),
+				Content: b.render(i.Locale, "subscriptions.channel-failed", nil),
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
 	}
 
-	respContent := fmt.Sprintf("🔍 %s\n\n", utils.Bold("Subscriptions of channel:"))
-	for _, subscription := range subscriptions {
-		respContent += fmt.Sprintf("%s: %s\n", utils.Italic("Repository"), utils.InlineCode(subscription.Repository.Name))
-	}
-
-	fmt.Printf("[get-subscriptions-of-channel] Retrieved %d webhook subscriptions for channel %s\n", len(subscriptions), i.ChannelID)
+	fmt.Printf("[get-subscriptions-of-channel] Retrieved webhook subscriptions for channel %s\n", i.ChannelID)
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: respContent,
-			Flags:   discordgo.MessageFlagsEphemeral,
-		},
+		Data: respData,
 	})
 }
 
@@ -1050,6 +1540,88 @@ func getStatusIcon(status string) string {
 	}
 }
 
+// parseDueDate parses a YYYY-MM-DD due date in the server time zone
+// taskTextTooLong returns the name of the field of a task, "title" or
+// "description", whose text is longer than its limit, or an empty string
+func taskTextTooLong(title string, description string) string {
+	switch {
+	case utf8.RuneCountInString(title) > taskTitleMaxLength:
+		return "title"
+	case utf8.RuneCountInString(description) > taskDescriptionMaxLength:
+		return "description"
+	default:
+		return ""
+	}
+}
+
+func parseDueDate(value string) (*time.Time, error) {
+	dueDate, err := time.ParseInLocation(db.DueDateFormat, strings.TrimSpace(value), time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &dueDate, nil
+}
+
+// getModalValues collects the values of the text inputs of a submitted modal, keyed by custom ID
+func getModalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := make(map[string]string)
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, rowComponent := range row.Components {
+			if input, ok := rowComponent.(*discordgo.TextInput); ok {
+				values[input.CustomID] = input.Value
+			}
+		}
+	}
+	return values
+}
+
+// isAdmin reports whether the member who triggered the interaction is a server administrator
+func isAdmin(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	return i.Member.Permissions&discordgo.PermissionAdministrator != 0
+}
+
+// canManageSubscriptions reports whether the member who triggered the interaction
+// can change push subscriptions: they need Manage Channels or the bot admin role
+func (b *DiscordBot) canManageSubscriptions(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	if i.Member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageChannels) != 0 {
+		return true
+	}
+	return b.options.AdminRoleID != "" && slices.Contains(i.Member.Roles, b.options.AdminRoleID)
+}
+
+// canManageSubscriptionsIn reports whether the member who triggered the
+// interaction can change the push subscriptions of a channel. The permissions
+// sent with the interaction are the ones in the channel it was triggered in,
+// so those in any other channel are fetched.
+func (b *DiscordBot) canManageSubscriptionsIn(s Session, i *discordgo.InteractionCreate, channelID string) bool {
+	if channelID == i.ChannelID {
+		return b.canManageSubscriptions(i)
+	}
+	if i.Member == nil || i.Member.User == nil {
+		return false
+	}
+	if isAdmin(i) || (b.options.AdminRoleID != "" && slices.Contains(i.Member.Roles, b.options.AdminRoleID)) {
+		return true
+	}
+
+	permissions, err := s.UserChannelPermissions(i.Member.User.ID, channelID)
+	if err != nil {
+		fmt.Printf("[subscriptions] Failed to get permissions of %s in channel %s: %v\n", i.Member.User.ID, channelID, err)
+		return false
+	}
+	return permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageChannels) != 0
+}
+
 // getOrCreateUser retrieves a user by Discord ID or creates a new one if not found
 func (b *DiscordBot) getOrCreateUser(discordID, username string) (*db.User, error) {
 	user, err := b.db.GetUserByDiscordID(discordID, &db.UserRetrieveOptions{
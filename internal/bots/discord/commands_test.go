@@ -0,0 +1,423 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRole = &discordgo.Role{ID: "role-1", Name: "Electronics"}
+
+func createTestUser(t *testing.T, database *db.DB, discordID string, username string) *db.User {
+	user := &db.User{Username: username, DiscordID: discordID}
+	require.NoError(t, database.CreateUser(user))
+	return user
+}
+
+// createTestTask creates a task of the role authored by the user 111, which must exist
+func createTestTask(t *testing.T, database *db.DB, title string, role string, assigneeDiscordID string) *db.Task {
+	task := &db.Task{Title: title, Role: role, Status: db.TASK_NOT_STARTED}
+	require.NoError(t, database.CreateTaskWithUserDiscordID(task, "111", assigneeDiscordID))
+	return task
+}
+
+func TestCreateTaskCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	assignee := &discordgo.User{ID: "2", Username: "bob"}
+
+	bot.handleInteraction(session, commandInteraction(CommandCreateTask, testMember("1", "alice", 0),
+		stringOption("title", "Wire the BMS"),
+		stringOption("description", "Use the new harness"),
+		userOption("assignee", assignee),
+		roleOption("role", testRole),
+		stringOption("due-date", "2026-11-02"),
+	))
+
+	resp := session.response(t)
+	assert.Contains(t, resp.Data.Content, "Task created successfully!")
+	require.Len(t, resp.Data.Embeds, 1)
+	assert.Contains(t, resp.Data.Embeds[0].Title, "Wire the BMS")
+
+	tasks, err := bot.db.GetTasksByRole("Electronics")
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "1", tasks[0].Author.DiscordID)
+	require.Len(t, tasks[0].AssignedUsers, 1)
+	assert.Equal(t, "2", tasks[0].AssignedUsers[0].DiscordID)
+	require.NotNil(t, tasks[0].DueDate)
+	assert.Equal(t, "2026-11-02", tasks[0].DueDate.Format(db.DueDateFormat))
+
+	dms := session.messagesTo(dmChannelID("2"))
+	require.Len(t, dms, 1)
+	assert.Contains(t, dms[0].Content, "You have been assigned a new task")
+}
+
+func TestCreateTaskCommandInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  *discordgo.Member
+		options []testOption
+		want    string
+	}{
+		{"outside of a server", nil, []testOption{stringOption("title", "Wire the BMS")}, "Access denied"},
+		{"invalid due date", testMember("1", "alice", 0), []testOption{stringOption("title", "Wire the BMS"), stringOption("due-date", "next monday")}, "Invalid due date"},
+		{"unknown GitHub account", testMember("1", "alice", 0), []testOption{stringOption("title", "Wire the BMS"), stringOption("assignee-github", "octocat")}, "Unknown GitHub account"},
+		{"two assignees", testMember("1", "alice", 0), []testOption{stringOption("title", "Wire the BMS"), userOption("assignee", &discordgo.User{ID: "2"}), stringOption("assignee-github", "octocat")}, "Invalid options"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, session := newTestBot(t)
+
+			bot.handleInteraction(session, commandInteraction(CommandCreateTask, tt.member, tt.options...))
+
+			resp := session.response(t)
+			assert.Contains(t, resp.Data.Content, tt.want)
+			assert.Equal(t, discordgo.MessageFlagsEphemeral, resp.Data.Flags)
+
+			_, err := bot.db.GetTaskByID(1)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetTaskCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+
+	bot.handleInteraction(session, commandInteraction(CommandGetTask, testMember("1", "alice", 0), intOption("id", int(task.ID))))
+
+	resp := session.response(t)
+	require.Len(t, resp.Data.Embeds, 1)
+	assert.Equal(t, fmt.Sprintf("Task #%d: Wire the BMS", task.ID), resp.Data.Embeds[0].Title)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandGetTask, testMember("1", "alice", 0), intOption("id", 42)))
+	assert.Contains(t, session.content(t), "Task not found!")
+}
+
+func TestGetAssignedTasksCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	createTestUser(t, bot.db, "1", "alice")
+	createTestTask(t, bot.db, "Wire the BMS", "", "1")
+	createTestTask(t, bot.db, "Tune the suspension", "", "")
+
+	bot.handleInteraction(session, commandInteraction(CommandAssignedTasks, testMember("1", "alice", 0)))
+
+	content := session.content(t)
+	assert.Contains(t, content, "Wire the BMS")
+	assert.NotContains(t, content, "Tune the suspension")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandAssignedTasks, testMember("2", "bob", 0)))
+	assert.Contains(t, session.content(t), "You have no tasks assigned")
+}
+
+func TestTasksByRoleCommands(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	createTestUser(t, bot.db, "1", "alice")
+	createTestTask(t, bot.db, "Wire the BMS", "Electronics", "")
+	createTestTask(t, bot.db, "Route the CAN bus", "Electronics", "1")
+	completed := createTestTask(t, bot.db, "Order the connectors", "Electronics", "")
+	_, err := bot.db.UpdateTaskStatus(completed.ID, db.TASK_COMPLETED)
+	require.NoError(t, err)
+	createTestTask(t, bot.db, "Tune the suspension", "Vehicle dynamics", "")
+
+	tests := []struct {
+		command string
+		want    []string
+		notWant []string
+	}{
+		{CommandGetTasksByRole, []string{"Wire the BMS", "Route the CAN bus"}, []string{"Order the connectors", "Tune the suspension"}},
+		{CommandUnassignedTasksByRole, []string{"Wire the BMS"}, []string{"Route the CAN bus", "Order the connectors"}},
+		{CommandCompletedTasksByRole, []string{"Order the connectors"}, []string{"Wire the BMS", "Route the CAN bus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			session.reset()
+			bot.handleInteraction(session, commandInteraction(tt.command, testMember("1", "alice", 0), roleOption("role", testRole)))
+
+			content := session.content(t)
+			assert.Contains(t, content, "`Electronics`")
+			for _, title := range tt.want {
+				assert.Contains(t, content, title)
+			}
+			for _, title := range tt.notWant {
+				assert.NotContains(t, content, title)
+			}
+		})
+	}
+
+	t.Run("role without tasks", func(t *testing.T) {
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandGetTasksByRole, testMember("1", "alice", 0), roleOption("role", &discordgo.Role{ID: "role-2", Name: "Aero"})))
+
+		assert.Contains(t, session.content(t), "No tasks found for role `Aero`")
+	})
+}
+
+func TestAssignTaskCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+
+	bot.handleInteraction(session, commandInteraction(CommandAssignTask, testMember("1", "alice", 0),
+		intOption("task-id", int(task.ID)),
+		userOption("user-id", &discordgo.User{ID: "2", Username: "bob"}),
+	))
+
+	assert.Contains(t, session.content(t), "Task assigned successfully!")
+	assert.Len(t, session.messagesTo(dmChannelID("2")), 1)
+
+	updated, err := bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	require.Len(t, updated.AssignedUsers, 1)
+	assert.Equal(t, "2", updated.AssignedUsers[0].DiscordID)
+
+	t.Run("by GitHub account", func(t *testing.T) {
+		carol := createTestUser(t, bot.db, "3", "carol")
+		_, err := bot.db.LinkGitHubLogin(carol.ID, "carol-gh")
+		require.NoError(t, err)
+
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandAssignTask, testMember("1", "alice", 0),
+			intOption("task-id", int(task.ID)),
+			stringOption("github", "carol-gh"),
+		))
+		assert.Contains(t, session.content(t), "<@3>")
+
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandAssignTask, testMember("1", "alice", 0),
+			intOption("task-id", int(task.ID)),
+			stringOption("github", "octocat"),
+		))
+		assert.Contains(t, session.content(t), "Unknown GitHub account")
+	})
+
+	t.Run("both a member and a GitHub account", func(t *testing.T) {
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandAssignTask, testMember("1", "alice", 0),
+			intOption("task-id", int(task.ID)),
+			userOption("user-id", &discordgo.User{ID: "2", Username: "bob"}),
+			stringOption("github", "carol-gh"),
+		))
+		assert.Contains(t, session.content(t), "Invalid options")
+	})
+}
+
+func TestUpdateTaskStatusCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+
+	bot.handleInteraction(session, commandInteraction(CommandUpdateTaskStatus, testMember("1", "alice", 0),
+		intOption("task-id", int(task.ID)),
+		stringOption("status", db.TASK_IN_PROGRESS),
+	))
+
+	assert.Contains(t, session.content(t), "Task status updated successfully!")
+	updated, err := bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.TASK_IN_PROGRESS, updated.Status)
+
+	dms := session.messagesTo(dmChannelID("111"))
+	require.Len(t, dms, 1)
+	assert.Contains(t, dms[0].Content, "Updated by: <@1>")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandUpdateTaskStatus, testMember("1", "alice", 0),
+		intOption("task-id", int(task.ID)),
+		stringOption("status", "Blocked"),
+	))
+	assert.Contains(t, session.content(t), "Failed to update task status")
+}
+
+func TestDeleteAndRestoreTask(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "")
+	member := testMember("1", "alice", 0)
+	admin := testMember("2", "bob", discordgo.PermissionAdministrator)
+
+	bot.handleInteraction(session, commandInteraction(CommandDeleteTask, member, intOption("id", int(task.ID))))
+	resp := session.response(t)
+	assert.Contains(t, resp.Data.Content, "Are you sure")
+	require.Len(t, resp.Data.Components, 1)
+
+	// Cancelling keeps the task
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(fmt.Sprintf("%s:%d", ButtonCancelDeleteTask, task.ID), member))
+	assert.Equal(t, discordgo.InteractionResponseUpdateMessage, session.response(t).Type)
+	assert.Contains(t, session.content(t), "Deletion cancelled")
+	_, err := bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(fmt.Sprintf("%s:%d", ButtonConfirmDeleteTask, task.ID), member))
+	assert.Contains(t, session.content(t), "Task deleted successfully!")
+	_, err = bot.db.GetTaskByID(task.ID)
+	require.Error(t, err)
+
+	// Only administrators can see and restore deleted tasks
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandDeletedTasks, member))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandDeletedTasks, admin))
+	assert.Contains(t, session.content(t), "Wire the BMS")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRestoreTask, member, intOption("id", int(task.ID))))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRestoreTask, admin, intOption("id", int(task.ID))))
+	assert.Contains(t, session.content(t), "Task restored successfully!")
+	_, err = bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+}
+
+func TestEditTask(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	createTestUser(t, bot.db, "2", "bob")
+	task := createTestTask(t, bot.db, "Wire the BMS", "", "2")
+	member := testMember("1", "alice", 0)
+
+	bot.handleInteraction(session, commandInteraction(CommandEditTask, member, intOption("task-id", int(task.ID))))
+	resp := session.response(t)
+	assert.Equal(t, discordgo.InteractionResponseModal, resp.Type)
+	assert.Equal(t, fmt.Sprintf("%s:%d", ModalEditTask, task.ID), resp.Data.CustomID)
+
+	session.reset()
+	bot.handleInteraction(session, modalInteraction(resp.Data.CustomID, member, map[string]string{
+		"title":       "Wire the new BMS",
+		"description": "",
+		"due-date":    "2026-11-02",
+	}))
+	assert.Contains(t, session.content(t), "Task edited successfully!")
+
+	updated, err := bot.db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Wire the new BMS", updated.Title)
+	require.NotNil(t, updated.DueDate)
+	assert.Len(t, session.messagesTo(dmChannelID("2")), 1)
+
+	session.reset()
+	bot.handleInteraction(session, modalInteraction(resp.Data.CustomID, member, map[string]string{"due-date": "soon"}))
+	assert.Contains(t, session.content(t), "Invalid due date")
+
+	t.Run("description over the limit", func(t *testing.T) {
+		// Tasks created before the limits can have longer texts
+		description := strings.Repeat("Use the new harness. ", 200)
+		_, _, err := bot.db.UpdateTask(task.ID, 1, &db.TaskUpdateOptions{Description: &description})
+		require.NoError(t, err)
+
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandEditTask, member, intOption("task-id", int(task.ID))))
+		resp := session.response(t)
+		assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, resp.Type)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, resp.Data.Flags)
+		assert.Contains(t, resp.Data.Content, "The description of task #1 is longer than the 4000 characters")
+	})
+}
+
+func TestSubscribeChannelToPushCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	bot.options.AdminRoleID = "role-bot-admin"
+	require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "king"}))
+	canPost := int64(discordgo.PermissionViewChannel | discordgo.PermissionSendMessages)
+	manager := testMember("1", "alice", discordgo.PermissionManageChannels)
+
+	t.Run("without permission", func(t *testing.T) {
+		session.reset()
+		session.permissions["channel-1"] = canPost
+		bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, testMember("2", "bob", 0), stringOption("repository", "king")))
+
+		assert.Contains(t, session.content(t), "Access denied")
+	})
+
+	t.Run("bot can't post", func(t *testing.T) {
+		session.reset()
+		session.permissions["channel-1"] = discordgo.PermissionViewChannel
+		bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, manager, stringOption("repository", "king")))
+
+		content := session.content(t)
+		assert.Contains(t, content, "Missing permissions")
+		assert.Contains(t, content, "missing Send Messages")
+	})
+
+	t.Run("without permission in the other channel", func(t *testing.T) {
+		// Manage Channels in channel-1, where the command is run, doesn't
+		// allow changing the subscriptions of channel-2
+		session.reset()
+		session.permissions["channel-2"] = canPost
+		session.setMemberPermissions("1", "channel-2", discordgo.PermissionViewChannel)
+		bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, manager,
+			stringOption("repository", "king"),
+			channelOption("channel", "channel-2"),
+		))
+		assert.Contains(t, session.content(t), "Access denied")
+
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandUnsubscribeChannelFromPush, manager,
+			stringOption("repository", "king"),
+			channelOption("channel", "channel-2"),
+		))
+		assert.Contains(t, session.content(t), "Access denied")
+	})
+
+	t.Run("other channel", func(t *testing.T) {
+		session.reset()
+		session.permissions["channel-2"] = canPost
+		session.setMemberPermissions("1", "channel-2", discordgo.PermissionManageChannels)
+		bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, manager,
+			stringOption("repository", "king"),
+			stringOption("branch", "release/*"),
+			channelOption("channel", "channel-2"),
+		))
+
+		content := session.content(t)
+		assert.Contains(t, content, "<#channel-2>")
+		assert.Contains(t, content, "`release/*`")
+	})
+
+	t.Run("bot admin role", func(t *testing.T) {
+		member := testMember("3", "carol", 0)
+		member.Roles = []string{"role-bot-admin"}
+		session.reset()
+		session.permissions["channel-1"] = canPost
+		bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, member, stringOption("repository", "king")))
+
+		assert.Contains(t, session.content(t), "Channel subscribed")
+	})
+
+	subscriptions, err := bot.db.GetAllWebhookSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandGetSubscriptionsOfChannel, manager))
+	assert.Contains(t, session.content(t), "`king`")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandUnsubscribeChannelFromPush, manager,
+		stringOption("repository", "king"),
+		channelOption("channel", "channel-2"),
+	))
+	assert.Contains(t, session.content(t), "Channel unsubscribed")
+
+	subscriptions, err = bot.db.GetWebhookSubscriptionsByChannel("channel-2")
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
@@ -0,0 +1,141 @@
+package discord
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/Formula-SAE/discord/internal/utils"
+)
+
+var (
+	// "#task-42" anywhere in a commit message references task 42
+	taskReferenceRegexp = regexp.MustCompile(`(?i)(?:^|[^\w#])#task-(\d+)\b`)
+
+	// "closes task-42", "fixes #task-42" and the like close task 42
+	taskClosingRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s+#?task-(\d+)\b`)
+)
+
+type taskReference struct {
+	TaskID uint
+	Closes bool
+}
+
+// parseTaskReferences returns the tasks referenced by a commit message, in
+// order of first appearance
+func parseTaskReferences(message string) []taskReference {
+	type match struct {
+		position int
+		taskID   uint
+		closes   bool
+	}
+
+	matches := make([]match, 0)
+	for _, regexpMatch := range []struct {
+		regexp *regexp.Regexp
+		closes bool
+	}{
+		{taskReferenceRegexp, false},
+		{taskClosingRegexp, true},
+	} {
+		for _, indexes := range regexpMatch.regexp.FindAllStringSubmatchIndex(message, -1) {
+			taskID, err := strconv.ParseUint(message[indexes[2]:indexes[3]], 10, 64)
+			if err != nil || taskID == 0 {
+				continue
+			}
+			matches = append(matches, match{position: indexes[0], taskID: uint(taskID), closes: regexpMatch.closes})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].position < matches[j].position
+	})
+
+	references := make([]taskReference, 0, len(matches))
+	seen := make(map[uint]int)
+	for _, m := range matches {
+		if index, ok := seen[m.taskID]; ok {
+			references[index].Closes = references[index].Closes || m.closes
+			continue
+		}
+		seen[m.taskID] = len(references)
+		references = append(references, taskReference{TaskID: m.taskID, Closes: m.closes})
+	}
+
+	return references
+}
+
+// linkCommitsToTasks comments the tasks referenced by the pushed commits with
+// a link to the commit, and completes the ones the commits close
+func (b *DiscordBot) linkCommitsToTasks(payload *PushEvent, discordIDs map[string]string) {
+	branch := getBranchName(payload.Ref)
+
+	// As on GitHub, closing keywords take effect only once the commit reaches
+	// the default branch; on other branches the task is just referenced
+	canClose := payload.Repository.DefaultBranch == "" || branch == payload.Repository.DefaultBranch
+
+	var botUser *db.User
+	for _, commit := range payload.Commits {
+		references := parseTaskReferences(commit.Message)
+		if len(references) == 0 {
+			continue
+		}
+
+		if botUser == nil {
+			if b.botUser() == nil {
+				fmt.Printf("[commits] Bot user not available, can't link commits to tasks\n")
+				return
+			}
+
+			var err error
+			botUser, err = b.getOrCreateUser(b.botUser().ID, b.botUser().Username)
+			if err != nil {
+				fmt.Printf("[commits] Failed to get or create bot user: %v\n", err)
+				return
+			}
+		}
+
+		title := strings.SplitN(commit.Message, "\n", 2)[0]
+		author := formatGitHubUser(commit.Author.Username, utils.EscapeMarkdown(commit.Author.Name), discordIDs)
+		text := b.render(b.options.DefaultLocale, "notify.commit-reference", messageData{"CommitLink": utils.EscapedLink(title, commit.URL), "Author": author, "Branch": branch})
+
+		for _, reference := range references {
+			_, created, err := b.db.AddTaskCommitComment(reference.TaskID, botUser.ID, commit.ID, text)
+			if err != nil {
+				fmt.Printf("[commits] Failed to link commit %s to task %d: %v\n", commit.ID, reference.TaskID, err)
+				continue
+			}
+			if created {
+				fmt.Printf("[commits] Linked commit %s to task %d\n", commit.ID, reference.TaskID)
+			}
+
+			if reference.Closes && canClose {
+				b.closeTaskFromCommit(reference.TaskID, title, commit.URL, author)
+			}
+		}
+	}
+}
+
+func (b *DiscordBot) closeTaskFromCommit(taskID uint, title string, url string, author string) {
+	task, err := b.db.GetTaskByID(taskID)
+	if err != nil {
+		fmt.Printf("[commits] Failed to get task %d: %v\n", taskID, err)
+		return
+	}
+	if task.Status == db.TASK_COMPLETED {
+		return
+	}
+
+	task, err = b.db.UpdateTaskStatus(task.ID, db.TASK_COMPLETED)
+	if err != nil {
+		fmt.Printf("[commits] Failed to complete task %d: %v\n", taskID, err)
+		return
+	}
+
+	fmt.Printf("[commits] Task %d completed by a commit of %s\n", task.ID, author)
+	b.notifyTaskClosedByCommit(task, utils.EscapedLink(title, url), author)
+	b.onTaskStatusChanged(task)
+	b.emitTaskEvent(db.TASK_EVENT_STATUS_CHANGED, task)
+}
@@ -0,0 +1,105 @@
+package discord
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskReferences(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		message  string
+		expected []taskReference
+	}{
+		{"reference", "Wire the BMS #task-42", []taskReference{{TaskID: 42}}},
+		{"reference at the start", "#task-42: wire the BMS", []taskReference{{TaskID: 42}}},
+		{"closing without hash", "Wire the BMS, closes task-42", []taskReference{{TaskID: 42, Closes: true}}},
+		{"closing with hash", "Fixes #task-7", []taskReference{{TaskID: 7, Closes: true}}},
+		{"closing in upper case", "resolved #TASK-3", []taskReference{{TaskID: 3, Closes: true}}},
+		{"double hash", "See ##task-1", []taskReference{}},
+		{"inside a word", "foo#task-1", []taskReference{}},
+		{"without hash or keyword", "Wire the BMS for task-42", []taskReference{}},
+		{"task zero", "#task-0", []taskReference{}},
+		{"duplicates", "#task-5 and #task-5", []taskReference{{TaskID: 5}}},
+		{"reference then closing", "Start #task-5\n\nCloses #task-5", []taskReference{{TaskID: 5, Closes: true}}},
+		{"order of appearance", "#task-2 #task-1, fixes task-3, #task-2", []taskReference{{TaskID: 2}, {TaskID: 1}, {TaskID: 3, Closes: true}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseTaskReferences(tt.message))
+		})
+	}
+}
+
+// pushDelivery returns a delivery of a push of commits to a branch of the
+// king repository, whose default branch is main
+func pushDelivery(t *testing.T, branch string, commits ...map[string]any) db.WebhookDelivery {
+	payload, err := json.Marshal(map[string]any{
+		"repository": map[string]any{"name": "king", "default_branch": "main"},
+		"ref":        "refs/heads/" + branch,
+		"pusher":     map[string]any{"name": "alice"},
+		"commits":    commits,
+	})
+	require.NoError(t, err)
+	return db.WebhookDelivery{Event: "push", Repository: "king", Payload: string(payload)}
+}
+
+func testCommit(sha string, message string) map[string]any {
+	return map[string]any{
+		"id":      sha,
+		"message": message,
+		"url":     "https://github.com/ApexCorse/king/commit/" + sha,
+		"author":  map[string]any{"name": "Alice", "username": "alice"},
+	}
+}
+
+func TestPushTaskReferences(t *testing.T) {
+	bot, _ := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	wiring := createTestTask(t, bot.db, "Wire the BMS", testRole.Name, "")
+	calibration := createTestTask(t, bot.db, "Calibrate the IMU", testRole.Name, "")
+	require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "king"}))
+	_, err := bot.db.CreateWebhookSubscription("king", "channel-1")
+	require.NoError(t, err)
+
+	comments := func(task *db.Task) []db.TaskComment {
+		comments, err := bot.db.GetTaskComments(task.ID)
+		require.NoError(t, err)
+		return comments
+	}
+	status := func(task *db.Task) string {
+		task, err := bot.db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		return task.Status
+	}
+
+	// Closing keywords on other branches only reference the task
+	delivery := pushDelivery(t, "feature/bms", testCommit("abc123", "Wire the harness, closes #task-1"))
+	result, _ := bot.processWebhookDelivery(delivery)
+	assert.Equal(t, db.WEBHOOK_DELIVERY_PROCESSED, result)
+	assert.Len(t, comments(wiring), 1)
+	assert.Equal(t, db.TASK_NOT_STARTED, status(wiring))
+
+	delivery = pushDelivery(t, "main",
+		testCommit("abc123", "Wire the harness, closes #task-1"),
+		testCommit("def456", "Log the IMU offsets for #task-2"),
+	)
+	result, _ = bot.processWebhookDelivery(delivery)
+	assert.Equal(t, db.WEBHOOK_DELIVERY_PROCESSED, result)
+	assert.Equal(t, db.TASK_COMPLETED, status(wiring))
+	assert.Equal(t, db.TASK_NOT_STARTED, status(calibration))
+	// A commit is linked to a task once, whatever the branches it's pushed to
+	assert.Len(t, comments(wiring), 1)
+	require.Len(t, comments(calibration), 1)
+	assert.Equal(t, "def456", comments(calibration)[0].CommitSHA)
+
+	// The push is posted to the subscribed channel as well
+	messages, err := bot.db.GetDueOutboundMessages(time.Now(), 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, messages)
+	assert.Equal(t, "channel-1", messages[0].ChannelID)
+}
@@ -3,6 +3,7 @@ package discord
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/http"
 	"sync"
 	"time"
@@ -13,39 +14,131 @@ import (
 	"github.com/gorilla/mux"
 )
 
+type BotOptions struct {
+	// DeletedTaskRetention is how long deleted tasks stay in the trash before
+	// being purged. Zero keeps them forever.
+	DeletedTaskRetention time.Duration
+
+	// MaxInProgressTasks is how many tasks in progress a member can have
+	// before they can't claim new ones. Zero means no limit.
+	MaxInProgressTasks int
+
+	// NotificationChannelID is the channel where members are mentioned when
+	// they prefer mentions or their DMs can't be delivered. Empty disables it.
+	NotificationChannelID string
+
+	// OutboundWorkers is how many queued messages are delivered concurrently.
+	// Zero uses the default.
+	OutboundWorkers int
+
+	// AdminRoleID is a role whose members can manage the bot like members
+	// with the Manage Channels permission. Empty disables it.
+	AdminRoleID string
+
+	// ReviewReminderThreshold is how long a requested review can be pending
+	// before the reviewer is reminded of it. Zero uses the default.
+	ReviewReminderThreshold time.Duration
+
+	// MessageTemplatesPath is a file of message templates replacing the
+	// default ones with the same name, in the default locale. Empty uses the
+	// default messages.
+	MessageTemplatesPath string
+
+	// DefaultLocale is the locale of the messages posted in channels, like
+	// push notifications and digests, and of the members whose locale isn't
+	// supported. Empty is English.
+	DefaultLocale discordgo.Locale
+
+	// WebhookSecret is the secret the GitHub webhooks sign their deliveries
+	// with. The deliveries that aren't signed with it are rejected, and all
+	// of them are when it is empty.
+	WebhookSecret string
+
+	// PublicURL is the address the HTTP server is reached at from outside,
+	// e.g. "https://king.example.com", used in the calendar feed links.
+	// Empty disables the calendar feeds.
+	PublicURL string
+}
+
 type DiscordBot struct {
-	session *discordgo.Session
+	gateway *discordgo.Session
+	session Session
 	db      *db.DB
 	appID   string
 	guildID string
+	options BotOptions
+
+	catalogs catalogs
 
-	router *mux.Router
-	gc     *github.Client
+	outboundWake    chan struct{}
+	webhookWake     chan struct{}
+	taskWebhookWake chan struct{}
+
+	router      *mux.Router
+	gc          *github.Client
+	githubCache *githubCache
+	imports     *pendingImports
 }
 
-func NewDiscordBot(s *discordgo.Session, db *db.DB, appID string, guildID string, router *mux.Router, gc *github.Client) *DiscordBot {
-	return &DiscordBot{
-		session: s,
+func NewDiscordBot(s *discordgo.Session, db *db.DB, appID string, guildID string, router *mux.Router, gc *github.Client, options *BotOptions) *DiscordBot {
+	bot := &DiscordBot{
+		gateway: s,
+		session: withAllowedMentions(s),
 		db:      db,
 		appID:   appID,
 		guildID: guildID,
 		router:  router,
 		gc:      gc,
+
+		githubCache: newGitHubCache(),
+		imports:     newPendingImports(),
+		catalogs:    defaultCatalogs,
+
+		outboundWake:    make(chan struct{}, 1),
+		webhookWake:     make(chan struct{}, 1),
+		taskWebhookWake: make(chan struct{}, 1),
+	}
+	if options != nil {
+		bot.options = *options
 	}
+	return bot
 }
 
 func (b *DiscordBot) Start(ctx context.Context) (func() error, error) {
+	language := defaultLanguage
+	if b.options.DefaultLocale != "" {
+		var ok bool
+		if language, ok = defaultCatalogs.language(b.options.DefaultLocale); !ok {
+			return nil, fmt.Errorf("unsupported default locale %q", b.options.DefaultLocale)
+		}
+	}
+
+	if b.options.MessageTemplatesPath != "" {
+		messages, err := loadMessages(defaultCatalogs[language], b.options.MessageTemplatesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load message templates: %w", err)
+		}
+		b.catalogs = maps.Clone(defaultCatalogs)
+		b.catalogs[language] = messages
+		fmt.Printf("[bot] Message templates of %s loaded from %s\n", language, b.options.MessageTemplatesPath)
+	}
+
 	b.initWebhookHandlers()
+	b.initAPIHandlers()
+	b.initCalendarHandlers()
 
-	err := b.session.Open()
+	err := b.gateway.Open()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open Discord session: %v", err)
 	}
 
+	workersCtx, cancelWorkers := context.WithCancel(ctx)
+
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	githubRepoNames, err := b.updateRepositoriesInDB(ctx)
 	if err != nil {
+		cancelWorkers()
 		return nil, err
 	}
 
@@ -54,30 +147,35 @@ func (b *DiscordBot) Start(ctx context.Context) (func() error, error) {
 	fmt.Printf("[bot] Command handlers registered\n")
 
 	if err := b.initCommands(githubRepoNames); err != nil {
+		cancelWorkers()
 		return nil, err
 	}
 
 	go http.ListenAndServe(":8080", b.router)
+	go b.purgeDeletedTasksPeriodically(workersCtx)
+	go b.runDigestScheduler(workersCtx)
+	go b.runOutboundQueue(workersCtx)
+	go b.runTaskWebhookQueue(workersCtx)
+	go b.runWebhookProcessor(workersCtx)
+	go b.purgeDeliveryHistoryPeriodically(workersCtx)
+	go b.runReviewReminders(workersCtx)
 
-	return b.session.Close, nil
+	return func() error {
+		cancelWorkers()
+		return b.gateway.Close()
+	}, nil
 }
 
 func (b *DiscordBot) initCommandHandlers() {
-	b.session.AddHandler(b.createTaskCommand)
-	b.session.AddHandler(b.getAssignedTasksCommand)
-	b.session.AddHandler(b.getTaskCommand)
-	b.session.AddHandler(b.getTasksByRoleCommand)
-	b.session.AddHandler(b.getUnassignedTasksByRoleCommand)
-	b.session.AddHandler(b.assignTaskCommand)
-	b.session.AddHandler(b.updateTaskStatusCommand)
-	b.session.AddHandler(b.getCompletedTasksByRoleCommand)
-	b.session.AddHandler(b.subscribeChannelToPushWebhookCommand)
-	b.session.AddHandler(b.unsubscribeChannelFromPushWebhookCommand)
-	b.session.AddHandler(b.deleteTaskCommand)
-	b.session.AddHandler(b.getSubscriptionsOfChannelCommand)
+	b.gateway.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		b.handleInteraction(s, i)
+	})
 }
 
 func (b *DiscordBot) initCommands(githubRepoNames []string) error {
+	adminPermissions := int64(discordgo.PermissionAdministrator)
+	manageChannelsPermissions := int64(discordgo.PermissionManageChannels)
+
 	repoOptions := make([]*discordgo.ApplicationCommandOptionChoice, len(githubRepoNames))
 	for i, repoName := range githubRepoNames {
 		repoOptions[i] = &discordgo.ApplicationCommandOptionChoice{
@@ -96,12 +194,14 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 					Name:        "title",
 					Description: "The title of the task",
 					Required:    true,
+					MaxLength:   taskTitleMaxLength,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "description",
 					Description: "The description of the task (optional)",
 					Required:    false,
+					MaxLength:   taskDescriptionMaxLength,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionUser,
@@ -109,12 +209,24 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 					Description: "The user to assign the task to (optional)",
 					Required:    false,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "assignee-github",
+					Description: "The GitHub username of the member to assign the task to (optional)",
+					Required:    false,
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionRole,
 					Name:        "role",
 					Description: "The role to assign the task to (optional)",
 					Required:    false,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "due-date",
+					Description: "The due date of the task, as YYYY-MM-DD (optional)",
+					Required:    false,
+				},
 			},
 		},
 		{
@@ -170,8 +282,14 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 				{
 					Type:        discordgo.ApplicationCommandOptionUser,
 					Name:        "user-id",
-					Description: "The ID of the user to assign the task to",
-					Required:    true,
+					Description: "The user to assign the task to",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "github",
+					Description: "The GitHub username of the member to assign the task to",
+					Required:    false,
 				},
 			},
 		},
@@ -191,18 +309,9 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 					Description: "The new status (Not Started, In Progress, Completed)",
 					Required:    true,
 					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{
-							Name:  "Not Started",
-							Value: db.TASK_NOT_STARTED,
-						},
-						{
-							Name:  "In Progress",
-							Value: db.TASK_IN_PROGRESS,
-						},
-						{
-							Name:  "Completed",
-							Value: db.TASK_COMPLETED,
-						},
+						b.choice(db.TASK_NOT_STARTED, "task.status", messageData{"Status": db.TASK_NOT_STARTED}),
+						b.choice(db.TASK_IN_PROGRESS, "task.status", messageData{"Status": db.TASK_IN_PROGRESS}),
+						b.choice(db.TASK_COMPLETED, "task.status", messageData{"Status": db.TASK_COMPLETED}),
 					},
 				},
 			},
@@ -230,6 +339,19 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 					Required:    true,
 					Choices:     repoOptions,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "branch",
+					Description: "Only post pushes to the branches matching this name or pattern, like release/* (optional)",
+					Required:    false,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The channel to subscribe (defaults to the current one)",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+				},
 			},
 		},
 		{
@@ -243,6 +365,13 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 					Required:    true,
 					Choices:     repoOptions,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The channel to unsubscribe (defaults to the current one)",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildNews},
+				},
 			},
 		},
 		{
@@ -261,6 +390,381 @@ func (b *DiscordBot) initCommands(githubRepoNames []string) error {
 			Name:        CommandGetSubscriptionsOfChannel,
 			Description: "Get all subscriptions of the current channel",
 		},
+		{
+			Name:                     CommandAllSubscriptions,
+			Description:              "Get the push webhook subscriptions of every channel",
+			DefaultMemberPermissions: &adminPermissions,
+		},
+		{
+			Name:        CommandEditTask,
+			Description: "Edit the title and description of a task",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "task-id",
+					Description: "The ID of the task to edit",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        CommandClaimTask,
+			Description: "Assign an unassigned task to yourself and start it",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the task to claim",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     CommandDeletedTasks,
+			Description:              "List the deleted tasks that can still be restored",
+			DefaultMemberPermissions: &adminPermissions,
+		},
+		{
+			Name:                     CommandRestoreTask,
+			Description:              "Restore a deleted task",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the task to restore",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     CommandAddDigest,
+			Description:              "Post a weekly digest of a role to a channel",
+			DefaultMemberPermissions: &manageChannelsPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role to summarize",
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The channel to post the digest to (defaults to the current one)",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+			},
+		},
+		{
+			Name:                     CommandRemoveDigest,
+			Description:              "Stop posting the weekly digest of a role to a channel",
+			DefaultMemberPermissions: &manageChannelsPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role of the digest",
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The channel of the digest (defaults to the current one)",
+					Required:     false,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				},
+			},
+		},
+		{
+			Name:        CommandListDigests,
+			Description: "List the weekly digests posted to the current channel",
+		},
+		{
+			Name:        CommandNotifications,
+			Description: "Show or change how you are notified about your tasks",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "method",
+					Description: "How to be notified (leave empty to show your preferences)",
+					Required:    false,
+					Choices:     b.notificationMethodChoices(),
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "event",
+					Description: "The event to change (defaults to all events)",
+					Required:    false,
+					Choices:     b.notificationEventChoices(),
+				},
+			},
+		},
+		{
+			Name:        CommandLanguage,
+			Description: "Show or change the language the bot answers you in",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "language",
+					Description: "The language of the messages (leave empty to show yours)",
+					Required:    false,
+					Choices:     b.languageChoices(),
+				},
+			},
+		},
+		{
+			Name:        CommandWorkload,
+			Description: "Show the open tasks of each member by status",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role to show the workload of (all roles if not given)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        CommandStats,
+			Description: "Show how many tasks were created and completed, and how fast",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role to show the stats of (all roles if not given)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "period",
+					Description: "The period to show the stats of (the last 30 days if not given)",
+					Required:    false,
+					Choices:     b.statsPeriodChoices(),
+				},
+			},
+		},
+		{
+			Name:        CommandExportTasks,
+			Description: "Export the tasks to a CSV or JSON file",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role to export the tasks of (all roles if not given)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "format",
+					Description: "The format of the file (CSV if not given)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "CSV", Value: taskFileFormatCSV},
+						{Name: "JSON", Value: taskFileFormatJSON},
+					},
+				},
+			},
+		},
+		{
+			Name:                     CommandImportTasks,
+			Description:              "Create the tasks of a CSV file, after previewing them",
+			DefaultMemberPermissions: &manageChannelsPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "file",
+					Description: "A CSV file with a title column, like the ones of /export-tasks",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     CommandCreateAPIToken,
+			Description:              "Create a token to read the tasks through the REST API",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "What the token is used by, e.g. grafana",
+					Required:    true,
+					MaxLength:   50,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "write",
+					Description: "Whether the token can also create and update tasks",
+				},
+			},
+		},
+		{
+			Name:                     CommandListAPITokens,
+			Description:              "List the tokens of the REST API",
+			DefaultMemberPermissions: &adminPermissions,
+		},
+		{
+			Name:                     CommandRevokeAPIToken,
+			Description:              "Revoke a token of the REST API",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "The name of the token",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     CommandAddTaskWebhook,
+			Description:              "Post the task events to an HTTP endpoint",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "url",
+					Description: "The URL the events are posted to",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:                     CommandListTaskWebhooks,
+			Description:              "List the endpoints the task events are posted to",
+			DefaultMemberPermissions: &adminPermissions,
+		},
+		{
+			Name:                     CommandRemoveTaskWebhook,
+			Description:              "Stop posting the task events to an endpoint",
+			DefaultMemberPermissions: &adminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the task webhook",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        CommandCalendarLink,
+			Description: "Get a calendar link to the due dates of your tasks or of a role",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role whose tasks are in the calendar, instead of yours",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:                     CommandQueueStatus,
+			Description:              "Show the delivery state of the outbound message queue",
+			DefaultMemberPermissions: &adminPermissions,
+		},
+		{
+			Name:                     CommandWebhookDeliveries,
+			Description:              "Show the recent GitHub webhook deliveries and their outcome",
+			DefaultMemberPermissions: &adminPermissions,
+		},
+		{
+			Name:        CommandLinkGitHub,
+			Description: "Start linking your GitHub account to your Discord account",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "username",
+					Description: "Your GitHub username",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        CommandVerifyGitHub,
+			Description: "Verify the code published on your GitHub account and complete the link",
+		},
+		{
+			Name:        CommandUnlinkGitHub,
+			Description: "Unlink your GitHub account from your Discord account",
+		},
+		{
+			Name:        CommandMyReviews,
+			Description: "Get the pull requests awaiting your review",
+		},
+		{
+			Name:        CommandTaskToIssue,
+			Description: "Open a GitHub issue from a task and keep their status in sync",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "task-id",
+					Description: "The ID of the task to open the issue from",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "repository",
+					Description: "The repository to open the issue in",
+					Required:    true,
+					Choices:     repoOptions,
+				},
+			},
+		},
+		{
+			Name:        CommandRepoInfo,
+			Description: "Get an overview of a repository",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "repository",
+					Description: "The repository to get information about",
+					Required:    true,
+					Choices:     repoOptions,
+				},
+			},
+		},
+		{
+			Name:        CommandRecentCommits,
+			Description: "Get the latest commits of a repository",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "repository",
+					Description: "The repository to get the commits of",
+					Required:    true,
+					Choices:     repoOptions,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "branch",
+					Description: "The branch to get the commits of (defaults to the default branch)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        CommandCIStatus,
+			Description: "Get the status of the latest CI runs on the default branch of a repository",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "repository",
+					Description: "The repository to get the CI status of",
+					Required:    true,
+					Choices:     repoOptions,
+				},
+			},
+		},
+	}
+
+	if err := defaultCommandCatalogs.localize(commands); err != nil {
+		return fmt.Errorf("failed to localize commands: %w", err)
+	}
+	if err := validateCommands(commands); err != nil {
+		return fmt.Errorf("invalid commands: %w", err)
 	}
 
 	errChan := make(chan error, len(commands))
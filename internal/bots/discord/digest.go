@@ -0,0 +1,287 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Digests are posted every Monday at 9:00, server time
+const (
+	digestWeekday       = time.Monday
+	digestHour          = 9
+	digestCheckInterval = 15 * time.Minute
+
+	// Maximum number of tasks listed in each digest section
+	digestSectionLimit = 10
+)
+
+// digestPeriodStart returns the most recent digest time at or before now
+func digestPeriodStart(now time.Time) time.Time {
+	daysSince := (int(now.Weekday()) - int(digestWeekday) + 7) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()-daysSince, digestHour, 0, 0, 0, now.Location())
+	if start.After(now) {
+		start = start.AddDate(0, 0, -7)
+	}
+	return start
+}
+
+// runDigestScheduler posts the weekly digests that are due, checking
+// periodically until the context is cancelled
+func (b *DiscordBot) runDigestScheduler(ctx context.Context) {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		b.postDueDigests(time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *DiscordBot) postDueDigests(now time.Time) {
+	periodStart := digestPeriodStart(now)
+
+	schedules, err := b.db.GetDigestSchedules()
+	if err != nil {
+		fmt.Printf("[digest] Failed to get digest schedules: %v\n", err)
+		return
+	}
+
+	for _, schedule := range schedules {
+		if schedule.LastPostedAt != nil && !schedule.LastPostedAt.Before(periodStart) {
+			continue
+		}
+
+		claimed, err := b.db.ClaimDigestRun(schedule.ID, periodStart)
+		if err != nil {
+			fmt.Printf("[digest] Failed to claim digest %d: %v\n", schedule.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		err = b.postDigest(schedule, periodStart.AddDate(0, 0, -7), periodStart)
+		if err != nil {
+			fmt.Printf("[digest] Failed to post digest for role %s in channel %s: %v\n", schedule.Role, schedule.ChannelID, err)
+			if err := b.db.ReleaseDigestRun(schedule.ID, schedule.LastPostedAt); err != nil {
+				fmt.Printf("[digest] Failed to release digest %d: %v\n", schedule.ID, err)
+			}
+			continue
+		}
+
+		fmt.Printf("[digest] Posted digest for role %s in channel %s\n", schedule.Role, schedule.ChannelID)
+	}
+}
+
+func (b *DiscordBot) postDigest(schedule db.DigestSchedule, since time.Time, until time.Time) error {
+	digest, err := b.db.GetRoleDigest(schedule.Role, since, until)
+	if err != nil {
+		return err
+	}
+
+	// Digests only reference members, they should not ping the whole department every week
+	_, err = b.session.ChannelMessageSendComplex(schedule.ChannelID, &discordgo.MessageSend{
+		Content: truncate(b.render(b.options.DefaultLocale, "digest.message", messageData{
+			"Digest":    digest,
+			"LastDay":   digest.Until.AddDate(0, 0, -1),
+			"Completed": digestSection(digest.Completed),
+			"Created":   digestSection(digest.Created),
+			"Overdue":   digestSection(digest.Overdue),
+		}), 2000),
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	return err
+}
+
+// digestSection returns the tasks listed in a section of a digest
+func digestSection(tasks []db.Task) messageData {
+	return messageData{
+		"Tasks": tasks[:min(len(tasks), digestSectionLimit)],
+		"Total": len(tasks),
+		"More":  max(0, len(tasks)-digestSectionLimit),
+	}
+}
+
+func formatAssignees(users []db.User) string {
+	mentions := make([]string, len(users))
+	for i, user := range users {
+		mentions[i] = fmt.Sprintf("<@%s>", user.DiscordID)
+	}
+	return strings.Join(mentions, ", ")
+}
+
+func (b *DiscordBot) addDigestCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandAddDigest {
+		return
+	}
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range i.ApplicationCommandData().Options {
+		optionMap[opt.Name] = opt
+	}
+
+	roleOption, ok := optionMap["role"]
+	if !ok {
+		fmt.Printf("[add-digest] Role option not found\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.invalid-role", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	role := resolvedRole(i, roleOption).Name
+	channelID := i.ChannelID
+	if channel, ok := optionMap["channel"]; ok {
+		channelID = channel.ChannelValue(nil).ID
+	}
+
+	// The first digest is the one of next week, not a catch-up of the current one
+	_, err := b.db.CreateDigestSchedule(channelID, role, digestPeriodStart(time.Now()))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "add-digest.already-configured", nil),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		fmt.Printf("[add-digest] Failed to create digest schedule: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "add-digest.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[add-digest] Channel %s will receive the digest for role %s\n", channelID, role)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: b.render(i.Locale, "add-digest.success", messageData{"Role": role, "ChannelID": channelID, "Hour": digestHour}),
+		},
+	})
+}
+
+func (b *DiscordBot) removeDigestCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandRemoveDigest {
+		return
+	}
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range i.ApplicationCommandData().Options {
+		optionMap[opt.Name] = opt
+	}
+
+	roleOption, ok := optionMap["role"]
+	if !ok {
+		fmt.Printf("[remove-digest] Role option not found\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.invalid-role", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	role := resolvedRole(i, roleOption).Name
+	channelID := i.ChannelID
+	if channel, ok := optionMap["channel"]; ok {
+		channelID = channel.ChannelValue(nil).ID
+	}
+
+	err := b.db.DeleteDigestSchedule(channelID, role)
+	if err != nil {
+		fmt.Printf("[remove-digest] Failed to delete digest schedule: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "remove-digest.not-found", messageData{"Role": role}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[remove-digest] Channel %s won't receive the digest for role %s anymore\n", channelID, role)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: b.render(i.Locale, "remove-digest.success", messageData{"Role": role, "ChannelID": channelID}),
+		},
+	})
+}
+
+func (b *DiscordBot) listDigestsCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandListDigests {
+		return
+	}
+
+	schedules, err := b.db.GetDigestSchedulesByChannel(i.ChannelID)
+	if err != nil {
+		fmt.Printf("[list-digests] Failed to get digest schedules: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "list-digests.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if len(schedules) == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "list-digests.none", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	respContent := b.render(i.Locale, "list-digests.list", messageData{"Schedules": schedules})
+
+	fmt.Printf("[list-digests] Retrieved %d digests for channel %s\n", len(schedules), i.ChannelID)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Formula-SAE/discord/internal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestCommands(t *testing.T) {
+	bot, session := newTestBot(t)
+	admin := testMember("1", "alice", 0)
+
+	bot.handleInteraction(session, commandInteraction(CommandListDigests, admin))
+	assert.Contains(t, session.content(t), "doesn't receive any weekly digest")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandAddDigest, admin, roleOption("role", testRole)))
+	assert.Contains(t, session.content(t), "Weekly digest configured")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandAddDigest, admin, roleOption("role", testRole)))
+	assert.Contains(t, session.content(t), "Digest already configured")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandAddDigest, admin, roleOption("role", testRole), channelOption("channel", "channel-2")))
+	assert.Contains(t, session.content(t), "<#channel-2>")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandListDigests, admin))
+	assert.Contains(t, session.content(t), "`Electronics`")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRemoveDigest, admin, roleOption("role", testRole)))
+	assert.Contains(t, session.content(t), "Weekly digest removed")
+
+	schedules, err := bot.db.GetDigestSchedules()
+	require.NoError(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, "channel-2", schedules[0].ChannelID)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRemoveDigest, admin, roleOption("role", testRole)))
+	assert.Contains(t, session.content(t), "Failed to remove digest")
+}
+
+func TestPostDigestLength(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	for i := 0; i < digestSectionLimit+2; i++ {
+		createTestTask(t, bot.db, strings.Repeat("Wire the BMS ", 20), testRole.Name, "")
+	}
+
+	schedule := db.DigestSchedule{Role: testRole.Name, ChannelID: "channel-1"}
+	require.NoError(t, bot.postDigest(schedule, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)))
+	require.Len(t, session.messages, 1)
+	assert.LessOrEqual(t, utf8.RuneCountInString(session.messages[0].Content), 2000)
+	assert.Contains(t, session.messages[0].Content, "…and 2 more")
+}
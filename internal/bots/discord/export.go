@@ -0,0 +1,222 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Formats of the exported task files
+const (
+	taskFileFormatCSV  = "csv"
+	taskFileFormatJSON = "json"
+)
+
+// taskFileColumns are the columns of the task CSV files, in order. Imports
+// read the same columns, so that an export can be edited and imported back.
+var taskFileColumns = []string{"id", "title", "description", "role", "status", "due_date", "assignees", "author", "created_at", "completed_at"}
+
+// assigneesSeparator separates the usernames in the assignees column
+const assigneesSeparator = ";"
+
+// taskRecord is a task as written to the exported files
+type taskRecord struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	DueDate     string     `json:"due_date,omitempty"`
+	Assignees   []string   `json:"assignees"`
+	Author      string     `json:"author"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func newTaskRecord(task db.Task) taskRecord {
+	record := taskRecord{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Role:        task.Role,
+		Status:      task.Status,
+		Assignees:   make([]string, 0, len(task.AssignedUsers)),
+		Author:      task.Author.Username,
+		CreatedAt:   task.CreatedAt.UTC(),
+	}
+	if task.DueDate != nil {
+		record.DueDate = task.DueDate.Format(db.DueDateFormat)
+	}
+	for _, user := range task.AssignedUsers {
+		record.Assignees = append(record.Assignees, user.Username)
+	}
+	if task.CompletedAt != nil {
+		completedAt := task.CompletedAt.UTC()
+		record.CompletedAt = &completedAt
+	}
+	return record
+}
+
+// csvRow returns the values of the record in the order of taskFileColumns
+func (r taskRecord) csvRow() []string {
+	completedAt := ""
+	if r.CompletedAt != nil {
+		completedAt = r.CompletedAt.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.FormatUint(uint64(r.ID), 10),
+		escapeCSVCell(r.Title),
+		escapeCSVCell(r.Description),
+		escapeCSVCell(r.Role),
+		r.Status,
+		r.DueDate,
+		strings.Join(r.Assignees, assigneesSeparator),
+		r.Author,
+		r.CreatedAt.Format(time.RFC3339),
+		completedAt,
+	}
+}
+
+// csvFormulaPrefixes are the first characters of the cells that spreadsheets
+// evaluate as formulas
+const csvFormulaPrefixes = "=+-@"
+
+// escapeCSVCell quotes with a leading ' a text that spreadsheets would
+// evaluate as a formula. Texts already quoted get one more quote, so that
+// unescapeCSVCell gives them back as they were.
+func escapeCSVCell(value string) string {
+	if isCSVFormula(value) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVCell removes the quote added by escapeCSVCell
+func unescapeCSVCell(value string) string {
+	if strings.HasPrefix(value, "'") && isCSVFormula(value[1:]) {
+		return value[1:]
+	}
+	return value
+}
+
+// isCSVFormula reports whether value, after its leading quotes, starts like
+// a formula
+func isCSVFormula(value string) bool {
+	value = strings.TrimLeft(value, "'")
+	return value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0]))
+}
+
+// writeTaskFile writes the tasks to w in a task file format
+func writeTaskFile(w io.Writer, format string, tasks []db.Task) error {
+	records := make([]taskRecord, 0, len(tasks))
+	for _, task := range tasks {
+		records = append(records, newTaskRecord(task))
+	}
+
+	switch format {
+	case taskFileFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(taskFileColumns); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := writer.Write(record.csvRow()); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case taskFileFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	default:
+		return fmt.Errorf("unknown task file format %q", format)
+	}
+}
+
+// taskFileName is the name of the file exporting the tasks of a role
+func taskFileName(role string, format string) string {
+	if role == "" {
+		return "tasks." + format
+	}
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(role))
+	return fmt.Sprintf("tasks-%s.%s", slug, format)
+}
+
+func (b *DiscordBot) exportTasksCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandExportTasks {
+		return
+	}
+
+	role := ""
+	format := taskFileFormatCSV
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "role":
+			role = resolvedRole(i, opt).Name
+		case "format":
+			format = opt.StringValue()
+		}
+	}
+
+	tasks, err := b.db.GetTasksForExport(role)
+	if err != nil {
+		fmt.Printf("[export-tasks] Failed to get tasks: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "export-tasks.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	file := &bytes.Buffer{}
+	if err := writeTaskFile(file, format, tasks); err != nil {
+		fmt.Printf("[export-tasks] Failed to write %s file: %v\n", format, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "export-tasks.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	contentType := "text/csv"
+	if format == taskFileFormatJSON {
+		contentType = "application/json"
+	}
+
+	fmt.Printf("[export-tasks] Exported %d tasks of role %q as %s\n", len(tasks), role, format)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: b.render(i.Locale, "export-tasks.success", messageData{"Role": role, "Count": len(tasks)}),
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Files: []*discordgo.File{
+				{Name: taskFileName(role, format), ContentType: contentType, Reader: file},
+			},
+		},
+	})
+}
@@ -0,0 +1,124 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportedFile returns the name and content of the file of the response
+func exportedFile(t *testing.T, session *fakeSession) (string, []byte) {
+	t.Helper()
+	resp := session.response(t)
+	require.Len(t, resp.Data.Files, 1)
+	content, err := io.ReadAll(resp.Data.Files[0].Reader)
+	require.NoError(t, err)
+	return resp.Data.Files[0].Name, content
+}
+
+func TestExportTasksCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	member := testMember("1", "alice", 0)
+
+	createTestUser(t, bot.db, "111", "author")
+	createTestUser(t, bot.db, "2", "bob")
+	wiring := createTestTask(t, bot.db, "Wire the BMS, then test it", testRole.Name, "2")
+	completed := createTestTask(t, bot.db, "Order connectors", testRole.Name, "")
+	_, err := bot.db.UpdateTaskStatus(completed.ID, db.TASK_COMPLETED)
+	require.NoError(t, err)
+	createTestTask(t, bot.db, "Paint the livery", "Design", "")
+
+	t.Run("csv", func(t *testing.T) {
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandExportTasks, member, roleOption("role", testRole)))
+
+		assert.Contains(t, session.content(t), "Exported 2 tasks")
+		name, content := exportedFile(t, session)
+		assert.Equal(t, "tasks-electronics.csv", name)
+
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, taskFileColumns, records[0])
+		assert.Equal(t, "Wire the BMS, then test it", records[1][1])
+		assert.Equal(t, db.TASK_NOT_STARTED, records[1][4])
+		assert.Equal(t, "bob", records[1][6])
+		assert.Equal(t, "author", records[1][7])
+		assert.Equal(t, db.TASK_COMPLETED, records[2][4])
+		assert.NotEmpty(t, records[2][9])
+	})
+
+	t.Run("json of all roles", func(t *testing.T) {
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandExportTasks, member, stringOption("format", taskFileFormatJSON)))
+
+		name, content := exportedFile(t, session)
+		assert.Equal(t, "tasks.json", name)
+
+		records := make([]taskRecord, 0)
+		require.NoError(t, json.Unmarshal(content, &records))
+		require.Len(t, records, 3)
+		assert.Equal(t, wiring.ID, records[0].ID)
+		assert.Equal(t, []string{"bob"}, records[0].Assignees)
+		assert.Nil(t, records[0].CompletedAt)
+		assert.Equal(t, "Design", records[2].Role)
+	})
+
+	t.Run("no tasks", func(t *testing.T) {
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandExportTasks, member, roleOption("role", &discordgo.Role{ID: "role-2", Name: "Aero"})))
+
+		// The header alone is a template for imports
+		_, content := exportedFile(t, session)
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+	})
+}
+
+func TestTaskFileFormulas(t *testing.T) {
+	tasks := []db.Task{{
+		Title:       "=HYPERLINK(\"https://example.com\")",
+		Description: "-5 bolts",
+		Role:        "@Electronics",
+		Status:      db.TASK_NOT_STARTED,
+		Author:      db.User{Username: "author"},
+	}, {
+		Title:  "'=already quoted",
+		Status: db.TASK_NOT_STARTED,
+		Author: db.User{Username: "author"},
+	}}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeTaskFile(buf, taskFileFormatCSV, tasks))
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "'=HYPERLINK(\"https://example.com\")", records[1][1])
+	assert.Equal(t, "'-5 bolts", records[1][2])
+	assert.Equal(t, "'@Electronics", records[1][3])
+	assert.Equal(t, "''=already quoted", records[2][1])
+
+	// Imports give back the original texts
+	rows, err := parseTaskFile(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, tasks[0].Title, rows[0].Values["title"])
+	assert.Equal(t, tasks[0].Description, rows[0].Values["description"])
+	assert.Equal(t, tasks[0].Role, rows[0].Values["role"])
+	assert.Equal(t, tasks[1].Title, rows[1].Values["title"])
+
+	// JSON files aren't opened by spreadsheets
+	buf.Reset()
+	require.NoError(t, writeTaskFile(buf, taskFileFormatJSON, tasks))
+	exported := make([]taskRecord, 0)
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	assert.Equal(t, tasks[0].Title, exported[0].Title)
+}
@@ -0,0 +1,297 @@
+package discord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-github/v74/github"
+)
+
+// A GitHub account is linked by publishing a verification code on it, either
+// in the profile bio or in the description or file name of a public gist
+const (
+	githubLinkCodeTTL    = 30 * time.Minute
+	githubLinkCodePrefix = "discord-verify-"
+	githubVerifyTimeout  = 10 * time.Second
+	githubGistsToInspect = 30
+)
+
+func newGitHubLinkCode() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return githubLinkCodePrefix + hex.EncodeToString(buf), nil
+}
+
+// isGitHubLinkCodePublished reports whether the verification code is
+// published on the GitHub account
+func (b *DiscordBot) isGitHubLinkCodePublished(ctx context.Context, login string, code string) (bool, error) {
+	user, _, err := b.gc.Users.Get(ctx, login)
+	if err != nil {
+		return false, fmt.Errorf("failed to get GitHub user: %w", err)
+	}
+	if strings.Contains(user.GetBio(), code) {
+		return true, nil
+	}
+
+	gists, _, err := b.gc.Gists.List(ctx, login, &github.GistListOptions{
+		ListOptions: github.ListOptions{PerPage: githubGistsToInspect},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list GitHub gists: %w", err)
+	}
+	for _, gist := range gists {
+		if !gist.GetPublic() {
+			continue
+		}
+		if strings.Contains(gist.GetDescription(), code) {
+			return true, nil
+		}
+		for filename := range gist.Files {
+			if strings.Contains(string(filename), code) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (b *DiscordBot) linkGitHubCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandLinkGitHub {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) != 1 || strings.TrimSpace(options[0].StringValue()) == "" {
+		fmt.Printf("[link-github] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "link-github.invalid-options", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	login := strings.TrimPrefix(strings.TrimSpace(options[0].StringValue()), "@")
+
+	code, err := newGitHubLinkCode()
+	if err == nil {
+		var user *db.User
+		user, err = b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+		if err == nil {
+			_, err = b.db.CreateGitHubLinkRequest(user.ID, login, code, time.Now().Add(githubLinkCodeTTL))
+		}
+	}
+	if err != nil {
+		fmt.Printf("[link-github] Failed to create link request: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "link-github.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[link-github] User %s is linking GitHub account %s\n", i.Member.User.Username, login)
+	respContent := b.render(i.Locale, "link-github.instructions", messageData{
+		"Login":   login,
+		"Code":    code,
+		"Minutes": int(githubLinkCodeTTL.Minutes()),
+	})
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (b *DiscordBot) verifyGitHubCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandVerifyGitHub {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[verify-github] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "verify-github.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	request, err := b.db.GetGitHubLinkRequest(user.ID, time.Now())
+	if err != nil {
+		fmt.Printf("[verify-github] No pending link request for user %s: %v\n", i.Member.User.Username, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "verify-github.nothing-to-verify", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Checking GitHub can take longer than the interaction response deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), githubVerifyTimeout)
+	defer cancel()
+
+	var respContent string
+	published, err := b.isGitHubLinkCodePublished(ctx, request.Login, request.Code)
+	switch {
+	case err != nil:
+		fmt.Printf("[verify-github] Failed to check GitHub account %s: %v\n", request.Login, err)
+		respContent = b.render(i.Locale, "verify-github.check-failed", messageData{"Login": request.Login})
+
+	case !published:
+		respContent = b.render(i.Locale, "verify-github.code-not-found", messageData{"Login": request.Login, "Code": request.Code})
+
+	default:
+		_, err = b.db.LinkGitHubLogin(user.ID, request.Login)
+		switch {
+		case errors.Is(err, db.ErrGitHubLoginTaken):
+			respContent = b.render(i.Locale, "verify-github.login-taken", messageData{"Login": request.Login})
+		case err != nil:
+			fmt.Printf("[verify-github] Failed to link GitHub account: %v\n", err)
+			respContent = b.render(i.Locale, "verify-github.link-failed", nil)
+		default:
+			fmt.Printf("[verify-github] User %s linked GitHub account %s\n", i.Member.User.Username, request.Login)
+			respContent = b.render(i.Locale, "verify-github.success", messageData{"Login": request.Login})
+		}
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &respContent,
+	})
+	if err != nil {
+		fmt.Printf("[verify-github] Failed to edit response: %v\n", err)
+	}
+}
+
+func (b *DiscordBot) unlinkGitHubCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandUnlinkGitHub {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err == nil {
+		err = b.db.UnlinkGitHubLogin(user.ID)
+	}
+	if err != nil {
+		fmt.Printf("[unlink-github] Failed to unlink GitHub account: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "unlink-github.not-linked", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[unlink-github] User %s unlinked their GitHub account\n", i.Member.User.Username)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: b.render(i.Locale, "unlink-github.success", nil),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// getPushDiscordIDs maps the GitHub logins of the pusher and the commit
+// authors of a push to the Discord IDs of the linked members
+func (b *DiscordBot) getPushDiscordIDs(payload *PushEvent) map[string]string {
+	logins := []string{payload.Pusher.Name}
+	for _, commit := range payload.Commits {
+		if commit.Author.Username != "" {
+			logins = append(logins, commit.Author.Username)
+		}
+	}
+
+	discordIDs, err := b.db.GetDiscordIDsByGitHubLogins(logins)
+	if err != nil {
+		fmt.Printf("[webhooks] Failed to resolve GitHub logins: %v\n", err)
+		return map[string]string{}
+	}
+	return discordIDs
+}
+
+// formatGitHubUser mentions the member linked to a GitHub login, falling back
+// to the given text for unknown logins
+func formatGitHubUser(login string, fallback string, discordIDs map[string]string) string {
+	if discordID, ok := discordIDs[strings.ToLower(login)]; ok && login != "" {
+		return fmt.Sprintf("<@%s>", discordID)
+	}
+	return fallback
+}
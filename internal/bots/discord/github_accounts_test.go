@@ -0,0 +1,65 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkGitHubCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+
+	bot.handleInteraction(session, commandInteraction(CommandLinkGitHub, testMember("1", "alice", 0), stringOption("username", "@octocat")))
+
+	content := session.content(t)
+	assert.Contains(t, content, "`octocat`")
+	assert.Contains(t, content, githubLinkCodePrefix)
+
+	user, err := bot.getOrCreateUser("1", "alice")
+	require.NoError(t, err)
+	request, err := bot.db.GetGitHubLinkRequest(user.ID, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", request.Login)
+	assert.Contains(t, content, request.Code)
+}
+
+func TestVerifyAndUnlinkGitHubCommands(t *testing.T) {
+	bot, session := newTestBot(t)
+	member := testMember("1", "alice", 0)
+	user := createTestUser(t, bot.db, "1", "alice")
+	_, err := bot.db.CreateGitHubLinkRequest(user.ID, "octocat", "discord-verify-0123456789ab", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	bio := "Racing"
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/octocat", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"login": "octocat", "bio": bio})
+	})
+	mux.HandleFunc("GET /users/octocat/gists", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{})
+	})
+	bot.gc = newTestGitHubClient(t, mux)
+
+	bot.handleInteraction(session, commandInteraction(CommandVerifyGitHub, member))
+	assert.Contains(t, session.content(t), "Code not found")
+
+	bio = "Racing discord-verify-0123456789ab"
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandVerifyGitHub, member))
+	assert.Contains(t, session.content(t), "GitHub account linked!")
+
+	linked, err := bot.db.GetUserByGitHubLogin("octocat")
+	require.NoError(t, err)
+	assert.Equal(t, "1", linked.DiscordID)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandUnlinkGitHub, member))
+	assert.Contains(t, session.content(t), "GitHub account unlinked!")
+
+	_, err = bot.db.GetUserByGitHubLogin("octocat")
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package discord
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// githubCache keeps GitHub API responses for a while, so that repeated
+// commands don't eat into the rate limit of the token
+type githubCache struct {
+	mu      sync.Mutex
+	entries map[string]githubCacheEntry
+}
+
+type githubCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+func newGitHubCache() *githubCache {
+	return &githubCache{entries: make(map[string]githubCacheEntry)}
+}
+
+func (c *githubCache) get(key string, now time.Time) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set caches value under key until now plus ttl. It drops the expired
+// entries, since the keys of some commands come from free text and would
+// otherwise pile up.
+func (c *githubCache) set(key string, value any, now time.Time, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = githubCacheEntry{value: value, expiresAt: now.Add(ttl)}
+}
+
+// cachedGitHubCall returns the cached result of fetch under key, calling it
+// on a miss. Errors are not cached.
+func cachedGitHubCall[T any](ctx context.Context, cache *githubCache, key string, ttl time.Duration, fetch func(context.Context) (T, error)) (T, error) {
+	if value, ok := cache.get(key, time.Now()); ok {
+		return value.(T), nil
+	}
+
+	value, err := fetch(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	cache.set(key, value, time.Now(), ttl)
+	return value, nil
+}
@@ -0,0 +1,63 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubCache(t *testing.T) {
+	cache := newGitHubCache()
+	now := time.Now()
+
+	cache.set("key", 42, now, time.Minute)
+
+	value, ok := cache.get("key", now)
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+
+	_, ok = cache.get("key", now.Add(time.Minute))
+	assert.False(t, ok)
+
+	_, ok = cache.get("missing", now)
+	assert.False(t, ok)
+
+	// Setting an entry drops the expired ones
+	cache.set("first", 1, now, time.Minute)
+	cache.set("second", 2, now, time.Hour)
+	cache.set("third", 3, now.Add(time.Minute), time.Minute)
+	assert.Len(t, cache.entries, 2)
+	_, ok = cache.entries["first"]
+	assert.False(t, ok)
+}
+
+func TestCachedGitHubCall(t *testing.T) {
+	cache := newGitHubCache()
+	calls := 0
+	fetch := func(ctx context.Context) (string, error) {
+		calls++
+		return "value", nil
+	}
+
+	for range 3 {
+		value, err := cachedGitHubCall(context.Background(), cache, "key", time.Minute, fetch)
+		assert.NoError(t, err)
+		assert.Equal(t, "value", value)
+	}
+	assert.Equal(t, 1, calls)
+
+	// Failed calls are retried
+	failures := 0
+	failing := func(ctx context.Context) (string, error) {
+		failures++
+		return "", errors.New("boom")
+	}
+	for range 2 {
+		_, err := cachedGitHubCall(context.Background(), cache, "other", time.Minute, failing)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, failures)
+}
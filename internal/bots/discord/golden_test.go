@@ -0,0 +1,566 @@
+package discord
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run "go test ./internal/bots/discord -update" to rewrite the golden files
+// after an intended change to the messages
+var updateGolden = flag.Bool("update", false, "update the golden files of the rendered messages")
+
+// Discord timestamps depend on when the test runs
+var discordTimestampRegexp = regexp.MustCompile(`<t:-?\d+:([A-Za-z])>`)
+
+// assertGolden compares a rendered output with testdata/golden/<name>.golden
+func assertGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+	actual = discordTimestampRegexp.ReplaceAllString(actual, "<t:TIMESTAMP:$1>")
+	path := filepath.Join("testdata", "golden", name+".golden")
+
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0o644))
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file, run the tests with -update to create it")
+	assert.Equal(t, string(expected), actual)
+}
+
+// transcript renders everything the bot sent through the fake session
+func (f *fakeSession) transcript() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	builder := &strings.Builder{}
+	for _, resp := range f.responses {
+		fmt.Fprintf(builder, "=== response (type %d)\n", resp.Type)
+		if resp.Data != nil {
+			writeResponseData(builder, resp.Data)
+		}
+	}
+	for _, edit := range f.edits {
+		builder.WriteString("=== edit\n")
+		if edit.Content != nil {
+			builder.WriteString(*edit.Content + "\n")
+		}
+		if edit.Components != nil {
+			writeComponents(builder, *edit.Components)
+		}
+	}
+	for _, message := range f.messages {
+		fmt.Fprintf(builder, "=== message to %s\n%s\n", message.ChannelID, message.Content)
+	}
+	return builder.String()
+}
+
+func writeResponseData(builder *strings.Builder, data *discordgo.InteractionResponseData) {
+	if data.Title != "" {
+		fmt.Fprintf(builder, "title: %s\n", data.Title)
+	}
+	if data.Content != "" {
+		builder.WriteString(data.Content + "\n")
+	}
+	for _, embed := range data.Embeds {
+		fmt.Fprintf(builder, "--- embed: %s\n", embed.Title)
+		if embed.Description != "" {
+			builder.WriteString(embed.Description + "\n")
+		}
+		for _, field := range embed.Fields {
+			fmt.Fprintf(builder, "[%s] %s\n", field.Name, field.Value)
+		}
+	}
+	writeComponents(builder, data.Components)
+	for _, file := range data.Files {
+		fmt.Fprintf(builder, "--- file: %s (%s)\n", file.Name, file.ContentType)
+	}
+}
+
+func writeComponents(builder *strings.Builder, components []discordgo.MessageComponent) {
+	for _, component := range components {
+		switch component := component.(type) {
+		case discordgo.ActionsRow:
+			writeComponents(builder, component.Components)
+		case *discordgo.ActionsRow:
+			writeComponents(builder, component.Components)
+		case discordgo.Button:
+			fmt.Fprintf(builder, "--- button: %s\n", component.Label)
+		case discordgo.SelectMenu:
+			fmt.Fprintf(builder, "--- select: %s\n", component.Placeholder)
+			for _, option := range component.Options {
+				fmt.Fprintf(builder, "  - %s (%s)\n", option.Label, option.Description)
+			}
+		case discordgo.TextInput:
+			fmt.Fprintf(builder, "--- input: %s (%s)\n", component.Label, component.Placeholder)
+		}
+	}
+}
+
+func TestGoldenCommands(t *testing.T) {
+	member := testMember("1", "alice", 0)
+	admin := testMember("9", "root", discordgo.PermissionAdministrator)
+	manager := testMember("8", "manager", discordgo.PermissionManageChannels)
+	assignee := &discordgo.User{ID: "2", Username: "bob"}
+	canPost := int64(discordgo.PermissionViewChannel | discordgo.PermissionSendMessages)
+
+	// withTasks creates the author and a task in each status for the test role
+	withTasks := func(t *testing.T, bot *DiscordBot) []*db.Task {
+		createTestUser(t, bot.db, "111", "author")
+		createTestUser(t, bot.db, "2", "bob")
+		tasks := []*db.Task{
+			createTestTask(t, bot.db, "Wire the BMS", testRole.Name, "2"),
+			createTestTask(t, bot.db, "Calibrate the IMU", testRole.Name, ""),
+			createTestTask(t, bot.db, "Order connectors", testRole.Name, "2"),
+		}
+		_, err := bot.db.UpdateTaskStatus(tasks[2].ID, db.TASK_COMPLETED)
+		require.NoError(t, err)
+		return tasks
+	}
+
+	for _, tt := range []struct {
+		name string
+		run  func(t *testing.T, bot *DiscordBot, session *fakeSession)
+	}{
+		{"create-task", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandCreateTask, member,
+				stringOption("title", "Wire the BMS"),
+				stringOption("description", "Use the new harness"),
+				userOption("assignee", assignee),
+				roleOption("role", testRole),
+				stringOption("due-date", "2026-11-02"),
+			))
+		}},
+		{"create-task-missing-title", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandCreateTask, member, stringOption("description", "No title")))
+		}},
+		{"assigned-tasks", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandAssignedTasks, testMember("2", "bob", 0)))
+		}},
+		{"assigned-tasks-none", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandAssignedTasks, member))
+		}},
+		{"get-tasks-by-role", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandGetTasksByRole, member, roleOption("role", testRole)))
+		}},
+		{"get-tasks-by-role-none", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandGetTasksByRole, member, roleOption("role", testRole)))
+		}},
+		{"unassigned-tasks-by-role", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandUnassignedTasksByRole, member, roleOption("role", testRole)))
+		}},
+		{"completed-tasks-by-role", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandCompletedTasksByRole, member, roleOption("role", testRole)))
+		}},
+		{"workload", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandWorkload, member, roleOption("role", testRole)))
+		}},
+		{"workload-none", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandWorkload, member))
+		}},
+		{"stats", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandStats, member, roleOption("role", testRole), stringOption("period", "week")))
+		}},
+		{"stats-none", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandStats, member))
+		}},
+		{"export-tasks", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandExportTasks, member, roleOption("role", testRole)))
+		}},
+		{"import-tasks", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			withTasks(t, bot)
+			session.roles = []*discordgo.Role{testRole}
+			attachment := serveTaskFile(t, "title,role,status,due_date,assignees\n"+
+				"Wire the BMS,Electronics,In Progress,2026-11-02,bob\n"+
+				"Calibrate the IMU,Electronics,,,\n")
+			bot.handleInteraction(session, commandInteraction(CommandImportTasks, manager, attachmentOption("file", attachment)))
+			buttons := importButtons(t, session)
+			require.Len(t, buttons, 2)
+			bot.handleInteraction(session, componentInteraction(buttons[0], manager))
+		}},
+		{"import-tasks-invalid-rows", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			session.roles = []*discordgo.Role{testRole}
+			attachment := serveTaskFile(t, "title,role,status,due_date,assignees\n"+
+				"Wire the BMS,Aero,Blocked,02/11/2026,dave\n"+
+				",Electronics,,,\n")
+			bot.handleInteraction(session, commandInteraction(CommandImportTasks, manager, attachmentOption("file", attachment)))
+		}},
+		{"assign-task", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			tasks := withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandAssignTask, member,
+				intOption("task-id", int(tasks[1].ID)),
+				userOption("user-id", assignee),
+			))
+		}},
+		{"update-task-status", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			tasks := withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandUpdateTaskStatus, member,
+				intOption("id", int(tasks[0].ID)),
+				stringOption("status", db.TASK_IN_PROGRESS),
+			))
+		}},
+		{"task-not-found", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandUpdateTaskStatus, member,
+				intOption("id", 42),
+				stringOption("status", db.TASK_IN_PROGRESS),
+			))
+		}},
+		{"delete-task", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.options.DeletedTaskRetention = 30 * 24 * time.Hour
+			tasks := withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandDeleteTask, member, intOption("id", int(tasks[0].ID))))
+			bot.handleInteraction(session, componentInteraction(fmt.Sprintf("%s:%d", ButtonConfirmDeleteTask, tasks[0].ID), member))
+		}},
+		{"deleted-tasks", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.options.DeletedTaskRetention = 30 * 24 * time.Hour
+			tasks := withTasks(t, bot)
+			require.NoError(t, bot.db.DeleteTask(tasks[1].ID))
+			bot.handleInteraction(session, commandInteraction(CommandDeletedTasks, admin))
+			bot.handleInteraction(session, commandInteraction(CommandRestoreTask, admin, intOption("id", int(tasks[1].ID))))
+		}},
+		{"edit-task", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			tasks := withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandEditTask, member, intOption("task-id", int(tasks[0].ID))))
+			bot.handleInteraction(session, modalInteraction(fmt.Sprintf("%s:%d", ModalEditTask, tasks[0].ID), member, map[string]string{
+				"title":       "Wire the new BMS",
+				"description": "Use the new harness",
+				"due-date":    "2026-11-02",
+			}))
+		}},
+		{"task-card", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			tasks := withTasks(t, bot)
+			author, err := bot.db.GetUserByDiscordID("2", nil)
+			require.NoError(t, err)
+			_, err = bot.db.AddTaskComment(tasks[0].ID, author.ID, "Harness ordered")
+			require.NoError(t, err)
+			bot.handleInteraction(session, componentInteraction(SelectOpenTaskCard, member, fmt.Sprintf("%d", tasks[0].ID)))
+			bot.handleInteraction(session, componentInteraction(taskCardCustomID(TaskCardActionComment, tasks[0].ID), member))
+		}},
+		{"claim-task", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			tasks := withTasks(t, bot)
+			bot.handleInteraction(session, commandInteraction(CommandClaimTask, member, intOption("id", int(tasks[1].ID))))
+			bot.handleInteraction(session, commandInteraction(CommandClaimTask, member, intOption("id", int(tasks[0].ID))))
+		}},
+		{"notifications", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.options.NotificationChannelID = "notifications"
+			bot.handleInteraction(session, commandInteraction(CommandNotifications, member,
+				stringOption("method", db.NOTIFICATION_METHOD_MENTION),
+				stringOption("event", db.NOTIFICATION_EVENT_COMMENT),
+			))
+		}},
+		{"subscriptions", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "king"}))
+			require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "telemetry"}))
+			session.permissions["channel-1"] = canPost
+			session.permissions["channel-2"] = canPost
+			session.setMemberPermissions("8", "channel-2", discordgo.PermissionManageChannels)
+			bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, manager, stringOption("repository", "king")))
+			bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, manager,
+				stringOption("repository", "telemetry"),
+				stringOption("branch", "release/*"),
+				channelOption("channel", "channel-2"),
+			))
+			bot.handleInteraction(session, commandInteraction(CommandGetSubscriptionsOfChannel, manager))
+			bot.handleInteraction(session, commandInteraction(CommandAllSubscriptions, admin))
+			bot.handleInteraction(session, commandInteraction(CommandUnsubscribeChannelFromPush, manager, stringOption("repository", "king")))
+		}},
+		{"subscribe-missing-permissions", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "king"}))
+			session.permissions["channel-1"] = discordgo.PermissionViewChannel
+			bot.handleInteraction(session, commandInteraction(CommandSubscribeChannelToPush, manager, stringOption("repository", "king")))
+		}},
+		{"digests", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandListDigests, admin))
+			bot.handleInteraction(session, commandInteraction(CommandAddDigest, admin, roleOption("role", testRole)))
+			bot.handleInteraction(session, commandInteraction(CommandListDigests, admin))
+			bot.handleInteraction(session, commandInteraction(CommandRemoveDigest, admin, roleOption("role", testRole)))
+		}},
+		{"github-accounts", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			bot.handleInteraction(session, commandInteraction(CommandVerifyGitHub, member))
+			bot.handleInteraction(session, commandInteraction(CommandUnlinkGitHub, member))
+			bot.handleInteraction(session, commandInteraction(CommandMyReviews, member))
+		}},
+		{"queue-status", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			_, err := bot.db.EnqueueOutboundMessage("channel-1", "Pushed 1 commit", nil)
+			require.NoError(t, err)
+			failed, err := bot.db.EnqueueOutboundMessage("channel-2", "Pushed 2 commits", nil)
+			require.NoError(t, err)
+			require.NoError(t, bot.db.FailOutboundMessage(failed.ID, "Missing Access"))
+			bot.handleInteraction(session, commandInteraction(CommandQueueStatus, admin))
+		}},
+		{"webhook-deliveries", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			delivery, _, err := bot.db.RecordWebhookDelivery("72d3162e-cc78-11e3-81ab-4c9367dc0958", "push", "king", []byte("{}"))
+			require.NoError(t, err)
+			require.NoError(t, bot.db.CompleteWebhookDelivery(delivery.ID, db.WEBHOOK_DELIVERY_FAILED, "invalid payload"))
+			bot.handleInteraction(session, commandInteraction(CommandWebhookDeliveries, admin))
+		}},
+		{"api-tokens", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			// The tokens created by the command are random, so this one is created directly
+			user := createTestUser(t, bot.db, "9", "root")
+			_, err := bot.db.CreateAPIToken("grafana", "king_0123456789abcdef", user.ID, false)
+			require.NoError(t, err)
+			bot.handleInteraction(session, commandInteraction(CommandCreateAPIToken, admin, stringOption("name", "grafana")))
+			bot.handleInteraction(session, commandInteraction(CommandListAPITokens, admin))
+			bot.handleInteraction(session, commandInteraction(CommandRevokeAPIToken, admin, stringOption("name", "grafana")))
+			bot.handleInteraction(session, commandInteraction(CommandRevokeAPIToken, admin, stringOption("name", "grafana")))
+			bot.handleInteraction(session, commandInteraction(CommandListAPITokens, member))
+		}},
+		{"task-webhooks", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			// The secrets created by the command are random, so this webhook is created directly
+			user := createTestUser(t, bot.db, "9", "root")
+			webhook, err := bot.db.CreateTaskWebhook("https://sheets.example.com/hook", "secret", user.ID)
+			require.NoError(t, err)
+			bot.handleInteraction(session, commandInteraction(CommandListTaskWebhooks, admin))
+			bot.handleInteraction(session, commandInteraction(CommandAddTaskWebhook, admin, stringOption("url", "https://sheets.example.com/hook")))
+			bot.handleInteraction(session, commandInteraction(CommandAddTaskWebhook, admin, stringOption("url", "sheets.example.com")))
+			bot.handleInteraction(session, commandInteraction(CommandRemoveTaskWebhook, admin, intOption("id", int(webhook.ID))))
+			bot.handleInteraction(session, commandInteraction(CommandRemoveTaskWebhook, admin, intOption("id", int(webhook.ID))))
+			bot.handleInteraction(session, commandInteraction(CommandListTaskWebhooks, admin))
+		}},
+		{"repository-commands", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/ApexCorse/king", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]any{
+					"full_name":      "ApexCorse/king",
+					"html_url":       "https://github.com/ApexCorse/king",
+					"description":    "Task tracker bot",
+					"default_branch": "main",
+				})
+			})
+			mux.HandleFunc("GET /repos/ApexCorse/king/pulls", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode([]map[string]any{{"number": 1}})
+			})
+			mux.HandleFunc("GET /repos/ApexCorse/king/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]any{"tag_name": "v1.2.0", "html_url": "https://github.com/ApexCorse/king/releases/v1.2.0"})
+			})
+			mux.HandleFunc("GET /repos/ApexCorse/king/commits", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode([]map[string]any{
+					{
+						"sha":      "0123456789abcdef",
+						"html_url": "https://github.com/ApexCorse/king/commit/0123456789abcdef",
+						"commit":   map[string]any{"message": "Fix the CAN parser\n\nDetails", "author": map[string]any{"name": "Alice"}},
+					},
+				})
+			})
+			mux.HandleFunc("GET /repos/ApexCorse/king/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(map[string]any{
+					"total_count": 2,
+					"workflow_runs": []map[string]any{
+						{"workflow_id": 1, "name": "Test", "status": "in_progress", "html_url": "https://github.com/ApexCorse/king/actions/runs/2"},
+						{"workflow_id": 2, "name": "Lint", "status": "completed", "conclusion": "failure", "html_url": "https://github.com/ApexCorse/king/actions/runs/1"},
+					},
+				})
+			})
+			mux.HandleFunc("GET /repos/ApexCorse/ghost", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+			})
+			bot.gc = newTestGitHubClient(t, mux)
+
+			for _, i := range []*discordgo.InteractionCreate{
+				commandInteraction(CommandRepoInfo, member, stringOption("repository", "king")),
+				commandInteraction(CommandRecentCommits, member, stringOption("repository", "king")),
+				commandInteraction(CommandCIStatus, member, stringOption("repository", "king")),
+				commandInteraction(CommandRepoInfo, member, stringOption("repository", "ghost")),
+			} {
+				bot.handleInteraction(session, i)
+			}
+		}},
+		{"italian", func(t *testing.T, bot *DiscordBot, session *fakeSession) {
+			tasks := withTasks(t, bot)
+			bob, err := bot.db.GetUserByDiscordID("2", nil)
+			require.NoError(t, err)
+			require.NoError(t, bot.db.SetUserLocale(bob.ID, "it"))
+
+			for _, i := range []*discordgo.InteractionCreate{
+				commandInteraction(CommandCreateTask, member,
+					stringOption("title", "Wire the BMS"),
+					userOption("assignee", assignee),
+					stringOption("due-date", "2026-11-02"),
+				),
+				commandInteraction(CommandGetTasksByRole, member, roleOption("role", testRole)),
+				commandInteraction(CommandUpdateTaskStatus, member,
+					intOption("id", int(tasks[0].ID)),
+					stringOption("status", db.TASK_IN_PROGRESS),
+				),
+				componentInteraction(SelectOpenTaskCard, member, fmt.Sprintf("%d", tasks[0].ID)),
+				commandInteraction(CommandNotifications, member),
+				commandInteraction(CommandLanguage, member),
+			} {
+				i.Locale = discordgo.Italian
+				bot.handleInteraction(session, i)
+			}
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, session := newTestBot(t)
+			tt.run(t, bot, session)
+			assertGolden(t, "command-"+tt.name, session.transcript())
+		})
+	}
+}
+
+func TestGoldenWebhookEvents(t *testing.T) {
+	pushEvent := func(modify func(payload *PushEvent)) *PushEvent {
+		payload := &PushEvent{Ref: "refs/heads/main"}
+		payload.Repository.Name = "king"
+		payload.Pusher = Author{Name: "alice-gh"}
+		payload.Commits = []struct {
+			ID        string `json:"id"`
+			Message   string `json:"message"`
+			Timestamp string `json:"timestamp"`
+			URL       string `json:"url"`
+			Author    Author `json:"author"`
+		}{
+			{ID: "a1", Message: "Fix the CAN parser\n\nThe length byte was off by one", URL: "https://github.com/ApexCorse/king/commit/a1", Author: Author{Name: "Alice", Username: "alice-gh"}},
+			{ID: "b2", Message: "Bump the firmware version", URL: "https://github.com/ApexCorse/king/commit/b2", Author: Author{Name: "Bob", Username: "bob-gh"}},
+		}
+		modify(payload)
+		return payload
+	}
+	discordIDs := map[string]string{"alice-gh": "1"}
+
+	for _, tt := range []struct {
+		name    string
+		payload *PushEvent
+	}{
+		{"push", pushEvent(func(payload *PushEvent) {})},
+		{"push-forced", pushEvent(func(payload *PushEvent) { payload.Forced = true })},
+		{"push-new-branch", pushEvent(func(payload *PushEvent) {
+			payload.Ref = "refs/heads/feature/can"
+			payload.Created = true
+		})},
+		{"branch-created", pushEvent(func(payload *PushEvent) {
+			payload.Ref = "refs/heads/feature/can"
+			payload.Created = true
+			payload.Commits = nil
+		})},
+		{"branch-deleted", pushEvent(func(payload *PushEvent) {
+			payload.Ref = "refs/heads/feature/can"
+			payload.Deleted = true
+			payload.Commits = nil
+		})},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &DiscordBot{catalogs: defaultCatalogs}
+			assertGolden(t, "webhook-"+tt.name, bot.formatPushEvent(tt.payload, discordIDs))
+		})
+	}
+
+	t.Run("issue-closed", func(t *testing.T) {
+		bot, session := newTestBot(t)
+		createTestUser(t, bot.db, "111", "author")
+		createTestUser(t, bot.db, "2", "bob")
+		task := createTestTask(t, bot.db, "Wire the BMS", "", "2")
+		_, err := bot.db.LinkTaskToIssue(task.ID, "king", 12, "https://github.com/ApexCorse/king/issues/12")
+		require.NoError(t, err)
+
+		status, _ := bot.processIssuesDelivery(db.WebhookDelivery{
+			Event:   "issues",
+			Payload: `{"action": "closed", "issue": {"number": 12, "title": "Wire the BMS", "html_url": "https://github.com/ApexCorse/king/issues/12"}, "repository": {"name": "king"}, "sender": {"login": "alice-gh"}}`,
+		})
+		require.Equal(t, db.WEBHOOK_DELIVERY_PROCESSED, status)
+		assertGolden(t, "webhook-issue-closed", session.transcript())
+	})
+
+	t.Run("review-reminder", func(t *testing.T) {
+		bot, session := newTestBot(t)
+		bot.notifyReviewPending(db.ReviewRequest{
+			Repository:  "king",
+			Number:      7,
+			Title:       "Add the CAN parser",
+			URL:         "https://github.com/ApexCorse/king/pull/7",
+			AuthorLogin: "alice-gh",
+		}, "2", 50*time.Hour)
+		assertGolden(t, "webhook-review-reminder", session.transcript())
+	})
+}
+
+func TestGoldenNotifications(t *testing.T) {
+	bot, session := newTestBot(t)
+	author := createTestUser(t, bot.db, "111", "author")
+	createTestUser(t, bot.db, "2", "bob")
+	created := createTestTask(t, bot.db, "Wire the BMS", "", "2")
+	task, err := bot.db.GetTaskByID(created.ID)
+	require.NoError(t, err)
+	task.Description = "Use the new harness"
+
+	comment := &db.TaskComment{Text: "Harness ordered"}
+	changes := []db.TaskChange{
+		{Field: db.TASK_FIELD_TITLE, OldValue: "Wire BMS", NewValue: "Wire the BMS"},
+		{Field: db.TASK_FIELD_DESCRIPTION, NewValue: "Use the new harness"},
+		{Field: db.TASK_FIELD_DUE_DATE, OldValue: "2026-11-01", NewValue: "2026-11-02"},
+	}
+
+	for _, tt := range []struct {
+		name   string
+		notify func()
+	}{
+		{"assigned", func() { bot.notifyTaskAssigned(task, "2") }},
+		{"status", func() { bot.notifyTaskStatusUpdate(task, "1") }},
+		{"edited", func() { bot.notifyTaskEdited(task, changes, "1") }},
+		{"comment", func() { bot.notifyTaskComment(task, comment, "2") }},
+		{"claimed", func() { bot.notifyTaskClaimed(task, "2") }},
+		{"deleted", func() { bot.notifyTaskDeleted(task, "1") }},
+		{"restored", func() { bot.notifyTaskRestored(task, "1") }},
+		{"closed-by-commit", func() {
+			bot.notifyTaskClosedByCommit(task, "[Wire the BMS](https://github.com/ApexCorse/king/commit/a1)", "<@1>")
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			session.reset()
+			tt.notify()
+			assertGolden(t, "notification-"+tt.name, session.transcript())
+		})
+	}
+
+	t.Run("digest", func(t *testing.T) {
+		dueDate := time.Date(2026, 10, 10, 0, 0, 0, 0, time.Local)
+		overdue := *task
+		overdue.DueDate = &dueDate
+
+		completed := make([]db.Task, digestSectionLimit+2)
+		for n := range completed {
+			completed[n] = db.Task{Title: fmt.Sprintf("Task %d", n+1), AssignedUsers: []db.User{*author}}
+			completed[n].ID = uint(n + 10)
+		}
+
+		session.reset()
+		digest := &db.RoleDigest{
+			Role:      testRole.Name,
+			Since:     time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local),
+			Until:     time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local),
+			Completed: completed,
+			Created:   []db.Task{*task},
+			Overdue:   []db.Task{overdue},
+			Workload:  []db.MemberWorkload{{DiscordID: "2", NotStarted: 1, InProgress: 2}},
+		}
+		message := bot.render("", "digest.message", messageData{
+			"Digest":    digest,
+			"LastDay":   digest.Until.AddDate(0, 0, -1),
+			"Completed": digestSection(digest.Completed),
+			"Created":   digestSection(digest.Created),
+			"Overdue":   digestSection(digest.Overdue),
+		})
+		assertGolden(t, "notification-digest", message)
+	})
+}
@@ -0,0 +1,539 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	maxImportFileSize     = 1 << 20
+	maxImportRows         = 500
+	importDownloadTimeout = 10 * time.Second
+
+	// importPreviewTTL is how long a previewed import can be confirmed, as
+	// long as the interaction token of the preview
+	importPreviewTTL = 15 * time.Minute
+
+	maxImportPreviewTasks = 10
+	maxImportRowErrors    = 15
+)
+
+// importedColumns are the columns of a task file read by an import. The
+// other columns of an export are ignored: imported tasks are new tasks,
+// authored by the member importing them.
+var importedColumns = []string{"title", "description", "role", "status", "due_date", "assignees"}
+
+// importFileError is a problem with a task file as a whole
+type importFileError struct {
+	// Problem is one of "empty", "malformed", "missing-title-column",
+	// "unknown-column", "duplicate-column" and "too-many-rows"
+	Problem string
+	Column  string
+	Line    int
+}
+
+func (e *importFileError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("%s: %s", e.Problem, e.Column)
+	}
+	return fmt.Sprintf("%s at line %d", e.Problem, e.Line)
+}
+
+// importRow is a row of a task file, keyed by column
+type importRow struct {
+	Line   int
+	Values map[string]string
+}
+
+// importRowError is a problem with a row of a task file
+type importRowError struct {
+	Line int
+	// Problem is one of "missing-title", "invalid-status",
+	// "invalid-due-date", "unknown-role" and "unknown-member"
+	Problem string
+	Value   string
+}
+
+// parseTaskFile reads the rows of a task CSV file. Its header names the
+// columns, in any order, and the rows without values are skipped. The
+// quotes that exports put before formulas are removed.
+func parseTaskFile(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &importFileError{Problem: "empty"}
+	}
+	if err != nil {
+		return nil, malformedTaskFile(err)
+	}
+
+	columns := make([]string, 0, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheets often start UTF-8 files with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if slices.Contains(columns, name) {
+			return nil, &importFileError{Problem: "duplicate-column", Column: name}
+		}
+		if !slices.Contains(taskFileColumns, name) {
+			return nil, &importFileError{Problem: "unknown-column", Column: name}
+		}
+		columns = append(columns, name)
+	}
+	if !slices.Contains(columns, "title") {
+		return nil, &importFileError{Problem: "missing-title-column"}
+	}
+
+	rows := make([]importRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, malformedTaskFile(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{Line: line, Values: make(map[string]string)}
+		for i, value := range record {
+			if value = unescapeCSVCell(strings.TrimSpace(value)); value != "" && slices.Contains(importedColumns, columns[i]) {
+				row.Values[columns[i]] = value
+			}
+		}
+		if len(row.Values) == 0 {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, &importFileError{Problem: "too-many-rows"}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, &importFileError{Problem: "empty"}
+	}
+
+	return rows, nil
+}
+
+func malformedTaskFile(err error) error {
+	parseErr := &csv.ParseError{}
+	if errors.As(err, &parseErr) {
+		return &importFileError{Problem: "malformed", Line: parseErr.Line}
+	}
+	return &importFileError{Problem: "malformed"}
+}
+
+// parseImportStatus returns the status named by value, ignoring its case
+func parseImportStatus(value string) (string, bool) {
+	if value == "" {
+		return db.TASK_NOT_STARTED, true
+	}
+	for _, status := range []string{db.TASK_NOT_STARTED, db.TASK_IN_PROGRESS, db.TASK_COMPLETED} {
+		if strings.EqualFold(value, status) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// resolveImportRows turns the rows of a task file into the tasks to create,
+// authored by authorID. Roles are matched by name with the roles of the
+// server, and assignees by username with the members who used the bot.
+func (b *DiscordBot) resolveImportRows(rows []importRow, roles []*discordgo.Role, authorID uint) ([]db.Task, []importRowError, error) {
+	roleNames := make(map[string]string, len(roles))
+	for _, role := range roles {
+		roleNames[strings.ToLower(role.Name)] = role.Name
+	}
+
+	usernames := make([]string, 0)
+	for _, row := range rows {
+		usernames = append(usernames, splitAssignees(row.Values["assignees"])...)
+	}
+	users, err := b.db.GetUsersByUsernames(usernames)
+	if err != nil {
+		return nil, nil, err
+	}
+	usersByName := make(map[string]db.User, len(users))
+	for _, user := range users {
+		usersByName[strings.ToLower(user.Username)] = user
+	}
+
+	tasks := make([]db.Task, 0, len(rows))
+	rowErrors := make([]importRowError, 0)
+	for _, row := range rows {
+		task := db.Task{
+			Title:       row.Values["title"],
+			Description: row.Values["description"],
+			AuthorID:    authorID,
+		}
+		if task.Title == "" {
+			rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "missing-title"})
+		}
+		switch taskTextTooLong(task.Title, task.Description) {
+		case "title":
+			rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "title-too-long", Value: strconv.Itoa(taskTitleMaxLength)})
+		case "description":
+			rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "description-too-long", Value: strconv.Itoa(taskDescriptionMaxLength)})
+		}
+
+		if value := row.Values["role"]; value != "" {
+			role, ok := roleNames[strings.ToLower(strings.TrimPrefix(value, "@"))]
+			if !ok {
+				rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "unknown-role", Value: value})
+			}
+			task.Role = role
+		}
+
+		status, ok := parseImportStatus(row.Values["status"])
+		if !ok {
+			rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "invalid-status", Value: row.Values["status"]})
+		}
+		task.Status = status
+
+		if value := row.Values["due_date"]; value != "" {
+			dueDate, err := parseDueDate(value)
+			if err != nil {
+				rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "invalid-due-date", Value: value})
+			}
+			task.DueDate = dueDate
+		}
+
+		for _, username := range splitAssignees(row.Values["assignees"]) {
+			user, ok := usersByName[strings.ToLower(username)]
+			if !ok {
+				rowErrors = append(rowErrors, importRowError{Line: row.Line, Problem: "unknown-member", Value: username})
+				continue
+			}
+			task.AssignedUsers = append(task.AssignedUsers, user)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rowErrors, nil
+}
+
+// splitAssignees returns the usernames of the assignees column
+func splitAssignees(value string) []string {
+	usernames := make([]string, 0)
+	for _, username := range strings.Split(value, assigneesSeparator) {
+		if username = strings.TrimPrefix(strings.TrimSpace(username), "@"); username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
+
+// downloadAttachment returns the content of an attachment, failing if it is
+// larger than maxImportFileSize
+func downloadAttachment(ctx context.Context, attachment *discordgo.MessageAttachment) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportFileSize {
+		return nil, fmt.Errorf("attachment larger than %d bytes", maxImportFileSize)
+	}
+	return data, nil
+}
+
+// pendingImport is a previewed import waiting for the confirmation of the
+// member who uploaded it
+type pendingImport struct {
+	discordID string
+	tasks     []db.Task
+	expiresAt time.Time
+}
+
+// pendingImports keeps the previewed imports in memory, so a restart of the
+// bot drops them and they have to be uploaded again
+type pendingImports struct {
+	mu      sync.Mutex
+	entries map[string]pendingImport
+}
+
+func newPendingImports() *pendingImports {
+	return &pendingImports{entries: make(map[string]pendingImport)}
+}
+
+func newPendingImportKey() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (p *pendingImports) add(key string, pending pendingImport, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, entry := range p.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(p.entries, key)
+		}
+	}
+	p.entries[key] = pending
+}
+
+// take removes and returns the import previewed by a member under key
+func (p *pendingImports) take(key string, discordID string, now time.Time) (pendingImport, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending, ok := p.entries[key]
+	if !ok || pending.discordID != discordID {
+		return pendingImport{}, false
+	}
+	delete(p.entries, key)
+	return pending, now.Before(pending.expiresAt)
+}
+
+func (b *DiscordBot) importTasksCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandImportTasks {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	var attachment *discordgo.MessageAttachment
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "file" {
+			attachment = resolvedAttachment(i, opt)
+		}
+	}
+	if attachment == nil || attachment.Size > maxImportFileSize {
+		fmt.Printf("[import-tasks] Missing or too large attachment\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "import-tasks.invalid-attachment", messageData{"MaxSizeKB": maxImportFileSize / 1024}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	fmt.Printf("[import-tasks] Command executed by user %s with file %s\n", i.Member.User.Username, attachment.Filename)
+
+	// Downloading the file can take longer than the interaction response deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	edit := b.previewImport(s, i, attachment)
+	if _, err := s.InteractionResponseEdit(i.Interaction, edit); err != nil {
+		fmt.Printf("[import-tasks] Failed to edit response: %v\n", err)
+	}
+}
+
+// previewImport validates an uploaded task file and returns the preview of
+// its import, with the buttons confirming it if the file is valid
+func (b *DiscordBot) previewImport(s Session, i *discordgo.InteractionCreate, attachment *discordgo.MessageAttachment) *discordgo.WebhookEdit {
+	contentEdit := func(content string) *discordgo.WebhookEdit {
+		return &discordgo.WebhookEdit{Content: &content}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), importDownloadTimeout)
+	defer cancel()
+
+	data, err := downloadAttachment(ctx, attachment)
+	if err != nil {
+		fmt.Printf("[import-tasks] Failed to download %s: %v\n", attachment.URL, err)
+		return contentEdit(b.render(i.Locale, "import-tasks.download-failed", nil))
+	}
+
+	rows, err := parseTaskFile(bytes.NewReader(data))
+	if err != nil {
+		fmt.Printf("[import-tasks] Invalid task file: %v\n", err)
+		fileErr := &importFileError{}
+		if !errors.As(err, &fileErr) {
+			return contentEdit(b.render(i.Locale, "import-tasks.failed", nil))
+		}
+		return contentEdit(b.render(i.Locale, "import-tasks.invalid-file", messageData{
+			"Problem":   fileErr.Problem,
+			"Column":    fileErr.Column,
+			"Line":      fileErr.Line,
+			"Columns":   strings.Join(importedColumns, ", "),
+			"MaxRows":   maxImportRows,
+			"Separator": assigneesSeparator,
+		}))
+	}
+
+	author, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[import-tasks] Failed to get or create user: %v\n", err)
+		return contentEdit(b.render(i.Locale, "import-tasks.failed", nil))
+	}
+
+	roles, err := s.GuildRoles(b.guildID)
+	if err != nil {
+		fmt.Printf("[import-tasks] Failed to get the server roles: %v\n", err)
+		return contentEdit(b.render(i.Locale, "import-tasks.failed", nil))
+	}
+
+	tasks, rowErrors, err := b.resolveImportRows(rows, roles, author.ID)
+	if err != nil {
+		fmt.Printf("[import-tasks] Failed to resolve rows: %v\n", err)
+		return contentEdit(b.render(i.Locale, "import-tasks.failed", nil))
+	}
+	if len(rowErrors) > 0 {
+		fmt.Printf("[import-tasks] %d invalid rows in %s\n", len(rowErrors), attachment.Filename)
+		return contentEdit(b.render(i.Locale, "import-tasks.invalid-rows", messageData{
+			"Errors": rowErrors[:min(len(rowErrors), maxImportRowErrors)],
+			"More":   max(len(rowErrors)-maxImportRowErrors, 0),
+		}))
+	}
+
+	key, err := newPendingImportKey()
+	if err != nil {
+		fmt.Printf("[import-tasks] Failed to generate import key: %v\n", err)
+		return contentEdit(b.render(i.Locale, "import-tasks.failed", nil))
+	}
+	b.imports.add(key, pendingImport{
+		discordID: i.Member.User.ID,
+		tasks:     tasks,
+		expiresAt: time.Now().Add(importPreviewTTL),
+	}, time.Now())
+
+	fmt.Printf("[import-tasks] Previewing the import of %d tasks from %s\n", len(tasks), attachment.Filename)
+	edit := contentEdit(b.render(i.Locale, "import-tasks.preview", messageData{
+		"Count": len(tasks),
+		"Tasks": tasks[:min(len(tasks), maxImportPreviewTasks)],
+		"More":  max(len(tasks)-maxImportPreviewTasks, 0),
+	}))
+	edit.Components = &[]discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    b.render(i.Locale, "import-tasks.confirm-button", messageData{"Count": len(tasks)}),
+					Style:    discordgo.SuccessButton,
+					CustomID: ButtonConfirmImport + ":" + key,
+				},
+				discordgo.Button{
+					Label:    b.render(i.Locale, "import-tasks.cancel-button", nil),
+					Style:    discordgo.SecondaryButton,
+					CustomID: ButtonCancelImport + ":" + key,
+				},
+			},
+		},
+	}
+	return edit
+}
+
+func (b *DiscordBot) importTasksConfirmationComponent(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+	key, confirmed := strings.CutPrefix(customID, ButtonConfirmImport+":")
+	if !confirmed {
+		var cancelled bool
+		key, cancelled = strings.CutPrefix(customID, ButtonCancelImport+":")
+		if !cancelled {
+			return
+		}
+	}
+
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Content:    content,
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		respond(b.render(i.Locale, "common.not-member", nil))
+		return
+	}
+
+	pending, ok := b.imports.take(key, i.Member.User.ID, time.Now())
+	if !ok {
+		fmt.Printf("[import-tasks] Import %s expired or unknown\n", key)
+		respond(b.render(i.Locale, "import-tasks.expired", nil))
+		return
+	}
+
+	if !confirmed {
+		fmt.Printf("[import-tasks] Import %s cancelled\n", key)
+		respond(b.render(i.Locale, "import-tasks.cancelled", nil))
+		return
+	}
+
+	// Imported tasks don't notify their assignees: a season plan would flood
+	// them with a notification per task
+	if err := b.db.ImportTasks(pending.tasks); err != nil {
+		fmt.Printf("[import-tasks] Failed to import tasks: %v\n", err)
+		respond(b.render(i.Locale, "import-tasks.failed", nil))
+		return
+	}
+
+	fmt.Printf("[import-tasks] Imported %d tasks\n", len(pending.tasks))
+	respond(b.render(i.Locale, "import-tasks.success", messageData{
+		"Count":   len(pending.tasks),
+		"FirstID": pending.tasks[0].ID,
+		"LastID":  pending.tasks[len(pending.tasks)-1].ID,
+	}))
+
+	// The task webhooks still get an event for every task
+	for _, imported := range pending.tasks {
+		task, err := b.db.GetTaskByID(imported.ID)
+		if err != nil {
+			fmt.Printf("[import-tasks] Failed to reload task %d: %v\n", imported.ID, err)
+			continue
+		}
+		b.emitTaskEvent(db.TASK_EVENT_CREATED, task)
+	}
+}
@@ -0,0 +1,208 @@
+package discord
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskFile(t *testing.T) {
+	t.Run("rows", func(t *testing.T) {
+		rows, err := parseTaskFile(strings.NewReader("\ufeffTitle,Due Date,author\nWiring,2026-11-02,alice\n,,\n\"Firmware, v2\",,bob\n"))
+		require.NoError(t, err)
+
+		require.Len(t, rows, 2)
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, map[string]string{"title": "Wiring", "due_date": "2026-11-02"}, rows[0].Values)
+		// Blank rows are skipped, and the columns that aren't imported ignored
+		assert.Equal(t, 4, rows[1].Line)
+		assert.Equal(t, map[string]string{"title": "Firmware, v2"}, rows[1].Values)
+	})
+
+	for _, tt := range []struct {
+		name     string
+		file     string
+		expected importFileError
+	}{
+		{"empty", "", importFileError{Problem: "empty"}},
+		{"header only", "title,role\n", importFileError{Problem: "empty"}},
+		{"malformed", "title,role\nWiring,Electronics\nFirmware\n", importFileError{Problem: "malformed", Line: 3}},
+		{"missing title column", "description,role\nWiring,Electronics\n", importFileError{Problem: "missing-title-column"}},
+		{"unknown column", "title,priority\nWiring,high\n", importFileError{Problem: "unknown-column", Column: "priority"}},
+		{"duplicate column", "title,Title\nWiring,Wiring\n", importFileError{Problem: "duplicate-column", Column: "title"}},
+		{"too many rows", "title\n" + strings.Repeat("Wiring\n", maxImportRows+1), importFileError{Problem: "too-many-rows"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTaskFile(strings.NewReader(tt.file))
+			fileErr := &importFileError{}
+			require.ErrorAs(t, err, &fileErr)
+			assert.Equal(t, tt.expected, *fileErr)
+		})
+	}
+}
+
+// serveTaskFile serves a task file as a Discord attachment
+func serveTaskFile(t *testing.T, content string) *discordgo.MessageAttachment {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+
+	return &discordgo.MessageAttachment{ID: "attachment-1", Filename: "plan.csv", URL: server.URL + "/plan.csv", Size: len(content)}
+}
+
+// importButtons returns the custom IDs of the buttons of the import preview
+func importButtons(t *testing.T, session *fakeSession) []string {
+	t.Helper()
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	require.Len(t, session.edits, 1)
+	if session.edits[0].Components == nil {
+		return nil
+	}
+	customIDs := make([]string, 0)
+	for _, component := range *session.edits[0].Components {
+		for _, button := range component.(discordgo.ActionsRow).Components {
+			customIDs = append(customIDs, button.(discordgo.Button).CustomID)
+		}
+	}
+	return customIDs
+}
+
+func TestImportTasksCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	session.roles = []*discordgo.Role{testRole, {ID: "role-2", Name: "Design"}}
+	lead := testMember("1", "alice", discordgo.PermissionManageChannels)
+	createTestUser(t, bot.db, "2", "bob")
+	createTestUser(t, bot.db, "3", "carol")
+
+	preview := func(t *testing.T, file string) []string {
+		t.Helper()
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandImportTasks, lead, attachmentOption("file", serveTaskFile(t, file))))
+		return importButtons(t, session)
+	}
+	countTasks := func(t *testing.T) int {
+		tasks, err := bot.db.GetTasksForExport("")
+		require.NoError(t, err)
+		return len(tasks)
+	}
+
+	t.Run("preview and confirm", func(t *testing.T) {
+		buttons := preview(t, "title,role,status,due_date,assignees\n"+
+			"Wire the BMS,electronics,in progress,2026-11-02,bob;@carol\n"+
+			"Paint the livery,Design,,,\n")
+
+		content := session.content(t)
+		assert.Contains(t, content, "Ready to import 2 tasks")
+		assert.Contains(t, content, "Wire the BMS — `Electronics` — due 2026-11-02 — <@2>, <@3>")
+		require.Len(t, buttons, 2)
+		// Nothing is created by the preview
+		assert.Zero(t, countTasks(t))
+
+		session.reset()
+		bot.handleInteraction(session, componentInteraction(buttons[0], lead))
+		assert.Contains(t, session.content(t), "Imported 2 tasks")
+
+		tasks, err := bot.db.GetTasksForExport("")
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, testRole.Name, tasks[0].Role)
+		assert.Equal(t, db.TASK_IN_PROGRESS, tasks[0].Status)
+		assert.Len(t, tasks[0].AssignedUsers, 2)
+		assert.Equal(t, "alice", tasks[0].Author.Username)
+		assert.Equal(t, db.TASK_NOT_STARTED, tasks[1].Status)
+
+		// A preview is imported once
+		session.reset()
+		bot.handleInteraction(session, componentInteraction(buttons[0], lead))
+		assert.Contains(t, session.content(t), "Import expired")
+		assert.Equal(t, 2, countTasks(t))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		before := countTasks(t)
+		buttons := preview(t, "title\nWire the BMS\n")
+		require.Len(t, buttons, 2)
+
+		session.reset()
+		bot.handleInteraction(session, componentInteraction(buttons[1], lead))
+		assert.Contains(t, session.content(t), "Import cancelled")
+
+		session.reset()
+		bot.handleInteraction(session, componentInteraction(buttons[0], lead))
+		assert.Contains(t, session.content(t), "Import expired")
+		assert.Equal(t, before, countTasks(t))
+	})
+
+	t.Run("only the uploader can confirm", func(t *testing.T) {
+		buttons := preview(t, "title\nWire the BMS\n")
+		require.Len(t, buttons, 2)
+
+		session.reset()
+		bot.handleInteraction(session, componentInteraction(buttons[0], testMember("2", "bob", 0)))
+		assert.Contains(t, session.content(t), "Import expired")
+	})
+
+	t.Run("expired preview", func(t *testing.T) {
+		buttons := preview(t, "title\nWire the BMS\n")
+		require.Len(t, buttons, 2)
+		key := strings.TrimPrefix(buttons[0], ButtonConfirmImport+":")
+
+		_, ok := bot.imports.take(key, lead.User.ID, time.Now().Add(importPreviewTTL))
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid rows", func(t *testing.T) {
+		before := countTasks(t)
+		buttons := preview(t, "title,role,status,due_date,assignees\n"+
+			"Wire the BMS,Aero,Blocked,02/11/2026,dave\n"+
+			",Design,,,\n"+
+			strings.Repeat("a", taskTitleMaxLength+1)+",,,,\n")
+
+		assert.Empty(t, buttons)
+		content := session.content(t)
+		assert.Contains(t, content, "Line 2: no role of the server is called `Aero`")
+		assert.Contains(t, content, "Line 2: unknown status `Blocked`")
+		assert.Contains(t, content, "Line 2: invalid due date `02/11/2026`")
+		assert.Contains(t, content, "Line 2: `dave` hasn't used the bot yet")
+		assert.Contains(t, content, "Line 3: the title is missing")
+		assert.Contains(t, content, "Line 4: the title is longer than 256 characters")
+		assert.Equal(t, before, countTasks(t))
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		buttons := preview(t, "name\nWire the BMS\n")
+		assert.Empty(t, buttons)
+		assert.Contains(t, session.content(t), "Unknown column `name`")
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		session.reset()
+		attachment := &discordgo.MessageAttachment{ID: "attachment-1", Filename: "plan.csv", Size: maxImportFileSize + 1}
+		bot.handleInteraction(session, commandInteraction(CommandImportTasks, lead, attachmentOption("file", attachment)))
+		assert.Contains(t, session.content(t), "Upload a CSV file of at most 1024 KB")
+	})
+
+	t.Run("export round trip", func(t *testing.T) {
+		before := countTasks(t)
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandExportTasks, lead, roleOption("role", testRole)))
+		_, exported := exportedFile(t, session)
+
+		buttons := preview(t, string(exported))
+		require.Len(t, buttons, 2)
+		assert.Contains(t, session.content(t), "Ready to import 1 tasks")
+		session.reset()
+		bot.handleInteraction(session, componentInteraction(buttons[0], lead))
+		assert.Equal(t, before+1, countTasks(t))
+	})
+}
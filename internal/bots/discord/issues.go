@@ -0,0 +1,230 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/Formula-SAE/discord/internal/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-github/v74/github"
+	"gorm.io/gorm"
+)
+
+const githubIssueTimeout = 10 * time.Second
+
+type IssuesEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+	} `json:"issue"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+	Sender GitHubUser `json:"sender"`
+}
+
+// createTaskIssue opens a GitHub issue with the title and description of the
+// task and links it to the task
+func (b *DiscordBot) createTaskIssue(ctx context.Context, task *db.Task, repository string) (*db.Task, error) {
+	body := b.render(b.options.DefaultLocale, "task-to-issue.issue-body", messageData{"Task": task})
+
+	issue, _, err := b.gc.Issues.Create(ctx, githubOrganization, repository, &github.IssueRequest{
+		Title: github.Ptr(task.Title),
+		Body:  github.Ptr(body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub issue: %w", err)
+	}
+
+	return b.db.LinkTaskToIssue(task.ID, repository, issue.GetNumber(), issue.GetHTMLURL())
+}
+
+// syncTaskIssue closes the issue linked to a completed task, and reopens it
+// when the task is not completed anymore
+func (b *DiscordBot) syncTaskIssue(ctx context.Context, task *db.Task) error {
+	if task.IssueNumber == 0 {
+		return nil
+	}
+
+	state := "open"
+	if task.Status == db.TASK_COMPLETED {
+		state = "closed"
+	}
+
+	_, _, err := b.gc.Issues.Edit(ctx, githubOrganization, task.IssueRepository, task.IssueNumber, &github.IssueRequest{
+		State: github.Ptr(state),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set state of issue %s#%d to %s: %w", task.IssueRepository, task.IssueNumber, state, err)
+	}
+
+	return nil
+}
+
+// onTaskStatusChanged propagates a status change made from Discord to the
+// linked GitHub issue
+func (b *DiscordBot) onTaskStatusChanged(task *db.Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), githubIssueTimeout)
+	defer cancel()
+
+	if err := b.syncTaskIssue(ctx, task); err != nil {
+		fmt.Printf("[issues] Failed to sync issue of task %d: %v\n", task.ID, err)
+	}
+}
+
+// applyIssueState updates the status of the task linked to an issue that
+// has been closed or reopened on GitHub. It returns whether the status changed.
+func (b *DiscordBot) applyIssueState(repository string, number int, action string) (*db.Task, bool, error) {
+	task, err := b.db.GetTaskByIssue(repository, number)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var status string
+	switch action {
+	case "closed":
+		if task.Status == db.TASK_COMPLETED {
+			return task, false, nil
+		}
+		status = db.TASK_COMPLETED
+	case "reopened":
+		if task.Status != db.TASK_COMPLETED {
+			return task, false, nil
+		}
+		status = db.TASK_IN_PROGRESS
+	default:
+		return task, false, nil
+	}
+
+	task, err = b.db.UpdateTaskStatus(task.ID, status)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return task, true, nil
+}
+
+// processIssuesDelivery updates the tasks linked to the issues closed or
+// reopened on GitHub. The change is not pushed back to the issue.
+func (b *DiscordBot) processIssuesDelivery(delivery db.WebhookDelivery) (string, string) {
+	payload := &IssuesEvent{}
+	if err := json.Unmarshal([]byte(delivery.Payload), payload); err != nil {
+		return db.WEBHOOK_DELIVERY_FAILED, fmt.Sprintf("invalid payload: %v", err)
+	}
+
+	if payload.Action != "closed" && payload.Action != "reopened" {
+		return db.WEBHOOK_DELIVERY_IGNORED, fmt.Sprintf("unsupported action %s", payload.Action)
+	}
+
+	task, changed, err := b.applyIssueState(payload.Repository.Name, payload.Issue.Number, payload.Action)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return db.WEBHOOK_DELIVERY_IGNORED, fmt.Sprintf("issue #%d is not linked to a task", payload.Issue.Number)
+	}
+	if err != nil {
+		return db.WEBHOOK_DELIVERY_FAILED, fmt.Sprintf("failed to update task: %v", err)
+	}
+	if !changed {
+		return db.WEBHOOK_DELIVERY_PROCESSED, fmt.Sprintf("task %d already %s", task.ID, task.Status)
+	}
+
+	discordIDs, err := b.db.GetDiscordIDsByGitHubLogins([]string{payload.Sender.Login})
+	if err != nil {
+		fmt.Printf("[issues] Failed to resolve GitHub login %s: %v\n", payload.Sender.Login, err)
+	}
+	sender := formatGitHubUser(payload.Sender.Login, utils.EscapedInlineCode(payload.Sender.Login), discordIDs)
+	b.notifyTaskIssueStateChanged(task, b.render(b.options.DefaultLocale, "issue.link", messageData{"Number": payload.Issue.Number, "Title": payload.Issue.Title, "URL": payload.Issue.HTMLURL}), payload.Action, sender)
+	b.emitTaskEvent(db.TASK_EVENT_STATUS_CHANGED, task)
+
+	return db.WEBHOOK_DELIVERY_PROCESSED, fmt.Sprintf("task %d set to %s", task.ID, task.Status)
+}
+
+func (b *DiscordBot) taskToIssueCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandTaskToIssue {
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) != 2 {
+		fmt.Printf("[task-to-issue] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-to-issue.invalid-options", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	taskID := options[0].IntValue()
+	repository := options[1].StringValue()
+
+	task, err := b.db.GetTaskByID(uint(taskID))
+	if err != nil {
+		fmt.Printf("[task-to-issue] Failed to get task %d: %v\n", taskID, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-card.not-found", messageData{"TaskID": taskID}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if task.IssueNumber != 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "task-to-issue.already-linked", messageData{"Task": task}),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// Opening the issue can take longer than the interaction response deadline
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), githubIssueTimeout)
+	defer cancel()
+
+	var respContent string
+	task, err = b.createTaskIssue(ctx, task, repository)
+	if err != nil {
+		fmt.Printf("[task-to-issue] Failed to create issue for task %d: %v\n", taskID, err)
+		respContent = b.render(i.Locale, "task-to-issue.failed", messageData{"Repository": repository})
+	} else {
+		fmt.Printf("[task-to-issue] Task %d linked to issue %s#%d\n", task.ID, task.IssueRepository, task.IssueNumber)
+		respContent = b.render(i.Locale, "task-to-issue.success", messageData{"Task": task})
+
+		// The issue starts closed if the task is already completed
+		if task.Status == db.TASK_COMPLETED {
+			if err := b.syncTaskIssue(ctx, task); err != nil {
+				fmt.Printf("[task-to-issue] Failed to close issue of task %d: %v\n", task.ID, err)
+			}
+		}
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &respContent,
+	})
+	if err != nil {
+		fmt.Printf("[task-to-issue] Failed to edit response: %v\n", err)
+	}
+}
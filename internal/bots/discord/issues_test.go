@@ -0,0 +1,211 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/google/go-github/v74/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGitHubClient returns a GitHub client whose requests are served by handler
+func newTestGitHubClient(t *testing.T, handler http.Handler) *github.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	return client
+}
+
+func TestCreateTaskIssue(t *testing.T) {
+	database := db.NewDB(db.CreateTestDB())
+	author := createTestUser(t, database, "111", "author")
+	task := createTestTask(t, database, "Fix the telemetry", "", "")
+	description := "CAN frames are dropped"
+	task, _, err := database.UpdateTask(task.ID, author.ID, &db.TaskUpdateOptions{Description: &description})
+	require.NoError(t, err)
+
+	var received github.IssueRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/ApexCorse/king/issues", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"number":   12,
+			"html_url": "https://github.com/ApexCorse/king/issues/12",
+		})
+	})
+	bot := &DiscordBot{db: database, gc: newTestGitHubClient(t, mux)}
+
+	linked, err := bot.createTaskIssue(context.Background(), task, "king")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Fix the telemetry", received.GetTitle())
+	assert.Contains(t, received.GetBody(), "CAN frames are dropped")
+	assert.Equal(t, "king", linked.IssueRepository)
+	assert.Equal(t, 12, linked.IssueNumber)
+	assert.Equal(t, "https://github.com/ApexCorse/king/issues/12", linked.IssueURL)
+
+	found, err := database.GetTaskByIssue("king", 12)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, found.ID)
+}
+
+func TestCreateTaskIssueFailure(t *testing.T) {
+	database := db.NewDB(db.CreateTestDB())
+	createTestUser(t, database, "111", "author")
+	task := createTestTask(t, database, "Fix the telemetry", "", "")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/ApexCorse/king/issues", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Issues are disabled for this repo"}`, http.StatusGone)
+	})
+	bot := &DiscordBot{db: database, gc: newTestGitHubClient(t, mux)}
+
+	_, err := bot.createTaskIssue(context.Background(), task, "king")
+	assert.Error(t, err)
+
+	found, err := database.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Zero(t, found.IssueNumber)
+}
+
+func TestSyncTaskIssue(t *testing.T) {
+	tests := []struct {
+		status string
+		state  string
+	}{
+		{db.TASK_COMPLETED, "closed"},
+		{db.TASK_IN_PROGRESS, "open"},
+		{db.TASK_NOT_STARTED, "open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			database := db.NewDB(db.CreateTestDB())
+			createTestUser(t, database, "111", "author")
+			task := createTestTask(t, database, "Fix the telemetry", "", "")
+			_, err := database.UpdateTaskStatus(task.ID, tt.status)
+			require.NoError(t, err)
+			task, err = database.LinkTaskToIssue(task.ID, "king", 12, "")
+			require.NoError(t, err)
+
+			var received github.IssueRequest
+			mux := http.NewServeMux()
+			mux.HandleFunc("PATCH /repos/ApexCorse/king/issues/12", func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				json.NewEncoder(w).Encode(map[string]any{"number": 12, "state": received.GetState()})
+			})
+			bot := &DiscordBot{db: database, gc: newTestGitHubClient(t, mux)}
+
+			assert.NoError(t, bot.syncTaskIssue(context.Background(), task))
+			assert.Equal(t, tt.state, received.GetState())
+		})
+	}
+
+	t.Run("tasks without issue are not synced", func(t *testing.T) {
+		database := db.NewDB(db.CreateTestDB())
+		createTestUser(t, database, "111", "author")
+		task := createTestTask(t, database, "Fix the telemetry", "", "")
+		task, err := database.UpdateTaskStatus(task.ID, db.TASK_COMPLETED)
+		require.NoError(t, err)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		})
+		bot := &DiscordBot{db: database, gc: newTestGitHubClient(t, mux)}
+
+		assert.NoError(t, bot.syncTaskIssue(context.Background(), task))
+	})
+}
+
+func TestApplyIssueState(t *testing.T) {
+	database := db.NewDB(db.CreateTestDB())
+	createTestUser(t, database, "111", "author")
+	task := createTestTask(t, database, "Fix the telemetry", "", "")
+	_, err := database.UpdateTaskStatus(task.ID, db.TASK_IN_PROGRESS)
+	require.NoError(t, err)
+	_, err = database.LinkTaskToIssue(task.ID, "king", 12, "")
+	require.NoError(t, err)
+	bot := &DiscordBot{db: database}
+
+	updated, changed, err := bot.applyIssueState("king", 12, "closed")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, db.TASK_COMPLETED, updated.Status)
+
+	// A redelivered event doesn't change the task again
+	_, changed, err = bot.applyIssueState("king", 12, "closed")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	updated, changed, err = bot.applyIssueState("king", 12, "reopened")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, db.TASK_IN_PROGRESS, updated.Status)
+
+	_, _, err = bot.applyIssueState("king", 13, "closed")
+	assert.Error(t, err)
+}
+
+func TestProcessIssuesDeliveryIgnored(t *testing.T) {
+	bot := &DiscordBot{db: db.NewDB(db.CreateTestDB())}
+
+	status, _ := bot.processIssuesDelivery(db.WebhookDelivery{
+		Event:   "issues",
+		Payload: `{"action": "labeled", "issue": {"number": 12}, "repository": {"name": "king"}}`,
+	})
+	assert.Equal(t, db.WEBHOOK_DELIVERY_IGNORED, status)
+
+	status, outcome := bot.processIssuesDelivery(db.WebhookDelivery{
+		Event:   "issues",
+		Payload: `{"action": "closed", "issue": {"number": 12}, "repository": {"name": "king"}}`,
+	})
+	assert.Equal(t, db.WEBHOOK_DELIVERY_IGNORED, status)
+	assert.Contains(t, outcome, "not linked")
+
+	status, _ = bot.processIssuesDelivery(db.WebhookDelivery{Event: "issues", Payload: "{"})
+	assert.Equal(t, db.WEBHOOK_DELIVERY_FAILED, status)
+}
+
+func TestTaskToIssueCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	task := createTestTask(t, bot.db, "Fix the telemetry", "", "")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/ApexCorse/king/issues", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"number":   12,
+			"html_url": "https://github.com/ApexCorse/king/issues/12",
+		})
+	})
+	bot.gc = newTestGitHubClient(t, mux)
+
+	bot.handleInteraction(session, commandInteraction(CommandTaskToIssue, testMember("1", "alice", 0),
+		intOption("task-id", int(task.ID)),
+		stringOption("repository", "king"),
+	))
+	assert.Contains(t, session.content(t), "Issue created successfully!")
+
+	// A task is linked to a single issue
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandTaskToIssue, testMember("1", "alice", 0),
+		intOption("task-id", int(task.ID)),
+		stringOption("repository", "king"),
+	))
+	assert.Contains(t, session.content(t), "king#12")
+	assert.Contains(t, session.content(t), "Issue already exists")
+}
@@ -0,0 +1,153 @@
+package discord
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// languageAuto follows the language of the Discord client of the member
+const languageAuto = "auto"
+
+// languageChoices are the languages of the language command, each named in
+// its own language
+func (b *DiscordBot) languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{
+		b.choice(languageAuto, "language.auto", nil),
+	}
+	for _, language := range b.languages() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  b.render(discordgo.Locale(language), "language.name", nil),
+			Value: language,
+		})
+	}
+	return choices
+}
+
+// languages returns the languages of the message catalogs, sorted
+func (b *DiscordBot) languages() []string {
+	c := b.messageCatalogs()
+	languages := make([]string, 0, len(c))
+	for language := range c {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// applyUserLocale replaces the locale of the Discord client in an interaction
+// with the one the member chose, so that the handlers answer in it. The
+// language command keeps the locale of the client, which it falls back to.
+func (b *DiscordBot) applyUserLocale(i *discordgo.InteractionCreate) {
+	if i.Type == discordgo.InteractionApplicationCommand && i.ApplicationCommandData().Name == CommandLanguage {
+		return
+	}
+
+	var user *discordgo.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	} else if i.User != nil {
+		user = i.User
+	}
+	if user == nil {
+		return
+	}
+
+	if locale := b.userLocale(user.ID); locale != "" {
+		i.Locale = locale
+	}
+}
+
+func (b *DiscordBot) languageCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandLanguage {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[language] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "language.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// The member's language, which applyUserLocale didn't apply
+	current := discordgo.Locale(cmp.Or(user.Locale, string(i.Locale)))
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(current, "language.current", messageData{
+					"Chosen":  user.Locale != "",
+					"Updated": false,
+				}),
+				Flags: discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	locale := options[0].StringValue()
+	if locale == languageAuto {
+		locale = ""
+	} else if !slices.Contains(b.languages(), locale) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(current, "language.invalid", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if err := b.db.SetUserLocale(user.ID, locale); err != nil {
+		fmt.Printf("[language] Failed to set locale: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(current, "language.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[language] User %s set their language to %q\n", i.Member.User.Username, locale)
+
+	// The answer is already in the new language
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: b.render(discordgo.Locale(cmp.Or(locale, string(i.Locale))), "language.current", messageData{
+				"Chosen":  locale != "",
+				"Updated": true,
+			}),
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,311 @@
+package discord
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The names and descriptions of the commands and their options are
+// translated in localizations/<locale>.json, named after the Discord locale
+// they apply to. Discord shows them to the members using that locale, and
+// keeps sending the English names in the interactions. The names of the
+// choices are messages, rendered from the message catalogs.
+
+//go:embed localizations/*.json
+var commandCatalogFiles embed.FS
+
+// commandLocalization is the translation of a command or of an option
+type commandLocalization struct {
+	Name        string                         `json:"name"`
+	Description string                         `json:"description"`
+	Options     map[string]commandLocalization `json:"options,omitempty"`
+}
+
+// commandCatalogs are the translations of the commands, keyed by locale and
+// English command name
+type commandCatalogs map[discordgo.Locale]map[string]commandLocalization
+
+var defaultCommandCatalogs = mustLoadCommandCatalogs()
+
+func mustLoadCommandCatalogs() commandCatalogs {
+	files, err := commandCatalogFiles.ReadDir("localizations")
+	if err != nil {
+		panic(err)
+	}
+
+	c := make(commandCatalogs, len(files))
+	for _, file := range files {
+		text, err := commandCatalogFiles.ReadFile("localizations/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		commands := make(map[string]commandLocalization)
+		if err := decoder.Decode(&commands); err != nil {
+			panic(fmt.Errorf("failed to parse %s: %w", file.Name(), err))
+		}
+		c[discordgo.Locale(strings.TrimSuffix(file.Name(), ".json"))] = commands
+	}
+	return c
+}
+
+// locales returns the locales with translated commands, sorted
+func (c commandCatalogs) locales() []discordgo.Locale {
+	locales := make([]discordgo.Locale, 0, len(c))
+	for locale := range c {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// localize sets the localized names and descriptions of the commands and of
+// their options. Translations of commands or options that don't exist are an
+// error, as they are usually left behind by a rename.
+func (c commandCatalogs) localize(commands []*discordgo.ApplicationCommand) error {
+	for _, locale := range c.locales() {
+		if _, ok := discordgo.Locales[locale]; !ok {
+			return fmt.Errorf("unknown Discord locale %q", string(locale))
+		}
+
+		// A copy, to tick off the translations that are used
+		translations := maps.Clone(c[locale])
+		for _, command := range commands {
+			translation, ok := translations[command.Name]
+			if !ok {
+				continue
+			}
+			delete(translations, command.Name)
+
+			if translation.Name != "" {
+				setLocalization(&command.NameLocalizations, locale, translation.Name)
+			}
+			if translation.Description != "" {
+				setLocalization(&command.DescriptionLocalizations, locale, translation.Description)
+			}
+			if err := localizeOptions(command.Options, locale, translation.Options); err != nil {
+				return fmt.Errorf("%s /%s: %w", string(locale), command.Name, err)
+			}
+		}
+
+		for name := range translations {
+			return fmt.Errorf("%s: unknown command %q", string(locale), name)
+		}
+	}
+	return nil
+}
+
+func localizeOptions(options []*discordgo.ApplicationCommandOption, locale discordgo.Locale, translations map[string]commandLocalization) error {
+	translations = maps.Clone(translations)
+	for _, option := range options {
+		translation, ok := translations[option.Name]
+		if !ok {
+			continue
+		}
+		delete(translations, option.Name)
+
+		if translation.Name != "" {
+			if option.NameLocalizations == nil {
+				option.NameLocalizations = make(map[discordgo.Locale]string)
+			}
+			option.NameLocalizations[locale] = translation.Name
+		}
+		if translation.Description != "" {
+			if option.DescriptionLocalizations == nil {
+				option.DescriptionLocalizations = make(map[discordgo.Locale]string)
+			}
+			option.DescriptionLocalizations[locale] = translation.Description
+		}
+		if err := localizeOptions(option.Options, locale, translation.Options); err != nil {
+			return fmt.Errorf("option %s: %w", option.Name, err)
+		}
+	}
+
+	for name := range translations {
+		return fmt.Errorf("unknown option %q", name)
+	}
+	return nil
+}
+
+func setLocalization(localizations **map[discordgo.Locale]string, locale discordgo.Locale, text string) {
+	if *localizations == nil {
+		*localizations = &map[discordgo.Locale]string{}
+	}
+	(**localizations)[locale] = text
+}
+
+// choice returns a choice named by a message, with its translations in the
+// locales of the commands
+func (b *DiscordBot) choice(value string, message string, data messageData) *discordgo.ApplicationCommandOptionChoice {
+	choice := &discordgo.ApplicationCommandOptionChoice{
+		Name:  b.render(discordgo.EnglishUS, message, data),
+		Value: value,
+	}
+
+	for _, locale := range defaultCommandCatalogs.locales() {
+		if language, ok := b.messageCatalogs().language(locale); !ok || language == defaultLanguage {
+			continue
+		}
+		if choice.NameLocalizations == nil {
+			choice.NameLocalizations = make(map[discordgo.Locale]string)
+		}
+		choice.NameLocalizations[locale] = b.render(locale, message, data)
+	}
+	return choice
+}
+
+// commandNameRegexp are the names Discord accepts for the slash commands and
+// their options, in any locale
+var commandNameRegexp = regexp.MustCompile(`^[-_\p{L}\p{N}\p{Devanagari}\p{Thai}]{1,32}$`)
+
+// validateCommands checks the names and descriptions of the commands, in
+// every locale, against the rules of Discord, which rejects the whole command
+// otherwise
+func validateCommands(commands []*discordgo.ApplicationCommand) error {
+	errs := make([]error, 0)
+	names := make([]localizedName, 0, len(commands))
+	for _, command := range commands {
+		path := "/" + command.Name
+		name := localizedName{path: path, name: command.Name}
+		if command.NameLocalizations != nil {
+			name.localizations = *command.NameLocalizations
+		}
+		names = append(names, name)
+		errs = append(errs, name.validate())
+
+		errs = append(errs, validateDescription(path, "", command.Description))
+		if command.DescriptionLocalizations != nil {
+			for locale, description := range *command.DescriptionLocalizations {
+				errs = append(errs, validateDescription(path, locale, description))
+			}
+		}
+
+		errs = append(errs, validateOptions(path, command.Options))
+	}
+	errs = append(errs, validateUniqueNames(names))
+	return errors.Join(errs...)
+}
+
+func validateOptions(path string, options []*discordgo.ApplicationCommandOption) error {
+	errs := make([]error, 0)
+	names := make([]localizedName, 0, len(options))
+	for _, option := range options {
+		optionPath := path + " " + option.Name
+		name := localizedName{path: optionPath, name: option.Name, localizations: option.NameLocalizations}
+		names = append(names, name)
+		errs = append(errs, name.validate())
+
+		errs = append(errs, validateDescription(optionPath, "", option.Description))
+		for locale, description := range option.DescriptionLocalizations {
+			errs = append(errs, validateDescription(optionPath, locale, description))
+		}
+
+		for _, choice := range option.Choices {
+			errs = append(errs, validateChoiceName(optionPath, "", choice.Name))
+			for locale, name := range choice.NameLocalizations {
+				errs = append(errs, validateChoiceName(optionPath, locale, name))
+			}
+		}
+
+		errs = append(errs, validateOptions(optionPath, option.Options))
+	}
+	errs = append(errs, validateUniqueNames(names))
+	return errors.Join(errs...)
+}
+
+// localizedName is the name of a command or of an option in every locale
+type localizedName struct {
+	path          string
+	name          string
+	localizations map[discordgo.Locale]string
+}
+
+// in returns the name shown in a locale, the English one when it isn't
+// translated
+func (n localizedName) in(locale discordgo.Locale) string {
+	if name, ok := n.localizations[locale]; ok {
+		return name
+	}
+	return n.name
+}
+
+func (n localizedName) validate() error {
+	errs := []error{validateCommandName(n.path, "", n.name)}
+	for locale, name := range n.localizations {
+		errs = append(errs, validateCommandName(n.path, locale, name))
+	}
+	return errors.Join(errs...)
+}
+
+// validateUniqueNames checks that the commands, or the options of a command,
+// have different names in every locale
+func validateUniqueNames(names []localizedName) error {
+	locales := []discordgo.Locale{""}
+	for _, name := range names {
+		for locale := range name.localizations {
+			if !slices.Contains(locales, locale) {
+				locales = append(locales, locale)
+			}
+		}
+	}
+	slices.Sort(locales)
+
+	errs := make([]error, 0)
+	for _, locale := range locales {
+		used := make(map[string]string, len(names))
+		for _, name := range names {
+			if other, ok := used[name.in(locale)]; ok {
+				errs = append(errs, fmt.Errorf("%s: name %q of %s is already used by %s", name.path, name.in(locale), localeName(locale), other))
+			}
+			used[name.in(locale)] = name.path
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateCommandName checks a name of a slash command or option, which
+// must be lowercase when the language has cases
+func validateCommandName(path string, locale discordgo.Locale, name string) error {
+	if !commandNameRegexp.MatchString(name) {
+		return fmt.Errorf("%s: invalid name %q of %s, it must be 1-32 letters, numbers, - or _", path, name, localeName(locale))
+	}
+	if strings.ToLower(name) != name {
+		return fmt.Errorf("%s: name %q of %s must be lowercase", path, name, localeName(locale))
+	}
+	return nil
+}
+
+func validateDescription(path string, locale discordgo.Locale, description string) error {
+	if length := utf8.RuneCountInString(description); length < 1 || length > 100 {
+		return fmt.Errorf("%s: description of %s must be 1-100 characters long, it is %d", path, localeName(locale), length)
+	}
+	return nil
+}
+
+func validateChoiceName(path string, locale discordgo.Locale, name string) error {
+	if length := utf8.RuneCountInString(name); length < 1 || length > 100 {
+		return fmt.Errorf("%s: choice %q of %s must be 1-100 characters long", path, name, localeName(locale))
+	}
+	return nil
+}
+
+// localeName names a locale in the validation errors, where the empty one is
+// the English default
+func localeName(locale discordgo.Locale) string {
+	if locale == "" {
+		return "the default locale"
+	}
+	return "locale " + string(locale)
+}
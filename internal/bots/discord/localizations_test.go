@@ -0,0 +1,153 @@
+package discord
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Every command and option is translated in every locale
+func TestCommandLocalizations(t *testing.T) {
+	bot, session := newTestBot(t)
+	require.NoError(t, bot.initCommands([]string{"king"}))
+	require.NotEmpty(t, session.commands)
+
+	var status *discordgo.ApplicationCommandOption
+	for _, locale := range defaultCommandCatalogs.locales() {
+		for _, command := range session.commands {
+			require.NotNil(t, command.NameLocalizations, "/%s", command.Name)
+			assert.NotEmpty(t, (*command.NameLocalizations)[locale], "name of /%s in %s", command.Name, locale)
+			require.NotNil(t, command.DescriptionLocalizations, "/%s", command.Name)
+			assert.NotEmpty(t, (*command.DescriptionLocalizations)[locale], "description of /%s in %s", command.Name, locale)
+
+			for _, option := range command.Options {
+				assert.NotEmpty(t, option.NameLocalizations[locale], "name of /%s %s in %s", command.Name, option.Name, locale)
+				assert.NotEmpty(t, option.DescriptionLocalizations[locale], "description of /%s %s in %s", command.Name, option.Name, locale)
+				if command.Name == CommandUpdateTaskStatus && option.Name == "status" {
+					status = option
+				}
+			}
+		}
+	}
+
+	require.NotNil(t, status)
+	require.Len(t, status.Choices, 3)
+	assert.Equal(t, "In Progress", status.Choices[1].Name)
+	assert.Equal(t, "In corso", status.Choices[1].NameLocalizations[discordgo.Italian])
+}
+
+func TestLocalizeCommands(t *testing.T) {
+	commands := func() []*discordgo.ApplicationCommand {
+		return []*discordgo.ApplicationCommand{{
+			Name:        "create-task",
+			Description: "Create a task",
+			Options: []*discordgo.ApplicationCommandOption{
+				{Type: discordgo.ApplicationCommandOptionString, Name: "title", Description: "The title"},
+			},
+		}}
+	}
+
+	t.Run("localized", func(t *testing.T) {
+		localized := commands()
+		require.NoError(t, commandCatalogs{discordgo.Italian: {
+			"create-task": {Name: "crea-task", Options: map[string]commandLocalization{
+				"title": {Name: "titolo", Description: "Il titolo"},
+			}},
+		}}.localize(localized))
+
+		assert.Equal(t, &map[discordgo.Locale]string{discordgo.Italian: "crea-task"}, localized[0].NameLocalizations)
+		// Missing translations keep the English text
+		assert.Nil(t, localized[0].DescriptionLocalizations)
+		assert.Equal(t, "titolo", localized[0].Options[0].NameLocalizations[discordgo.Italian])
+		assert.Equal(t, "Il titolo", localized[0].Options[0].DescriptionLocalizations[discordgo.Italian])
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		err := commandCatalogs{discordgo.Italian: {"create-tasks": {Name: "crea-task"}}}.localize(commands())
+		assert.ErrorContains(t, err, `unknown command "create-tasks"`)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		err := commandCatalogs{discordgo.Italian: {"create-task": {Options: map[string]commandLocalization{
+			"name": {Name: "nome"},
+		}}}}.localize(commands())
+		assert.ErrorContains(t, err, `unknown option "name"`)
+	})
+
+	t.Run("unknown locale", func(t *testing.T) {
+		err := commandCatalogs{"italian": {"create-task": {Name: "crea-task"}}}.localize(commands())
+		assert.ErrorContains(t, err, `unknown Discord locale "italian"`)
+	})
+}
+
+func TestValidateCommands(t *testing.T) {
+	command := func(name string, localized string) *discordgo.ApplicationCommand {
+		return &discordgo.ApplicationCommand{
+			Name:              name,
+			Description:       "A command",
+			NameLocalizations: &map[discordgo.Locale]string{discordgo.Italian: localized},
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		commands []*discordgo.ApplicationCommand
+		err      string
+	}{
+		{"valid", []*discordgo.ApplicationCommand{command("create-task", "crea-task"), command("get-task", "visualizza_task")}, ""},
+		{"non-latin letters", []*discordgo.ApplicationCommand{command("create-task", "создать-задачу")}, ""},
+		{"uppercase", []*discordgo.ApplicationCommand{command("create-task", "Crea-Task")}, `name "Crea-Task" of locale it must be lowercase`},
+		{"space", []*discordgo.ApplicationCommand{command("create-task", "crea task")}, `invalid name "crea task" of locale it`},
+		{"too long", []*discordgo.ApplicationCommand{command("create-task", strings.Repeat("a", 33))}, "invalid name"},
+		{"empty", []*discordgo.ApplicationCommand{command("create-task", "")}, "invalid name"},
+		{
+			"duplicate",
+			[]*discordgo.ApplicationCommand{command("create-task", "task"), command("get-task", "task")},
+			`/get-task: name "task" of locale it is already used by /create-task`,
+		},
+		{
+			"duplicate of an English name",
+			[]*discordgo.ApplicationCommand{
+				command("create-task", "get-task"),
+				{Name: "get-task", Description: "A command"},
+			},
+			`/get-task: name "get-task" of locale it is already used by /create-task`,
+		},
+		{
+			"long description",
+			[]*discordgo.ApplicationCommand{{
+				Name:                     "create-task",
+				Description:              "A command",
+				DescriptionLocalizations: &map[discordgo.Locale]string{discordgo.Italian: strings.Repeat("a", 101)},
+			}},
+			"/create-task: description of locale it must be 1-100 characters long, it is 101",
+		},
+		{
+			"options",
+			[]*discordgo.ApplicationCommand{{
+				Name:        "create-task",
+				Description: "A command",
+				Options: []*discordgo.ApplicationCommandOption{
+					{Name: "title", Description: "The title", NameLocalizations: map[discordgo.Locale]string{discordgo.Italian: "Titolo"}},
+					{Name: "status", Description: "The status", Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Completed", Value: "Completed", NameLocalizations: map[discordgo.Locale]string{discordgo.Italian: ""}},
+					}},
+				},
+			}},
+			`/create-task title: name "Titolo" of locale it must be lowercase` + "\n" +
+				`/create-task status: choice "" of locale it must be 1-100 characters long`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCommands(tt.commands)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package discord
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/Formula-SAE/discord/internal/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// The user-facing texts of the bot are named text/template templates. Each
+// language has a catalog, templates/<language>.tmpl, defining the same
+// templates. A file of {{define}} blocks passed in
+// BotOptions.MessageTemplatesPath replaces the templates with the same name
+// of the default locale, so that wording and emoji can change without a code
+// change.
+
+//go:embed templates/*.tmpl
+var messageCatalogFiles embed.FS
+
+// defaultLanguage is the language of the messages when neither the member
+// nor the bot options choose a supported one
+const defaultLanguage = "en"
+
+// messageData is the data a message template is executed with
+type messageData map[string]any
+
+type messages struct {
+	defaults  *template.Template
+	templates *template.Template
+}
+
+var messageFuncs = template.FuncMap{
+	// bold and italic wrap markdown, user text in them must be escaped
+	"bold":    func(text any) string { return utils.Bold(fmt.Sprint(text)) },
+	"italic":  func(text any) string { return utils.Italic(fmt.Sprint(text)) },
+	"escape":  func(text any) string { return utils.EscapeMarkdown(fmt.Sprint(text)) },
+	"code":    func(text any) string { return utils.EscapedInlineCode(fmt.Sprint(text)) },
+	"link":    func(text any, url string) string { return utils.EscapedLink(fmt.Sprint(text), url) },
+	"mention": func(discordID string) string { return fmt.Sprintf("<@%s>", discordID) },
+	"channel": func(channelID string) string { return fmt.Sprintf("<#%s>", channelID) },
+
+	"mentions":     formatAssignees,
+	"statusIcon":   getStatusIcon,
+	"deliveryIcon": getDeliveryStatusIcon,
+	"truncate":     func(limit int, text string) string { return truncate(text, limit) },
+	"add":          func(a, b int) int { return a + b },
+
+	// relative renders a Discord timestamp, shown as e.g. "in 5 minutes"
+	"relative": formatRelativeTime,
+	// longDate renders a Discord timestamp shown as a date, e.g. "20 April 2026"
+	"longDate": func(t time.Time) string { return fmt.Sprintf("<t:%d:D>", t.Unix()) },
+	"date":     func(t time.Time) string { return t.Format(db.DueDateFormat) },
+}
+
+func parseMessageTemplates(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(messageFuncs).Option("missingkey=error").Parse(text)
+}
+
+// catalogs are the messages of each supported language
+type catalogs map[string]*messages
+
+var defaultCatalogs = mustLoadDefaultCatalogs()
+
+func mustLoadDefaultCatalogs() catalogs {
+	files, err := messageCatalogFiles.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+
+	defaults := make(catalogs, len(files))
+	for _, file := range files {
+		text, err := messageCatalogFiles.ReadFile("templates/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+
+		language := strings.TrimSuffix(file.Name(), ".tmpl")
+		templates := template.Must(parseMessageTemplates(language, string(text)))
+		defaults[language] = &messages{defaults: templates, templates: templates}
+	}
+	return defaults
+}
+
+// language returns the catalog language of a Discord locale, e.g. "it" for
+// "it" and "en" for "en-GB", and whether it is supported
+func (c catalogs) language(locale discordgo.Locale) (string, bool) {
+	language, _, _ := strings.Cut(string(locale), "-")
+	_, ok := c[language]
+	return language, ok
+}
+
+// lookup returns the messages of a locale. Unsupported locales use the
+// fallback locale, and then the default language.
+func (c catalogs) lookup(locale discordgo.Locale, fallback discordgo.Locale) *messages {
+	for _, candidate := range []discordgo.Locale{locale, fallback} {
+		if language, ok := c.language(candidate); ok {
+			return c[language]
+		}
+	}
+	return c[defaultLanguage]
+}
+
+// loadMessages returns the messages with the templates defined in the file
+// at path replacing the default ones
+func loadMessages(base *messages, path string) (*messages, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message templates: %w", err)
+	}
+
+	overrides, err := parseMessageTemplates("overrides", string(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse message templates: %w", err)
+	}
+
+	templates, err := base.defaults.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone message templates: %w", err)
+	}
+
+	for _, override := range overrides.Templates() {
+		if override.Name() == overrides.Name() {
+			continue
+		}
+		if templates.Lookup(override.Name()) == nil {
+			return nil, fmt.Errorf("unknown message template %q", override.Name())
+		}
+		if _, err := templates.AddParseTree(override.Name(), override.Tree); err != nil {
+			return nil, fmt.Errorf("failed to override message template %q: %w", override.Name(), err)
+		}
+	}
+
+	return &messages{defaults: base.defaults, templates: templates}, nil
+}
+
+// render executes the named template. If an overridden template fails, for
+// example because it uses a field the message doesn't have, the default one
+// is used instead.
+func (m *messages) render(name string, data messageData) string {
+	text, err := executeMessageTemplate(m.templates, name, data)
+	if err != nil && m.templates != m.defaults {
+		fmt.Printf("[messages] Failed to render overridden message %s, using the default: %v\n", name, err)
+		text, err = executeMessageTemplate(m.defaults, name, data)
+	}
+	if err != nil {
+		fmt.Printf("[messages] Failed to render message %s: %v\n", name, err)
+		return name
+	}
+	return text
+}
+
+// executeMessageTemplate renders a message without the surrounding blank
+// lines, which keep the templates readable
+func executeMessageTemplate(templates *template.Template, name string, data messageData) (string, error) {
+	builder := &strings.Builder{}
+	if err := templates.ExecuteTemplate(builder, name, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(builder.String()), nil
+}
+
+// render renders the named message template in a locale. Messages that
+// aren't answers to a member, e.g. push notifications, use the default
+// locale of the bot.
+func (b *DiscordBot) render(locale discordgo.Locale, name string, data messageData) string {
+	return b.messageCatalogs().lookup(locale, b.options.DefaultLocale).render(name, data)
+}
+
+// messageCatalogs returns the catalogs of the bot, or the default ones for
+// bots not created by NewDiscordBot
+func (b *DiscordBot) messageCatalogs() catalogs {
+	if b.catalogs == nil {
+		return defaultCatalogs
+	}
+	return b.catalogs
+}
+
+// userLocale returns the locale chosen by a member, for the messages that
+// aren't answers to an interaction of theirs
+func (b *DiscordBot) userLocale(discordID string) discordgo.Locale {
+	locale, err := b.db.GetUserLocale(discordID)
+	if err != nil {
+		fmt.Printf("[messages] Failed to get locale of %s: %v\n", discordID, err)
+	}
+	return discordgo.Locale(locale)
+}
@@ -0,0 +1,190 @@
+package discord
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMessages(t *testing.T) {
+	defaults := defaultCatalogs[defaultLanguage]
+	write := func(t *testing.T, text string) string {
+		path := filepath.Join(t.TempDir(), "messages.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte(text), 0o644))
+		return path
+	}
+
+	t.Run("override", func(t *testing.T) {
+		messages, err := loadMessages(defaults, write(t, `{{define "assigned-tasks.none"}}Nothing to do, {{bold "enjoy"}}!{{end}}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Nothing to do, **enjoy**!", messages.render("assigned-tasks.none", nil))
+		// Templates that are not overridden keep the default text
+		assert.Equal(t, defaults.render("create-task.failed", nil), messages.render("create-task.failed", nil))
+		// The defaults are not changed
+		assert.Contains(t, defaults.render("assigned-tasks.none", nil), "no tasks assigned")
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := loadMessages(defaults, write(t, `{{define "assigned-tasks.nothing"}}Nothing{{end}}`))
+		assert.ErrorContains(t, err, "assigned-tasks.nothing")
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := loadMessages(defaults, write(t, `{{define "assigned-tasks.none"}}{{bold}{{end}}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := loadMessages(defaults, filepath.Join(t.TempDir(), "missing.tmpl"))
+		assert.Error(t, err)
+	})
+
+	t.Run("broken override falls back to the default", func(t *testing.T) {
+		messages, err := loadMessages(defaults, write(t, `{{define "assign-task.success"}}Assigned to {{.Assignee}}{{end}}`))
+		require.NoError(t, err)
+
+		content := messages.render("assign-task.success", messageData{"TaskID": 4, "AssigneeID": "2"})
+		assert.Contains(t, content, "Task assigned successfully!")
+		assert.Contains(t, content, "<@2>")
+	})
+
+	t.Run("bot uses the loaded messages", func(t *testing.T) {
+		bot, session := newTestBot(t)
+		messages, err := loadMessages(defaults, write(t, `{{define "assigned-tasks.none"}}Nothing to do{{end}}`))
+		require.NoError(t, err)
+		bot.catalogs = maps.Clone(defaultCatalogs)
+		bot.catalogs[defaultLanguage] = messages
+		bot.handleInteraction(session, commandInteraction(CommandAssignedTasks, testMember("1", "alice", 0)))
+
+		assert.Equal(t, "Nothing to do", session.content(t))
+	})
+}
+
+// templateNames returns the names of the templates of a catalog
+func templateNames(m *messages) []string {
+	names := make([]string, 0)
+	for _, tmpl := range m.defaults.Templates() {
+		if tmpl.Name() != m.defaults.Name() {
+			names = append(names, tmpl.Name())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Every language defines the same templates as the default one
+func TestCatalogsDefineTheSameTemplates(t *testing.T) {
+	require.Contains(t, defaultCatalogs, "it")
+	expected := templateNames(defaultCatalogs[defaultLanguage])
+
+	for language, messages := range defaultCatalogs {
+		t.Run(language, func(t *testing.T) {
+			names := templateNames(messages)
+			for _, name := range expected {
+				assert.Contains(t, names, name, "missing in %s", language)
+			}
+			for _, name := range names {
+				assert.Contains(t, expected, name, "only in %s", language)
+			}
+		})
+	}
+}
+
+// Every template the bot renders must exist in the catalogs
+func TestRenderedTemplatesExist(t *testing.T) {
+	sources, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	renderRegexp := regexp.MustCompile(`b\.(?:render|choice)\([^,]+, "([^"]+)"`)
+	for _, source := range sources {
+		if strings.HasSuffix(source, "_test.go") {
+			continue
+		}
+		text, err := os.ReadFile(source)
+		require.NoError(t, err)
+
+		for _, match := range renderRegexp.FindAllStringSubmatch(string(text), -1) {
+			for language, messages := range defaultCatalogs {
+				assert.NotNil(t, messages.defaults.Lookup(match[1]), "%s renders unknown message %s in %s", source, match[1], language)
+			}
+		}
+	}
+}
+
+func TestCatalogLookup(t *testing.T) {
+	english := defaultCatalogs["en"]
+	italian := defaultCatalogs["it"]
+
+	assert.Same(t, italian, defaultCatalogs.lookup(discordgo.Italian, ""))
+	assert.Same(t, english, defaultCatalogs.lookup(discordgo.EnglishGB, discordgo.Italian))
+	// Unsupported locales use the fallback, then English
+	assert.Same(t, italian, defaultCatalogs.lookup(discordgo.French, discordgo.Italian))
+	assert.Same(t, english, defaultCatalogs.lookup(discordgo.French, discordgo.German))
+	assert.Same(t, english, defaultCatalogs.lookup("", ""))
+}
+
+func TestInteractionLocale(t *testing.T) {
+	bot, session := newTestBot(t)
+	member := testMember("1", "alice", 0)
+	assignedTasks := func(locale discordgo.Locale) string {
+		session.reset()
+		i := commandInteraction(CommandAssignedTasks, member)
+		i.Locale = locale
+		bot.handleInteraction(session, i)
+		return session.content(t)
+	}
+
+	assert.Equal(t, "🎉 Al momento non hai task assegnati.", assignedTasks(discordgo.Italian))
+	assert.Contains(t, assignedTasks(discordgo.EnglishUS), "no tasks assigned")
+	assert.Contains(t, assignedTasks(discordgo.French), "no tasks assigned")
+
+	t.Run("user preference", func(t *testing.T) {
+		session.reset()
+		i := commandInteraction(CommandLanguage, member, stringOption("language", "it"))
+		i.Locale = discordgo.EnglishUS
+		bot.handleInteraction(session, i)
+		assert.Contains(t, session.content(t), "Lingua aggiornata!")
+
+		// The preference wins over the locale of the client
+		assert.Equal(t, "🎉 Al momento non hai task assegnati.", assignedTasks(discordgo.EnglishUS))
+
+		session.reset()
+		i = commandInteraction(CommandLanguage, member)
+		i.Locale = discordgo.EnglishUS
+		bot.handleInteraction(session, i)
+		assert.Contains(t, session.content(t), "Il bot ti risponde in italiano.")
+
+		session.reset()
+		i = commandInteraction(CommandLanguage, member, stringOption("language", languageAuto))
+		i.Locale = discordgo.EnglishUS
+		bot.handleInteraction(session, i)
+		assert.Contains(t, session.content(t), "language of your Discord client")
+
+		assert.Contains(t, assignedTasks(discordgo.EnglishUS), "no tasks assigned")
+	})
+
+	t.Run("direct messages use the preference", func(t *testing.T) {
+		assignee, err := bot.getOrCreateUser("2", "bob")
+		require.NoError(t, err)
+		require.NoError(t, bot.db.SetUserLocale(assignee.ID, "it"))
+
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandCreateTask, member,
+			stringOption("title", "Wiring"),
+			userOption("assignee", &discordgo.User{ID: "2", Username: "bob"}),
+		))
+
+		dms := session.messagesTo(dmChannelID("2"))
+		require.Len(t, dms, 1)
+		assert.Contains(t, dms[0].Content, "Ti è stato assegnato un nuovo task")
+	})
+}
@@ -0,0 +1,133 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+// notificationEventAll applies a notification method to every event
+const notificationEventAll = "all"
+
+// notificationEventChoices are the events of the notifications command,
+// named by the notifications.event message
+func (b *DiscordBot) notificationEventChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := []*discordgo.ApplicationCommandOptionChoice{
+		b.choice(notificationEventAll, "notifications.all-events", nil),
+	}
+	for _, event := range db.NotificationEvents {
+		choices = append(choices, b.choice(event, "notifications.event", messageData{"Event": event}))
+	}
+	return choices
+}
+
+func (b *DiscordBot) notificationMethodChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(db.NotificationMethods))
+	for _, method := range db.NotificationMethods {
+		choices = append(choices, b.choice(method, "notifications.method", messageData{"Method": method}))
+	}
+	return choices
+}
+
+func (b *DiscordBot) notificationsCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandNotifications {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range i.ApplicationCommandData().Options {
+		optionMap[opt.Name] = opt
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[notifications] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "notifications.lookup-failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	updated := false
+	if method, ok := optionMap["method"]; ok {
+		events := db.NotificationEvents
+		if event, ok := optionMap["event"]; ok && event.StringValue() != notificationEventAll {
+			events = []string{event.StringValue()}
+		}
+
+		for _, event := range events {
+			err = b.db.SetNotificationPreference(user.ID, event, method.StringValue())
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			fmt.Printf("[notifications] Failed to set notification preference: %v\n", err)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: b.render(i.Locale, "notifications.update-failed", messageData{"Error": err.Error()}),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		fmt.Printf("[notifications] User %s set %d event(s) to %s\n", i.Member.User.Username, len(events), method.StringValue())
+		updated = true
+	}
+
+	preferences, err := b.db.GetNotificationPreferences(user.ID)
+	if err != nil {
+		fmt.Printf("[notifications] Failed to get notification preferences: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "notifications.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	preferenceNames := make([]messageData, 0, len(db.NotificationEvents))
+	for _, event := range db.NotificationEvents {
+		preferenceNames = append(preferenceNames, messageData{
+			"Event":  event,
+			"Method": preferences[event],
+		})
+	}
+	respContent := b.render(i.Locale, "notifications.preferences", messageData{
+		"Updated":     updated,
+		"Preferences": preferenceNames,
+		"ChannelID":   b.options.NotificationChannelID,
+	})
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationsCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	bot.options.NotificationChannelID = "notifications"
+	member := testMember("1", "alice", 0)
+
+	bot.handleInteraction(session, commandInteraction(CommandNotifications, member))
+	content := session.content(t)
+	assert.Contains(t, content, "Your notification preferences:")
+	assert.Contains(t, content, "<#notifications>")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandNotifications, member,
+		stringOption("method", db.NOTIFICATION_METHOD_MENTION),
+		stringOption("event", notificationEventAll),
+	))
+	assert.Contains(t, session.content(t), "Notification preferences updated!")
+
+	method, err := bot.db.GetNotificationMethod("1", db.NOTIFICATION_EVENT_COMMENT)
+	require.NoError(t, err)
+	assert.Equal(t, db.NOTIFICATION_METHOD_MENTION, method)
+
+	session.reset()
+	bot.notifyUser("1", db.NOTIFICATION_EVENT_ASSIGNED, "Wire the BMS")
+	mentions := session.messagesTo("notifications")
+	require.Len(t, mentions, 1)
+	assert.Contains(t, mentions[0].Content, "<@1>")
+	assert.Equal(t, &discordgo.MessageAllowedMentions{Users: []string{"1"}}, mentions[0].AllowedMentions)
+}
+
+func TestNotifyUserFallsBackToMention(t *testing.T) {
+	bot, session := newTestBot(t)
+	bot.options.NotificationChannelID = "notifications"
+	session.closedDMs["1"] = true
+
+	bot.notifyUser("1", db.NOTIFICATION_EVENT_ASSIGNED, "Wire the BMS")
+	assert.Empty(t, session.messagesTo(dmChannelID("1")))
+	assert.Len(t, session.messagesTo("notifications"), 1)
+
+	// Without a notification channel the notification is dropped
+	session.reset()
+	bot.options.NotificationChannelID = ""
+	bot.notifyUser("1", db.NOTIFICATION_EVENT_ASSIGNED, "Wire the BMS")
+	assert.Empty(t, session.messages)
+}
@@ -1,5 +1,12 @@
 package discord
 
+import (
+	"fmt"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
 func (b *DiscordBot) sendPrivateMessage(userID string, message string) error {
 	channel, err := b.session.UserChannelCreate(userID)
 	if err != nil {
@@ -13,3 +20,139 @@ func (b *DiscordBot) sendPrivateMessage(userID string, message string) error {
 
 	return nil
 }
+
+// sendChannelMention posts a message mentioning the user in the notification channel
+func (b *DiscordBot) sendChannelMention(userID string, message string) error {
+	if b.options.NotificationChannelID == "" {
+		return fmt.Errorf("no notification channel configured")
+	}
+
+	// Only the recipient is pinged, other users mentioned in the message are not
+	_, err := b.session.ChannelMessageSendComplex(b.options.NotificationChannelID, &discordgo.MessageSend{
+		Content:         fmt.Sprintf("<@%s>\n\n%s", userID, message),
+		AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{userID}},
+	})
+	return err
+}
+
+// notifyUser delivers a notification the way the user chose for the event.
+// DMs that can't be delivered, e.g. because the user closed them, fall back
+// to a mention in the notification channel.
+func (b *DiscordBot) notifyUser(discordID string, event string, message string) {
+	method, err := b.db.GetNotificationMethod(discordID, event)
+	if err != nil {
+		fmt.Printf("[notifications] Failed to get notification preference of %s: %v\n", discordID, err)
+	}
+
+	switch method {
+	case db.NOTIFICATION_METHOD_NONE:
+		return
+	case db.NOTIFICATION_METHOD_MENTION:
+		err = b.sendChannelMention(discordID, message)
+	default:
+		err = b.sendPrivateMessage(discordID, message)
+		if err != nil {
+			fmt.Printf("[notifications] Failed to send %s notification to %s by DM, falling back to channel: %v\n", event, discordID, err)
+			err = b.sendChannelMention(discordID, message)
+		}
+	}
+
+	if err != nil {
+		fmt.Printf("[notifications] Failed to send %s notification to %s: %v\n", event, discordID, err)
+	}
+}
+
+// notifyTaskAssigned tells a user that a task has been assigned to them
+func (b *DiscordBot) notifyTaskAssigned(task *db.Task, assigneeDiscordID string) {
+	message := b.render(b.userLocale(assigneeDiscordID), "notify.assigned", messageData{"Task": task, "AssigneeID": assigneeDiscordID})
+	b.notifyUser(assigneeDiscordID, db.NOTIFICATION_EVENT_ASSIGNED, message)
+}
+
+// notifyTaskStatusUpdate tells the author and the assignees of a task about its new status
+func (b *DiscordBot) notifyTaskStatusUpdate(task *db.Task, updaterDiscordID string) {
+	allUsers := []db.User{task.Author}
+	allUsers = append(allUsers, task.AssignedUsers...)
+
+	for _, user := range allUsers {
+		message := b.render(b.userLocale(user.DiscordID), "notify.status", messageData{"Task": task, "UpdaterID": updaterDiscordID})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_STATUS, message)
+	}
+}
+
+// notifyTaskEdited tells the assignees of a task, except the editor, about the changed fields
+func (b *DiscordBot) notifyTaskEdited(task *db.Task, changes []db.TaskChange, editorDiscordID string) {
+	for _, user := range task.AssignedUsers {
+		if user.DiscordID == editorDiscordID {
+			continue
+		}
+
+		message := b.render(b.userLocale(user.DiscordID), "notify.edited", messageData{"Task": task, "Changes": changes, "EditorID": editorDiscordID})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_EDITED, message)
+	}
+}
+
+// notifyTaskComment tells the author and the assignees of a task, except the commenter, about a new comment
+func (b *DiscordBot) notifyTaskComment(task *db.Task, comment *db.TaskComment, commenterDiscordID string) {
+	allUsers := []db.User{task.Author}
+	allUsers = append(allUsers, task.AssignedUsers...)
+
+	notified := make(map[string]bool)
+	for _, user := range allUsers {
+		if user.DiscordID == commenterDiscordID || notified[user.DiscordID] {
+			continue
+		}
+		notified[user.DiscordID] = true
+
+		message := b.render(b.userLocale(user.DiscordID), "notify.comment", messageData{"Task": task, "Comment": comment, "CommenterID": commenterDiscordID})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_COMMENT, message)
+	}
+}
+
+// notifyTaskClaimed tells the author of a task that a member claimed it
+func (b *DiscordBot) notifyTaskClaimed(task *db.Task, claimerDiscordID string) {
+	if task.Author.DiscordID == claimerDiscordID {
+		return
+	}
+
+	message := b.render(b.userLocale(task.Author.DiscordID), "notify.claimed", messageData{"Task": task, "ClaimerID": claimerDiscordID})
+	b.notifyUser(task.Author.DiscordID, db.NOTIFICATION_EVENT_CLAIMED, message)
+}
+
+// notifyTaskDeleted tells the author and the assignees of a task that it has been deleted
+func (b *DiscordBot) notifyTaskDeleted(task *db.Task, deleterDiscordID string) {
+	allUsers := []db.User{task.Author}
+	allUsers = append(allUsers, task.AssignedUsers...)
+
+	for _, user := range allUsers {
+		message := b.render(b.userLocale(user.DiscordID), "notify.deleted", messageData{"Task": task, "DeleterID": deleterDiscordID})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_DELETED, message)
+	}
+}
+
+// notifyTaskRestored tells the author and the assignees of a task that it has been restored
+func (b *DiscordBot) notifyTaskRestored(task *db.Task, restorerDiscordID string) {
+	allUsers := []db.User{task.Author}
+	allUsers = append(allUsers, task.AssignedUsers...)
+
+	for _, user := range allUsers {
+		message := b.render(b.userLocale(user.DiscordID), "notify.restored", messageData{"Task": task, "RestorerID": restorerDiscordID})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_DELETED, message)
+	}
+}
+
+// notifyTaskIssueStateChanged tells the assignees of a task that its GitHub
+// issue has been closed or reopened, changing the task status
+func (b *DiscordBot) notifyTaskIssueStateChanged(task *db.Task, issueLink string, action string, sender string) {
+	for _, user := range task.AssignedUsers {
+		message := b.render(b.userLocale(user.DiscordID), "notify.issue-state-changed", messageData{"Task": task, "IssueLink": issueLink, "Action": action, "Sender": sender})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_STATUS, message)
+	}
+}
+
+// notifyTaskClosedByCommit tells the assignees of a task that a pushed commit completed it
+func (b *DiscordBot) notifyTaskClosedByCommit(task *db.Task, commitLink string, commitAuthor string) {
+	for _, user := range task.AssignedUsers {
+		message := b.render(b.userLocale(user.DiscordID), "notify.closed-by-commit", messageData{"Task": task, "CommitLink": commitLink, "CommitAuthor": commitAuthor})
+		b.notifyUser(user.DiscordID, db.NOTIFICATION_EVENT_STATUS, message)
+	}
+}
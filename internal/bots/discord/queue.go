@@ -0,0 +1,294 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	outboundPollInterval = 5 * time.Second
+	outboundBatchSize    = 50
+
+	// Failed deliveries are retried after 5s, 10s, 20s... up to 30 minutes,
+	// and given up after outboundMaxAttempts attempts
+	outboundBaseBackoff = 5 * time.Second
+	outboundMaxBackoff  = 30 * time.Minute
+	outboundMaxAttempts = 8
+
+	// Sent messages are kept for inspection for a week
+	outboundSentRetention = 7 * 24 * time.Hour
+
+	defaultOutboundWorkers = 4
+)
+
+// enqueueMessage persists a message to be delivered to a channel by the
+// outbound queue workers
+func (b *DiscordBot) enqueueMessage(channelID string, content string, subscriptionID *uint) error {
+	_, err := b.db.EnqueueOutboundMessage(channelID, content, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	// Wake up the queue without waiting for the next poll
+	select {
+	case b.outboundWake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// runQueue runs the passes of a queue until the context is cancelled: on
+// start, at every poll interval and when woken up. A pass returns how many
+// items it handled, and while passes make progress the next one runs right
+// away, as handling an item can make the next one due, like the following
+// message of a channel. Items that couldn't be handled, e.g. because their
+// outcome couldn't be recorded, are due again at once, so a pass that
+// handles nothing waits for the next poll instead of spinning on them.
+func runQueue(ctx context.Context, interval time.Duration, wake <-chan struct{}, pass func(ctx context.Context, now time.Time) int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && pass(ctx, time.Now()) > 0 {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// runOutboundQueue delivers the queued messages until the context is cancelled
+func (b *DiscordBot) runOutboundQueue(ctx context.Context) {
+	runQueue(ctx, outboundPollInterval, b.outboundWake, b.processOutboundMessages)
+}
+
+// processOutboundMessages delivers the messages due at now and returns how
+// many were handled, i.e. delivered or rescheduled. At most one message per
+// channel is due at a time, so the workers never deliver two messages of the
+// same channel concurrently.
+func (b *DiscordBot) processOutboundMessages(ctx context.Context, now time.Time) int {
+	messages, err := b.db.GetDueOutboundMessages(now, outboundBatchSize)
+	if err != nil {
+		fmt.Printf("[queue] Failed to get due messages: %v\n", err)
+		return 0
+	}
+
+	workers := b.options.OutboundWorkers
+	if workers <= 0 {
+		workers = defaultOutboundWorkers
+	}
+
+	var handled atomic.Int64
+	sem := make(chan struct{}, workers)
+	wg := sync.WaitGroup{}
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(message db.OutboundMessage) {
+			defer wg.Done()
+			if b.deliverOutboundMessage(message) {
+				handled.Add(1)
+			}
+			<-sem
+		}(message)
+	}
+	wg.Wait()
+
+	return int(handled.Load())
+}
+
+// deliverOutboundMessage attempts a delivery and reports whether its outcome
+// was recorded
+func (b *DiscordBot) deliverOutboundMessage(message db.OutboundMessage) bool {
+	// Rate limits are handled by rescheduling the message instead of blocking
+	// the worker. Queued messages mention members without pinging them.
+	_, err := b.session.ChannelMessageSendComplex(message.ChannelID, &discordgo.MessageSend{
+		Content:         message.Content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}, discordgo.WithRetryOnRatelimit(false))
+	if err == nil {
+		if err := b.db.MarkOutboundMessageSent(message.ID, time.Now()); err != nil {
+			fmt.Printf("[queue] Failed to mark message %d as sent: %v\n", message.ID, err)
+			return false
+		}
+		return true
+	}
+
+	var rateLimitErr *discordgo.RateLimitError
+	var restErr *discordgo.RESTError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		fmt.Printf("[queue] Rate limited on channel %s, retrying in %s\n", message.ChannelID, rateLimitErr.RetryAfter)
+		err = b.db.RetryOutboundMessage(message.ID, time.Now().Add(rateLimitErr.RetryAfter), err.Error())
+
+	case errors.As(err, &restErr) && restErr.Response != nil && isChannelUnreachable(restErr.Response.StatusCode):
+		// The channel has been deleted or the bot can't post there anymore:
+		// nothing sent there will ever be delivered
+		fmt.Printf("[queue] Channel %s is unreachable, disabling its deliveries: %v\n", message.ChannelID, err)
+		failErr := b.db.FailOutboundMessage(message.ID, err.Error())
+		if failErr != nil {
+			fmt.Printf("[queue] Failed to mark message %d as failed: %v\n", message.ID, failErr)
+		}
+
+		var disabled int64
+		disabled, err = b.db.DisableChannelDeliveries(message.ChannelID, err.Error())
+		if err == nil && disabled > 0 {
+			fmt.Printf("[queue] Disabled %d subscription(s) of channel %s\n", disabled, message.ChannelID)
+		}
+		if err == nil {
+			err = failErr
+		}
+
+	case errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode < http.StatusInternalServerError:
+		// Other client errors won't succeed on retry
+		fmt.Printf("[queue] Message %d rejected by Discord: %v\n", message.ID, err)
+		err = b.db.FailOutboundMessage(message.ID, err.Error())
+
+	case message.Attempts+1 >= outboundMaxAttempts:
+		fmt.Printf("[queue] Giving up on message %d after %d attempts: %v\n", message.ID, message.Attempts+1, err)
+		err = b.db.FailOutboundMessage(message.ID, err.Error())
+
+	default:
+		backoff := outboundBackoff(message.Attempts + 1)
+		fmt.Printf("[queue] Failed to deliver message %d, retrying in %s: %v\n", message.ID, backoff, err)
+		err = b.db.RetryOutboundMessage(message.ID, time.Now().Add(backoff), err.Error())
+	}
+
+	if err != nil {
+		fmt.Printf("[queue] Failed to update message %d: %v\n", message.ID, err)
+		return false
+	}
+	return true
+}
+
+// outboundBackoff returns the delay before the next delivery attempt
+func outboundBackoff(attempts int) time.Duration {
+	backoff := outboundBaseBackoff
+	for range attempts - 1 {
+		backoff *= 2
+		if backoff >= outboundMaxBackoff {
+			return outboundMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// isChannelUnreachable reports whether a response status means that messages
+// can't be delivered to the channel anymore
+func isChannelUnreachable(statusCode int) bool {
+	return statusCode == http.StatusNotFound || statusCode == http.StatusForbidden
+}
+
+// purgeDeliveryHistoryPeriodically removes the sent messages, the sent task
+// events and the processed webhook deliveries kept for inspection, once a day
+func (b *DiscordBot) purgeDeliveryHistoryPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(deletedTasksPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := b.db.PurgeSentOutboundMessages(time.Now().Add(-outboundSentRetention))
+		if err != nil {
+			fmt.Printf("[queue] Failed to purge sent messages: %v\n", err)
+		} else if purged > 0 {
+			fmt.Printf("[queue] Purged %d sent messages\n", purged)
+		}
+
+		purged, err = b.db.PurgeSentTaskWebhookDeliveries(time.Now().Add(-outboundSentRetention))
+		if err != nil {
+			fmt.Printf("[queue] Failed to purge task webhook deliveries: %v\n", err)
+		} else if purged > 0 {
+			fmt.Printf("[queue] Purged %d task webhook deliveries\n", purged)
+		}
+
+		purged, err = b.db.PurgeWebhookDeliveries(time.Now().Add(-webhookDeliveryRetention))
+		if err != nil {
+			fmt.Printf("[queue] Failed to purge webhook deliveries: %v\n", err)
+		} else if purged > 0 {
+			fmt.Printf("[queue] Purged %d webhook deliveries\n", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *DiscordBot) queueStatusCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandQueueStatus {
+		return
+	}
+
+	if !isAdmin(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "queue-status.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	counts, err := b.db.CountOutboundMessages()
+	if err != nil {
+		fmt.Printf("[queue-status] Failed to count messages: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "queue-status.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	failed, err := b.db.GetFailedOutboundMessages(5)
+	if err != nil {
+		fmt.Printf("[queue-status] Failed to get failed messages: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "queue-status.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	respContent := b.render(i.Locale, "queue-status.status", messageData{
+		"Pending":  counts[db.OUTBOUND_PENDING],
+		"Sent":     counts[db.OUTBOUND_SENT],
+		"Failed":   counts[db.OUTBOUND_FAILED],
+		"Failures": failed,
+	})
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
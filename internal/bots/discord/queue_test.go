@@ -0,0 +1,112 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueStatusCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	_, err := bot.db.EnqueueOutboundMessage("channel-1", "Pushed 1 commit", nil)
+	require.NoError(t, err)
+	failed, err := bot.db.EnqueueOutboundMessage("channel-2", "Pushed 2 commits", nil)
+	require.NoError(t, err)
+	require.NoError(t, bot.db.FailOutboundMessage(failed.ID, "Missing Access"))
+
+	bot.handleInteraction(session, commandInteraction(CommandQueueStatus, testMember("1", "alice", 0)))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandQueueStatus, testMember("1", "alice", discordgo.PermissionAdministrator)))
+	content := session.content(t)
+	assert.Contains(t, content, "_Pending_: 1")
+	assert.Contains(t, content, "_Failed_: 1")
+	assert.Contains(t, content, "<#channel-2> after")
+	assert.Contains(t, content, "Missing Access")
+}
+
+func TestRunQueue(t *testing.T) {
+	run := func(ctx context.Context, wake chan struct{}, pass func(ctx context.Context, now time.Time) int) chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			runQueue(ctx, time.Hour, wake, pass)
+			close(done)
+		}()
+		return done
+	}
+
+	t.Run("stops draining when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var passes atomic.Int64
+		done := run(ctx, nil, func(ctx context.Context, now time.Time) int {
+			if passes.Add(1) == 3 {
+				cancel()
+			}
+			// Full batches are always due
+			return 10
+		})
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the queue kept draining after being cancelled")
+		}
+		assert.Equal(t, int64(3), passes.Load())
+	})
+
+	t.Run("waits after a pass that handles nothing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		wake := make(chan struct{})
+		var passes atomic.Int64
+		done := run(ctx, wake, func(ctx context.Context, now time.Time) int {
+			if passes.Add(1) == 1 {
+				return 4
+			}
+			// The outcome of the items left couldn't be recorded
+			return 0
+		})
+
+		assert.Eventually(t, func() bool { return passes.Load() == 2 }, time.Second, time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int64(2), passes.Load())
+
+		wake <- struct{}{}
+		assert.Eventually(t, func() bool { return passes.Load() >= 3 }, time.Second, time.Millisecond)
+		cancel()
+		<-done
+	})
+}
+
+func TestOutboundQueueDrainsChannel(t *testing.T) {
+	bot, session := newTestBot(t)
+	for i := range 5 {
+		require.NoError(t, bot.enqueueMessage("channel-1", fmt.Sprintf("Commit %d", i), nil))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		bot.runOutboundQueue(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Only one message of a channel is due at a time, but the next ones are
+	// sent right after it instead of at the next poll
+	assert.Eventually(t, func() bool { return len(session.messagesTo("channel-1")) == 5 }, outboundPollInterval/2, 10*time.Millisecond)
+	messages := session.messagesTo("channel-1")
+	require.Len(t, messages, 5)
+	assert.Equal(t, "Commit 0", messages[0].Content)
+	assert.Equal(t, "Commit 4", messages[4].Content)
+}
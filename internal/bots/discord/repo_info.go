@@ -0,0 +1,398 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-github/v74/github"
+)
+
+const (
+	repoInfoCacheTTL      = 5 * time.Minute
+	recentCommitsCacheTTL = 2 * time.Minute
+	ciStatusCacheTTL      = time.Minute
+	githubCommandTimeout  = 10 * time.Second
+	recentCommitsCount    = 10
+	workflowRunsToInspect = 30
+)
+
+type repoInfo struct {
+	Repository       *github.Repository
+	OpenPullRequests int
+	OpenIssues       int
+
+	// LatestRelease is nil if the repository has no release
+	LatestRelease *github.RepositoryRelease
+}
+
+type ciStatus struct {
+	Branch string
+
+	// Runs holds the latest run of every workflow, newest first
+	Runs []*github.WorkflowRun
+}
+
+func (b *DiscordBot) fetchRepoInfo(ctx context.Context, repository string) (*repoInfo, error) {
+	return cachedGitHubCall(ctx, b.githubCache, "repo-info:"+repository, repoInfoCacheTTL, func(ctx context.Context) (*repoInfo, error) {
+		repo, _, err := b.gc.Repositories.Get(ctx, githubOrganization, repository)
+		if err != nil {
+			return nil, err
+		}
+		info := &repoInfo{Repository: repo}
+
+		// With one pull request per page, the number of the last page is the
+		// number of open pull requests
+		prs, resp, err := b.gc.PullRequests.List(ctx, githubOrganization, repository, &github.PullRequestListOptions{
+			State:       "open",
+			ListOptions: github.ListOptions{PerPage: 1},
+		})
+		if err != nil {
+			return nil, err
+		}
+		info.OpenPullRequests = len(prs)
+		if resp.LastPage > 0 {
+			info.OpenPullRequests = resp.LastPage
+		}
+
+		// GitHub counts pull requests as issues
+		info.OpenIssues = max(0, repo.GetOpenIssuesCount()-info.OpenPullRequests)
+
+		release, _, err := b.gc.Repositories.GetLatestRelease(ctx, githubOrganization, repository)
+		if err != nil && !isGitHubNotFound(err) {
+			return nil, err
+		}
+		info.LatestRelease = release
+
+		return info, nil
+	})
+}
+
+// fetchRecentCommits returns the latest commits of a branch, or of the
+// default branch if branch is empty
+func (b *DiscordBot) fetchRecentCommits(ctx context.Context, repository string, branch string) ([]*github.RepositoryCommit, error) {
+	key := fmt.Sprintf("recent-commits:%s:%s", repository, branch)
+	return cachedGitHubCall(ctx, b.githubCache, key, recentCommitsCacheTTL, func(ctx context.Context) ([]*github.RepositoryCommit, error) {
+		commits, _, err := b.gc.Repositories.ListCommits(ctx, githubOrganization, repository, &github.CommitsListOptions{
+			SHA:         branch,
+			ListOptions: github.ListOptions{PerPage: recentCommitsCount},
+		})
+		return commits, err
+	})
+}
+
+// fetchCIStatus returns the latest workflow runs on the default branch
+func (b *DiscordBot) fetchCIStatus(ctx context.Context, repository string) (*ciStatus, error) {
+	info, err := b.fetchRepoInfo(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	branch := info.Repository.GetDefaultBranch()
+
+	return cachedGitHubCall(ctx, b.githubCache, "ci-status:"+repository, ciStatusCacheTTL, func(ctx context.Context) (*ciStatus, error) {
+		runs, _, err := b.gc.Actions.ListRepositoryWorkflowRuns(ctx, githubOrganization, repository, &github.ListWorkflowRunsOptions{
+			Branch:      branch,
+			ListOptions: github.ListOptions{PerPage: workflowRunsToInspect},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		status := &ciStatus{Branch: branch}
+		seen := make(map[int64]bool)
+		for _, run := range runs.WorkflowRuns {
+			if seen[run.GetWorkflowID()] {
+				continue
+			}
+			seen[run.GetWorkflowID()] = true
+			status.Runs = append(status.Runs, run)
+		}
+
+		return status, nil
+	})
+}
+
+func isGitHubNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// githubErrorMessage explains a failed GitHub API call to the user
+func (b *DiscordBot) githubErrorMessage(locale discordgo.Locale, err error, repository string) string {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return b.render(locale, "github.rate-limited", messageData{"ResetAt": rateLimitErr.Rate.Reset.Time})
+	}
+
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		var retryAt *time.Time
+		if abuseErr.RetryAfter != nil {
+			retry := time.Now().Add(*abuseErr.RetryAfter)
+			retryAt = &retry
+		}
+		return b.render(locale, "github.throttled", messageData{"RetryAt": retryAt})
+	}
+
+	if isGitHubNotFound(err) {
+		return b.render(locale, "github.not-found", messageData{"Repository": repository})
+	}
+
+	return b.render(locale, "github.failed", nil)
+}
+
+// formatRelativeTime renders a Discord timestamp, shown as e.g. "in 5 minutes"
+func formatRelativeTime(t time.Time) string {
+	return fmt.Sprintf("<t:%d:R>", t.Unix())
+}
+
+func (b *DiscordBot) formatRepoInfo(locale discordgo.Locale, info *repoInfo) string {
+	releaseName := ""
+	if info.LatestRelease != nil {
+		releaseName = info.LatestRelease.GetName()
+		if releaseName == "" {
+			releaseName = info.LatestRelease.GetTagName()
+		}
+	}
+
+	return b.render(locale, "repo-info.info", messageData{
+		"Repo":             info.Repository,
+		"OpenPullRequests": info.OpenPullRequests,
+		"OpenIssues":       info.OpenIssues,
+		"Release":          info.LatestRelease,
+		"ReleaseName":      releaseName,
+	})
+}
+
+func (b *DiscordBot) formatRecentCommits(locale discordgo.Locale, repository string, branch string, commits []*github.RepositoryCommit) string {
+	items := make([]messageData, 0, len(commits))
+	for _, commit := range commits {
+		author := commit.GetCommit().GetAuthor().GetName()
+		if commit.GetAuthor().GetLogin() != "" {
+			author = commit.GetAuthor().GetLogin()
+		}
+
+		items = append(items, messageData{
+			"SHA":    shortSHA(commit.GetSHA()),
+			"URL":    commit.GetHTMLURL(),
+			"Title":  strings.SplitN(commit.GetCommit().GetMessage(), "\n", 2)[0],
+			"Author": author,
+			"Date":   commit.GetCommit().GetAuthor().GetDate().Time,
+		})
+	}
+
+	return b.render(locale, "recent-commits.list", messageData{"Repository": repository, "Branch": branch, "Commits": items})
+}
+
+func (b *DiscordBot) formatCIStatus(locale discordgo.Locale, repository string, status *ciStatus) string {
+	runs := make([]messageData, 0, len(status.Runs))
+	for _, run := range status.Runs {
+		state := run.GetConclusion()
+		if run.GetStatus() != "completed" {
+			state = strings.ReplaceAll(run.GetStatus(), "_", " ")
+		}
+
+		runs = append(runs, messageData{
+			"Icon":      getWorkflowRunIcon(run),
+			"Name":      run.GetName(),
+			"URL":       run.GetHTMLURL(),
+			"State":     state,
+			"UpdatedAt": run.GetUpdatedAt().Time,
+		})
+	}
+
+	return b.render(locale, "ci-status.list", messageData{"Repository": repository, "Branch": status.Branch, "Runs": runs})
+}
+
+func getWorkflowRunIcon(run *github.WorkflowRun) string {
+	if run.GetStatus() != "completed" {
+		return "⏳"
+	}
+
+	switch run.GetConclusion() {
+	case "success":
+		return "✅"
+	case "failure", "timed_out", "startup_failure":
+		return "❌"
+	case "cancelled":
+		return "🚫"
+	case "skipped", "neutral":
+		return "⏭️"
+	default:
+		return "⚪"
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func (b *DiscordBot) repoInfoCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandRepoInfo {
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) != 1 {
+		fmt.Printf("[repo-info] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.expect-repository", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	repository := options[0].StringValue()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), githubCommandTimeout)
+	defer cancel()
+
+	var respContent string
+	info, err := b.fetchRepoInfo(ctx, repository)
+	if err != nil {
+		fmt.Printf("[repo-info] Failed to get repository %s: %v\n", repository, err)
+		respContent = b.githubErrorMessage(i.Locale, err, repository)
+	} else {
+		respContent = b.formatRepoInfo(i.Locale, info)
+	}
+
+	respContent = truncate(respContent, 2000)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &respContent,
+	})
+	if err != nil {
+		fmt.Printf("[repo-info] Failed to edit response: %v\n", err)
+	}
+}
+
+func (b *DiscordBot) recentCommitsCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandRecentCommits {
+		return
+	}
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range i.ApplicationCommandData().Options {
+		optionMap[option.Name] = option
+	}
+
+	repositoryOption, ok := optionMap["repository"]
+	if !ok {
+		fmt.Printf("[recent-commits] Repository option not found\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.expect-repository", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	repository := repositoryOption.StringValue()
+
+	branch := ""
+	if branchOption, ok := optionMap["branch"]; ok {
+		branch = strings.TrimSpace(branchOption.StringValue())
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), githubCommandTimeout)
+	defer cancel()
+
+	var respContent string
+	commits, err := b.fetchRecentCommits(ctx, repository, branch)
+	if err != nil {
+		fmt.Printf("[recent-commits] Failed to get commits of %s: %v\n", repository, err)
+		respContent = b.githubErrorMessage(i.Locale, err, repository)
+	} else {
+		respContent = b.formatRecentCommits(i.Locale, repository, branch, commits)
+	}
+
+	respContent = truncate(respContent, 2000)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &respContent,
+	})
+	if err != nil {
+		fmt.Printf("[recent-commits] Failed to edit response: %v\n", err)
+	}
+}
+
+func (b *DiscordBot) ciStatusCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandCIStatus {
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) != 1 {
+		fmt.Printf("[ci-status] Invalid number of options provided: %d\n", len(options))
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.expect-repository", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	repository := options[0].StringValue()
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), githubCommandTimeout)
+	defer cancel()
+
+	var respContent string
+	status, err := b.fetchCIStatus(ctx, repository)
+	if err != nil {
+		fmt.Printf("[ci-status] Failed to get CI status of %s: %v\n", repository, err)
+		respContent = b.githubErrorMessage(i.Locale, err, repository)
+	} else {
+		respContent = b.formatCIStatus(i.Locale, repository, status)
+	}
+
+	respContent = truncate(respContent, 2000)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &respContent,
+	})
+	if err != nil {
+		fmt.Printf("[ci-status] Failed to edit response: %v\n", err)
+	}
+}
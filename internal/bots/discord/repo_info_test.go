@@ -0,0 +1,146 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRepoInfo(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/ApexCorse/king", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(map[string]any{
+			"full_name":         "ApexCorse/king",
+			"description":       "Task tracker bot",
+			"default_branch":    "main",
+			"open_issues_count": 7,
+		})
+	})
+	mux.HandleFunc("GET /repos/ApexCorse/king/pulls", func(w http.ResponseWriter, r *http.Request) {
+		// One pull request per page, the last page being the third
+		w.Header().Set("Link", `<https://api.github.com/repos/ApexCorse/king/pulls?per_page=1&page=3>; rel="last"`)
+		json.NewEncoder(w).Encode([]map[string]any{{"number": 1}})
+	})
+	mux.HandleFunc("GET /repos/ApexCorse/king/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	})
+	bot := &DiscordBot{gc: newTestGitHubClient(t, mux), githubCache: newGitHubCache()}
+
+	info, err := bot.fetchRepoInfo(context.Background(), "king")
+	require.NoError(t, err)
+	assert.Equal(t, "main", info.Repository.GetDefaultBranch())
+	assert.Equal(t, 3, info.OpenPullRequests)
+	assert.Equal(t, 4, info.OpenIssues)
+	assert.Nil(t, info.LatestRelease)
+
+	msg := bot.formatRepoInfo("", info)
+	assert.Contains(t, msg, "Task tracker bot")
+	assert.Contains(t, msg, "Latest release**: none")
+
+	// The second call is served from the cache
+	_, err = bot.fetchRepoInfo(context.Background(), "king")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+}
+
+func TestFetchCIStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/ApexCorse/king", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"default_branch": "main"})
+	})
+	mux.HandleFunc("GET /repos/ApexCorse/king/pulls", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{})
+	})
+	mux.HandleFunc("GET /repos/ApexCorse/king/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /repos/ApexCorse/king/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "main", r.URL.Query().Get("branch"))
+		json.NewEncoder(w).Encode(map[string]any{
+			"total_count": 3,
+			"workflow_runs": []map[string]any{
+				{"workflow_id": 1, "name": "Test", "status": "in_progress"},
+				{"workflow_id": 2, "name": "Lint", "status": "completed", "conclusion": "failure"},
+				{"workflow_id": 1, "name": "Test", "status": "completed", "conclusion": "success"},
+			},
+		})
+	})
+	bot := &DiscordBot{gc: newTestGitHubClient(t, mux), githubCache: newGitHubCache()}
+
+	status, err := bot.fetchCIStatus(context.Background(), "king")
+	require.NoError(t, err)
+	assert.Equal(t, "main", status.Branch)
+	require.Len(t, status.Runs, 2)
+	assert.Equal(t, "in_progress", status.Runs[0].GetStatus())
+	assert.Equal(t, "failure", status.Runs[1].GetConclusion())
+
+	msg := bot.formatCIStatus("", "king", status)
+	assert.Contains(t, msg, "⏳")
+	assert.Contains(t, msg, "in progress")
+	assert.Contains(t, msg, "❌")
+}
+
+func TestGitHubRateLimitMessage(t *testing.T) {
+	reset := time.Now().Add(10 * time.Minute).Unix()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/ApexCorse/king/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", reset))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "API rate limit exceeded for user."}`))
+	})
+	bot := &DiscordBot{gc: newTestGitHubClient(t, mux), githubCache: newGitHubCache()}
+
+	_, err := bot.fetchRecentCommits(context.Background(), "king", "")
+	require.Error(t, err)
+
+	msg := bot.githubErrorMessage("", err, "king")
+	assert.Contains(t, msg, "rate limit")
+	assert.Contains(t, msg, fmt.Sprintf("<t:%d:R>", reset))
+}
+
+func TestRepositoryCommands(t *testing.T) {
+	bot, session := newTestBot(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/ApexCorse/king/commits", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "develop", r.URL.Query().Get("sha"))
+		json.NewEncoder(w).Encode([]map[string]any{
+			{
+				"sha":      "0123456789abcdef",
+				"html_url": "https://github.com/ApexCorse/king/commit/0123456789abcdef",
+				"commit":   map[string]any{"message": "Fix the CAN parser\n\nDetails", "author": map[string]any{"name": "Alice"}},
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/ApexCorse/ghost", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	})
+	bot.gc = newTestGitHubClient(t, mux)
+
+	bot.handleInteraction(session, commandInteraction(CommandRecentCommits, testMember("1", "alice", 0),
+		stringOption("repository", "king"),
+		stringOption("branch", "develop"),
+	))
+	assert.Equal(t, discordgo.InteractionResponseDeferredChannelMessageWithSource, session.response(t).Type)
+	content := session.content(t)
+	assert.Contains(t, content, "Fix the CAN parser")
+	assert.NotContains(t, content, "Details")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRepoInfo, testMember("1", "alice", 0), stringOption("repository", "ghost")))
+	assert.Contains(t, session.content(t), "`ghost`")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandCIStatus, testMember("1", "alice", 0), stringOption("repository", "ghost")))
+	assert.Contains(t, session.content(t), "`ghost`")
+}
@@ -8,12 +8,15 @@ import (
 	"github.com/google/go-github/v74/github"
 )
 
+// githubOrganization owns the repositories the bot works with
+const githubOrganization = "ApexCorse"
+
 func (b *DiscordBot) updateRepositoriesInDB(ctx context.Context) ([]string, error) {
-	githubRepos, _, err := b.gc.Repositories.ListByOrg(ctx, "ApexCorse", &github.RepositoryListByOrgOptions{
+	githubRepos, _, err := b.gc.Repositories.ListByOrg(ctx, githubOrganization, &github.RepositoryListByOrgOptions{
 		Sort: "full_name",
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repositories from GitHub organization 'ApexCorse': %w", err)
+		return nil, fmt.Errorf("failed to fetch repositories from GitHub organization '%s': %w", githubOrganization, err)
 	}
 
 	for _, repo := range githubRepos {
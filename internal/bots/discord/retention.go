@@ -0,0 +1,36 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const deletedTasksPurgeInterval = 24 * time.Hour
+
+// purgeDeletedTasksPeriodically permanently removes the tasks that have been in
+// the trash for longer than the configured retention, once a day.
+func (b *DiscordBot) purgeDeletedTasksPeriodically(ctx context.Context) {
+	if b.options.DeletedTaskRetention <= 0 {
+		fmt.Printf("[retention] Deleted tasks retention disabled\n")
+		return
+	}
+
+	ticker := time.NewTicker(deletedTasksPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := b.db.PurgeDeletedTasks(time.Now().Add(-b.options.DeletedTaskRetention))
+		if err != nil {
+			fmt.Printf("[retention] Failed to purge deleted tasks: %v\n", err)
+		} else if purged > 0 {
+			fmt.Printf("[retention] Purged %d deleted tasks\n", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
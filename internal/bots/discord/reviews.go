@@ -0,0 +1,320 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	reviewReminderInterval         = 30 * time.Minute
+	defaultReviewReminderThreshold = 24 * time.Hour
+	reviewRefreshTimeout           = 10 * time.Second
+)
+
+type GitHubUser struct {
+	Login string `json:"login"`
+}
+
+type PullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Number             int          `json:"number"`
+		Title              string       `json:"title"`
+		HTMLURL            string       `json:"html_url"`
+		State              string       `json:"state"`
+		User               GitHubUser   `json:"user"`
+		RequestedReviewers []GitHubUser `json:"requested_reviewers"`
+	} `json:"pull_request"`
+
+	// RequestedReviewer is set on review_requested and review_request_removed
+	// actions, unless the review was requested to a team
+	RequestedReviewer *GitHubUser `json:"requested_reviewer"`
+
+	// Review is set on pull_request_review events
+	Review struct {
+		User GitHubUser `json:"user"`
+	} `json:"review"`
+
+	Repository struct {
+		Name  string     `json:"name"`
+		Owner GitHubUser `json:"owner"`
+	} `json:"repository"`
+}
+
+func (payload *PullRequestEvent) reviewRequest(reviewerLogin string) *db.ReviewRequest {
+	return &db.ReviewRequest{
+		Repository:    payload.Repository.Name,
+		Owner:         payload.Repository.Owner.Login,
+		Number:        payload.Number,
+		Title:         payload.PullRequest.Title,
+		URL:           payload.PullRequest.HTMLURL,
+		AuthorLogin:   payload.PullRequest.User.Login,
+		ReviewerLogin: reviewerLogin,
+	}
+}
+
+// processPullRequestDelivery keeps the tracked review requests in sync with
+// pull_request and pull_request_review events
+func (b *DiscordBot) processPullRequestDelivery(delivery db.WebhookDelivery) (string, string) {
+	payload := &PullRequestEvent{}
+	if err := json.Unmarshal([]byte(delivery.Payload), payload); err != nil {
+		return db.WEBHOOK_DELIVERY_FAILED, fmt.Sprintf("invalid payload: %v", err)
+	}
+	repository := payload.Repository.Name
+
+	// Reviews are submitted on pull_request_review events, which carry the
+	// pull request number only in the pull request itself
+	if delivery.Event == "pull_request_review" {
+		if payload.Action != "submitted" {
+			return db.WEBHOOK_DELIVERY_IGNORED, fmt.Sprintf("unsupported action %s", payload.Action)
+		}
+		number := payload.PullRequest.Number
+		if err := b.db.DeleteReviewRequest(repository, number, payload.Review.User.Login); err != nil {
+			return db.WEBHOOK_DELIVERY_FAILED, err.Error()
+		}
+		return db.WEBHOOK_DELIVERY_PROCESSED, fmt.Sprintf("review of %s submitted on #%d", payload.Review.User.Login, number)
+	}
+
+	var err error
+	switch payload.Action {
+	case "review_requested":
+		if payload.RequestedReviewer == nil {
+			return db.WEBHOOK_DELIVERY_IGNORED, "review requested to a team"
+		}
+		err = b.db.UpsertReviewRequest(payload.reviewRequest(payload.RequestedReviewer.Login))
+
+	case "review_request_removed":
+		if payload.RequestedReviewer == nil {
+			return db.WEBHOOK_DELIVERY_IGNORED, "review request removed from a team"
+		}
+		err = b.db.DeleteReviewRequest(repository, payload.Number, payload.RequestedReviewer.Login)
+
+	case "opened", "reopened":
+		for _, reviewer := range payload.PullRequest.RequestedReviewers {
+			if err = b.db.UpsertReviewRequest(payload.reviewRequest(reviewer.Login)); err != nil {
+				break
+			}
+		}
+
+	case "closed":
+		err = b.db.DeletePullRequestReviewRequests(repository, payload.Number)
+
+	case "edited":
+		err = b.db.UpdatePullRequestTitle(repository, payload.Number, payload.PullRequest.Title)
+
+	default:
+		return db.WEBHOOK_DELIVERY_IGNORED, fmt.Sprintf("unsupported action %s", payload.Action)
+	}
+	if err != nil {
+		return db.WEBHOOK_DELIVERY_FAILED, err.Error()
+	}
+
+	return db.WEBHOOK_DELIVERY_PROCESSED, fmt.Sprintf("pull request #%d %s", payload.Number, payload.Action)
+}
+
+func (b *DiscordBot) runReviewReminders(ctx context.Context) {
+	ticker := time.NewTicker(reviewReminderInterval)
+	defer ticker.Stop()
+
+	for {
+		b.sendReviewReminders(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *DiscordBot) reviewReminderThreshold() time.Duration {
+	if b.options.ReviewReminderThreshold > 0 {
+		return b.options.ReviewReminderThreshold
+	}
+	return defaultReviewReminderThreshold
+}
+
+// sendReviewReminders reminds linked members of the reviews pending longer
+// than the threshold, at most once per threshold
+func (b *DiscordBot) sendReviewReminders(ctx context.Context, now time.Time) {
+	threshold := b.reviewReminderThreshold()
+	requests, err := b.db.GetDueReviewReminders(now.Add(-threshold))
+	if err != nil {
+		fmt.Printf("[reviews] Failed to get due review reminders: %v\n", err)
+		return
+	}
+	if len(requests) == 0 {
+		return
+	}
+
+	logins := make([]string, 0, len(requests))
+	for _, request := range requests {
+		logins = append(logins, request.ReviewerLogin)
+	}
+	discordIDs, err := b.db.GetDiscordIDsByGitHubLogins(logins)
+	if err != nil {
+		fmt.Printf("[reviews] Failed to resolve reviewers: %v\n", err)
+		return
+	}
+
+	for _, request := range requests {
+		discordID, ok := discordIDs[request.ReviewerLogin]
+		if !ok {
+			continue
+		}
+
+		pending, err := b.refreshReviewRequest(ctx, request)
+		if err != nil {
+			fmt.Printf("[reviews] Failed to refresh %s#%d: %v\n", request.Repository, request.Number, err)
+			continue
+		}
+		if !pending {
+			continue
+		}
+
+		b.notifyReviewPending(request, discordID, now.Sub(request.RequestedAt))
+		if err := b.db.MarkReviewReminded(request.ID, now); err != nil {
+			fmt.Printf("[reviews] Failed to mark review %d reminded: %v\n", request.ID, err)
+		}
+	}
+}
+
+// refreshReviewRequest checks on GitHub that the review is still pending, to
+// catch events that were missed, and stops tracking it otherwise
+func (b *DiscordBot) refreshReviewRequest(ctx context.Context, request db.ReviewRequest) (bool, error) {
+	if request.Owner == "" {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reviewRefreshTimeout)
+	defer cancel()
+
+	pr, _, err := b.gc.PullRequests.Get(ctx, request.Owner, request.Repository, request.Number)
+	if err != nil {
+		return false, err
+	}
+
+	if pr.GetState() != "open" {
+		fmt.Printf("[reviews] %s#%d is %s, no longer tracking its reviews\n", request.Repository, request.Number, pr.GetState())
+		return false, b.db.DeletePullRequestReviewRequests(request.Repository, request.Number)
+	}
+
+	for _, reviewer := range pr.RequestedReviewers {
+		if strings.EqualFold(reviewer.GetLogin(), request.ReviewerLogin) {
+			if pr.GetTitle() != request.Title {
+				b.db.UpdatePullRequestTitle(request.Repository, request.Number, pr.GetTitle())
+			}
+			return true, nil
+		}
+	}
+
+	fmt.Printf("[reviews] Review of %s on %s#%d is no longer requested\n", request.ReviewerLogin, request.Repository, request.Number)
+	return false, b.db.DeleteReviewRequest(request.Repository, request.Number, request.ReviewerLogin)
+}
+
+// notifyReviewPending reminds a member of a pull request awaiting their review
+func (b *DiscordBot) notifyReviewPending(request db.ReviewRequest, reviewerDiscordID string, pendingFor time.Duration) {
+	locale := b.userLocale(reviewerDiscordID)
+	message := b.render(locale, "review.reminder", messageData{"Request": request, "PendingFor": b.formatPendingDuration(locale, pendingFor)})
+
+	b.notifyUser(reviewerDiscordID, db.NOTIFICATION_EVENT_REVIEW, message)
+}
+
+func (b *DiscordBot) formatPendingDuration(locale discordgo.Locale, d time.Duration) string {
+	return b.render(locale, "review.pending-for", messageData{
+		"Days":    int(d.Hours() / 24),
+		"Hours":   int(d.Hours()),
+		"Minutes": int(d.Minutes()),
+	})
+}
+
+func (b *DiscordBot) myReviewsCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandMyReviews {
+		return
+	}
+
+	if i.Member == nil || i.Member.User == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.not-member", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[my-reviews] Failed to get or create user: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "my-reviews.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if user.GitHubLogin == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "my-reviews.not-linked", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	requests, err := b.db.GetReviewRequestsByReviewer(*user.GitHubLogin)
+	if err != nil {
+		fmt.Printf("[my-reviews] Failed to get review requests: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "my-reviews.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	if len(requests) == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "my-reviews.none", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	now := time.Now()
+	reviews := make([]messageData, 0, len(requests))
+	for _, request := range requests {
+		reviews = append(reviews, messageData{"Request": request, "PendingFor": b.formatPendingDuration(i.Locale, now.Sub(request.RequestedAt))})
+	}
+	respContent := b.render(i.Locale, "my-reviews.list", messageData{"Reviews": reviews})
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,43 @@
+package discord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMyReviewsCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	member := testMember("1", "alice", 0)
+
+	bot.handleInteraction(session, commandInteraction(CommandMyReviews, member))
+	assert.Contains(t, session.content(t), "No GitHub account linked")
+
+	user, err := bot.getOrCreateUser("1", "alice")
+	require.NoError(t, err)
+	_, err = bot.db.LinkGitHubLogin(user.ID, "alice-gh")
+	require.NoError(t, err)
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandMyReviews, member))
+	assert.Contains(t, session.content(t), "All caught up!")
+
+	require.NoError(t, bot.db.UpsertReviewRequest(&db.ReviewRequest{
+		Repository:    "king",
+		Number:        7,
+		ReviewerLogin: "alice-gh",
+		Title:         "Add telemetry dashboard",
+		URL:           "https://github.com/ApexCorse/king/pull/7",
+		AuthorLogin:   "bob-gh",
+		RequestedAt:   time.Now().Add(-3 * time.Hour),
+	}))
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandMyReviews, member))
+	content := session.content(t)
+	assert.Contains(t, content, "#7 Add telemetry dashboard")
+	assert.Contains(t, content, "by `bob-gh`, 3 hours ago")
+}
@@ -0,0 +1,177 @@
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// Session is the part of the Discord API used by the bot. It is implemented
+// by *discordgo.Session, and by a fake in tests.
+type Session interface {
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error)
+	GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error)
+	ApplicationCommandCreate(appID string, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error)
+}
+
+// allowedMentionsSession sets the allowed mentions of the messages that don't
+// set them, so that user content in a message can only ping the members it
+// mentions, never @everyone, @here or a role
+type allowedMentionsSession struct {
+	Session
+}
+
+func withAllowedMentions(s Session) Session {
+	if _, ok := s.(allowedMentionsSession); ok {
+		return s
+	}
+	return allowedMentionsSession{Session: s}
+}
+
+func defaultAllowedMentions() *discordgo.MessageAllowedMentions {
+	return &discordgo.MessageAllowedMentions{
+		Parse: []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers},
+	}
+}
+
+func (s allowedMentionsSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	// Only responses carrying a message can mention, not e.g. modals
+	hasMessage := resp.Type == discordgo.InteractionResponseChannelMessageWithSource || resp.Type == discordgo.InteractionResponseUpdateMessage
+	if hasMessage && resp.Data != nil && resp.Data.AllowedMentions == nil {
+		resp.Data.AllowedMentions = defaultAllowedMentions()
+	}
+	return s.Session.InteractionRespond(interaction, resp, options...)
+}
+
+func (s allowedMentionsSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if newresp.AllowedMentions == nil {
+		newresp.AllowedMentions = defaultAllowedMentions()
+	}
+	return s.Session.InteractionResponseEdit(interaction, newresp, options...)
+}
+
+func (s allowedMentionsSession) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content}, options...)
+}
+
+func (s allowedMentionsSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if data.AllowedMentions == nil {
+		data.AllowedMentions = defaultAllowedMentions()
+	}
+	return s.Session.ChannelMessageSendComplex(channelID, data, options...)
+}
+
+type interactionHandler func(s Session, i *discordgo.InteractionCreate)
+
+// interactionHandlers returns the handlers of every command, component and
+// modal. Each of them ignores the interactions it doesn't handle.
+func (b *DiscordBot) interactionHandlers() []interactionHandler {
+	return []interactionHandler{
+		b.createTaskCommand,
+		b.getAssignedTasksCommand,
+		b.getTaskCommand,
+		b.getTasksByRoleCommand,
+		b.getUnassignedTasksByRoleCommand,
+		b.assignTaskCommand,
+		b.updateTaskStatusCommand,
+		b.getCompletedTasksByRoleCommand,
+		b.subscribeChannelToPushWebhookCommand,
+		b.unsubscribeChannelFromPushWebhookCommand,
+		b.deleteTaskCommand,
+		b.getSubscriptionsOfChannelCommand,
+		b.allSubscriptionsCommand,
+		b.unsubscribeButtonComponent,
+		b.editTaskCommand,
+		b.editTaskModalSubmit,
+		b.deleteTaskConfirmationComponent,
+		b.getDeletedTasksCommand,
+		b.restoreTaskCommand,
+		b.taskCardComponent,
+		b.openTaskCardComponent,
+		b.taskCommentModalSubmit,
+		b.claimTaskCommand,
+		b.claimTaskComponent,
+		b.addDigestCommand,
+		b.removeDigestCommand,
+		b.listDigestsCommand,
+		b.notificationsCommand,
+		b.queueStatusCommand,
+		b.getWebhookDeliveriesCommand,
+		b.linkGitHubCommand,
+		b.verifyGitHubCommand,
+		b.unlinkGitHubCommand,
+		b.myReviewsCommand,
+		b.taskToIssueCommand,
+		b.repoInfoCommand,
+		b.recentCommitsCommand,
+		b.ciStatusCommand,
+		b.languageCommand,
+		b.workloadCommand,
+		b.statsCommand,
+		b.exportTasksCommand,
+		b.importTasksCommand,
+		b.importTasksConfirmationComponent,
+		b.createAPITokenCommand,
+		b.listAPITokensCommand,
+		b.revokeAPITokenCommand,
+		b.addTaskWebhookCommand,
+		b.listTaskWebhooksCommand,
+		b.removeTaskWebhookCommand,
+		b.calendarLinkCommand,
+	}
+}
+
+// handleInteraction passes an interaction to every handler, like the gateway does
+func (b *DiscordBot) handleInteraction(s Session, i *discordgo.InteractionCreate) {
+	s = withAllowedMentions(s)
+	b.applyUserLocale(i)
+	for _, handler := range b.interactionHandlers() {
+		handler(s, i)
+	}
+}
+
+// botUser returns the user of the bot, or nil before the gateway is ready
+func (b *DiscordBot) botUser() *discordgo.User {
+	if b.gateway == nil || b.gateway.State == nil {
+		return nil
+	}
+	return b.gateway.State.User
+}
+
+// resolvedUser returns the user of a user option, as resolved by Discord
+// in the interaction data
+func resolvedUser(i *discordgo.InteractionCreate, option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.User {
+	user := option.UserValue(nil)
+	if resolved := i.ApplicationCommandData().Resolved; resolved != nil {
+		if resolvedUser, ok := resolved.Users[user.ID]; ok {
+			return resolvedUser
+		}
+	}
+	return user
+}
+
+// resolvedRole returns the role of a role option, as resolved by Discord
+// in the interaction data
+func resolvedRole(i *discordgo.InteractionCreate, option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.Role {
+	role := option.RoleValue(nil, "")
+	if resolved := i.ApplicationCommandData().Resolved; resolved != nil {
+		if resolvedRole, ok := resolved.Roles[role.ID]; ok {
+			return resolvedRole
+		}
+	}
+	return role
+}
+
+// resolvedAttachment returns the attachment of an attachment option, as
+// resolved by Discord in the interaction data. It is nil if Discord didn't
+// resolve it.
+func resolvedAttachment(i *discordgo.InteractionCreate, option *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageAttachment {
+	id, _ := option.Value.(string)
+	if resolved := i.ApplicationCommandData().Resolved; resolved != nil {
+		return resolved.Attachments[id]
+	}
+	return nil
+}
@@ -0,0 +1,371 @@
+package discord
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotUserID = "999"
+
+type sentMessage struct {
+	ChannelID       string
+	Content         string
+	AllowedMentions *discordgo.MessageAllowedMentions
+}
+
+// fakeSession records the calls made by the bot instead of reaching Discord
+type fakeSession struct {
+	mu sync.Mutex
+
+	responses []*discordgo.InteractionResponse
+	edits     []*discordgo.WebhookEdit
+	messages  []sentMessage
+	commands  []*discordgo.ApplicationCommand
+
+	// permissions are the permissions of the bot, keyed by channel ID
+	permissions map[string]int64
+
+	// memberPermissions are the permissions of the other users, keyed by
+	// user ID and then by channel ID
+	memberPermissions map[string]map[string]int64
+
+	// closedDMs are the users whose DMs can't be opened
+	closedDMs map[string]bool
+
+	// roles are the roles of the server
+	roles []*discordgo.Role
+}
+
+var _ Session = (*fakeSession)(nil)
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{
+		permissions:       make(map[string]int64),
+		memberPermissions: make(map[string]map[string]int64),
+		closedDMs:         make(map[string]bool),
+	}
+}
+
+func (f *fakeSession) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func (f *fakeSession) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.edits = append(f.edits, newresp)
+	return &discordgo.Message{}, nil
+}
+
+func (f *fakeSession) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return f.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Content: content})
+}
+
+func (f *fakeSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = append(f.messages, sentMessage{ChannelID: channelID, Content: data.Content, AllowedMentions: data.AllowedMentions})
+	return &discordgo.Message{ChannelID: channelID, Content: data.Content}, nil
+}
+
+func (f *fakeSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closedDMs[recipientID] {
+		return nil, errors.New("cannot send messages to this user")
+	}
+	return &discordgo.Channel{ID: dmChannelID(recipientID), Type: discordgo.ChannelTypeDM}, nil
+}
+
+func (f *fakeSession) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	channelPermissions := f.permissions
+	if userID != testBotUserID {
+		channelPermissions = f.memberPermissions[userID]
+	}
+	permissions, ok := channelPermissions[channelID]
+	if !ok {
+		return 0, errors.New("unknown channel")
+	}
+	return permissions, nil
+}
+
+func (f *fakeSession) GuildRoles(guildID string, options ...discordgo.RequestOption) ([]*discordgo.Role, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.roles, nil
+}
+
+func (f *fakeSession) ApplicationCommandCreate(appID string, guildID string, cmd *discordgo.ApplicationCommand, options ...discordgo.RequestOption) (*discordgo.ApplicationCommand, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.commands = append(f.commands, cmd)
+	return cmd, nil
+}
+
+// setMemberPermissions sets the permissions of a user in a channel
+func (f *fakeSession) setMemberPermissions(userID string, channelID string, permissions int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.memberPermissions[userID] == nil {
+		f.memberPermissions[userID] = make(map[string]int64)
+	}
+	f.memberPermissions[userID][channelID] = permissions
+}
+
+// reset forgets the calls recorded so far
+func (f *fakeSession) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.responses = nil
+	f.edits = nil
+	f.messages = nil
+}
+
+// dmChannelID is the ID of the fake DM channel with a user
+func dmChannelID(userID string) string {
+	return "dm-" + userID
+}
+
+// response returns the only response to the interaction
+func (f *fakeSession) response(t *testing.T) *discordgo.InteractionResponse {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	require.Len(t, f.responses, 1)
+	require.NotNil(t, f.responses[0].Data)
+	return f.responses[0]
+}
+
+// content returns the content of the only response, or of its edit when deferred
+func (f *fakeSession) content(t *testing.T) string {
+	t.Helper()
+	resp := f.response(t)
+	if resp.Type != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+		return resp.Data.Content
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.Len(t, f.edits, 1)
+	require.NotNil(t, f.edits[0].Content)
+	return *f.edits[0].Content
+}
+
+// messagesTo returns the messages sent to a channel
+func (f *fakeSession) messagesTo(channelID string) []sentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := make([]sentMessage, 0)
+	for _, message := range f.messages {
+		if message.ChannelID == channelID {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// newTestBot returns a bot backed by a test database and a fake session
+func newTestBot(t *testing.T) (*DiscordBot, *fakeSession) {
+	session := newFakeSession()
+	gateway := &discordgo.Session{State: discordgo.NewState()}
+	gateway.State.User = &discordgo.User{ID: testBotUserID, Username: "king"}
+
+	bot := &DiscordBot{
+		gateway:         gateway,
+		session:         withAllowedMentions(session),
+		db:              db.NewDB(db.CreateTestDB()),
+		githubCache:     newGitHubCache(),
+		imports:         newPendingImports(),
+		catalogs:        defaultCatalogs,
+		outboundWake:    make(chan struct{}, 1),
+		webhookWake:     make(chan struct{}, 1),
+		taskWebhookWake: make(chan struct{}, 1),
+	}
+	return bot, session
+}
+
+func testMember(id string, username string, permissions int64) *discordgo.Member {
+	return &discordgo.Member{
+		User:        &discordgo.User{ID: id, Username: username},
+		Permissions: permissions,
+	}
+}
+
+// testOption is a command option, with the user, role or attachment Discord
+// resolves for it
+type testOption struct {
+	option     *discordgo.ApplicationCommandInteractionDataOption
+	user       *discordgo.User
+	role       *discordgo.Role
+	attachment *discordgo.MessageAttachment
+}
+
+func stringOption(name string, value string) testOption {
+	return testOption{option: &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}}
+}
+
+func intOption(name string, value int) testOption {
+	return testOption{option: &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionInteger, Value: float64(value)}}
+}
+
+func userOption(name string, user *discordgo.User) testOption {
+	return testOption{
+		option: &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionUser, Value: user.ID},
+		user:   user,
+	}
+}
+
+func roleOption(name string, role *discordgo.Role) testOption {
+	return testOption{
+		option: &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionRole, Value: role.ID},
+		role:   role,
+	}
+}
+
+func attachmentOption(name string, attachment *discordgo.MessageAttachment) testOption {
+	return testOption{
+		option:     &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionAttachment, Value: attachment.ID},
+		attachment: attachment,
+	}
+}
+
+func channelOption(name string, channelID string) testOption {
+	return testOption{option: &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionChannel, Value: channelID}}
+}
+
+func commandInteraction(name string, member *discordgo.Member, options ...testOption) *discordgo.InteractionCreate {
+	data := discordgo.ApplicationCommandInteractionData{
+		Name: name,
+		Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+			Users:       make(map[string]*discordgo.User),
+			Roles:       make(map[string]*discordgo.Role),
+			Attachments: make(map[string]*discordgo.MessageAttachment),
+		},
+	}
+	for _, option := range options {
+		data.Options = append(data.Options, option.option)
+		if option.user != nil {
+			data.Resolved.Users[option.user.ID] = option.user
+		}
+		if option.role != nil {
+			data.Resolved.Roles[option.role.ID] = option.role
+		}
+		if option.attachment != nil {
+			data.Resolved.Attachments[option.attachment.ID] = option.attachment
+		}
+	}
+
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionApplicationCommand,
+		ChannelID: "channel-1",
+		Member:    member,
+		Data:      data,
+	}}
+}
+
+func componentInteraction(customID string, member *discordgo.Member, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionMessageComponent,
+		ChannelID: "channel-1",
+		Member:    member,
+		Data:      discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+	}}
+}
+
+func modalInteraction(customID string, member *discordgo.Member, values map[string]string) *discordgo.InteractionCreate {
+	data := discordgo.ModalSubmitInteractionData{CustomID: customID}
+	for inputID, value := range values {
+		data.Components = append(data.Components, &discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{&discordgo.TextInput{CustomID: inputID, Value: value}},
+		})
+	}
+
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type:      discordgo.InteractionModalSubmit,
+		ChannelID: "channel-1",
+		Member:    member,
+		Data:      data,
+	}}
+}
+
+func TestEveryCommandIsHandled(t *testing.T) {
+	bot, session := newTestBot(t)
+	require.NoError(t, bot.initCommands([]string{"king"}))
+
+	names := make([]string, 0, len(session.commands))
+	for _, command := range session.commands {
+		names = append(names, command.Name)
+	}
+	sort.Strings(names)
+	require.NotEmpty(t, names)
+
+	// Without options every handler answers, if only to report the missing ones
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			session.reset()
+			bot.handleInteraction(session, commandInteraction(name, testMember("1", "alice", 0)))
+
+			assert.Len(t, session.responses, 1)
+		})
+	}
+}
+
+func TestResolvedOptions(t *testing.T) {
+	user := &discordgo.User{ID: "42", Username: "alice"}
+	role := &discordgo.Role{ID: "7", Name: "Electronics"}
+	i := commandInteraction(CommandCreateTask, nil, userOption("assignee", user), roleOption("role", role))
+
+	assert.Equal(t, user, resolvedUser(i, i.ApplicationCommandData().Options[0]))
+	assert.Equal(t, role, resolvedRole(i, i.ApplicationCommandData().Options[1]))
+
+	// Options missing from the resolved data only carry the ID
+	i.ApplicationCommandData().Resolved.Users = nil
+	assert.Equal(t, &discordgo.User{ID: "42"}, resolvedUser(i, i.ApplicationCommandData().Options[0]))
+}
+
+func TestUserContentCantPing(t *testing.T) {
+	bot, session := newTestBot(t)
+	member := testMember("1", "alice", 0)
+
+	bot.handleInteraction(session, commandInteraction(CommandCreateTask, member,
+		stringOption("title", "**Urgent** @everyone"),
+		stringOption("description", "ping <@&123> and @here"),
+		userOption("assignee", &discordgo.User{ID: "2", Username: "bob"}),
+	))
+
+	resp := session.response(t)
+	require.NotNil(t, resp.Data.AllowedMentions)
+	assert.Equal(t, []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers}, resp.Data.AllowedMentions.Parse)
+	require.NotEmpty(t, resp.Data.Embeds)
+	assert.Equal(t, "Task #1: \\*\\*Urgent\\*\\* @\u200beveryone", resp.Data.Embeds[0].Title)
+
+	dms := session.messagesTo(dmChannelID("2"))
+	require.Len(t, dms, 1)
+	assert.Contains(t, dms[0].Content, "**\\*\\*Urgent\\*\\* @\u200beveryone**")
+	assert.Contains(t, dms[0].Content, "ping \\<@&123> and @\u200bhere")
+	require.NotNil(t, dms[0].AllowedMentions)
+	assert.Equal(t, []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers}, dms[0].AllowedMentions.Parse)
+}
@@ -0,0 +1,185 @@
+package discord
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// statsPeriod is a period of the stats command, the last days days or all
+// the time when days is zero
+type statsPeriod struct {
+	name string
+	days int
+}
+
+var statsPeriods = []statsPeriod{
+	{name: "week", days: 7},
+	{name: "month", days: 30},
+	{name: "quarter", days: 90},
+	{name: "year", days: 365},
+	{name: "all", days: 0},
+}
+
+const defaultStatsPeriod = "month"
+
+// statsPeriodChoices are the periods of the stats command, named by the
+// stats.period message
+func (b *DiscordBot) statsPeriodChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(statsPeriods))
+	for _, period := range statsPeriods {
+		choices = append(choices, b.choice(period.name, "stats.period", messageData{"Period": period.name}))
+	}
+	return choices
+}
+
+// since returns the start of the period ending at now, or the zero time for
+// all the time
+func (p statsPeriod) since(now time.Time) time.Time {
+	if p.days == 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -p.days)
+}
+
+// durationData is the data of the stats.duration message
+func durationData(d time.Duration) messageData {
+	return messageData{
+		"Days":    int(d.Hours()) / 24,
+		"Hours":   int(d.Hours()) % 24,
+		"Minutes": int(d.Minutes()) % 60,
+	}
+}
+
+func (b *DiscordBot) workloadCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandWorkload {
+		return
+	}
+
+	role := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "role" {
+			role = resolvedRole(i, opt).Name
+		}
+	}
+
+	workload, err := b.db.GetMemberWorkload(role)
+	if err != nil {
+		fmt.Printf("[workload] Failed to get member workload: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "workload.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	unassigned, err := b.db.CountUnassignedTasks(role)
+	if err != nil {
+		fmt.Printf("[workload] Failed to count unassigned tasks: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "workload.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	fmt.Printf("[workload] Retrieved the workload of %d members\n", len(workload))
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: truncate(b.render(i.Locale, "workload.list", messageData{
+				"Role":       role,
+				"Workload":   workload,
+				"Unassigned": unassigned,
+			}), 2000),
+		},
+	})
+}
+
+func (b *DiscordBot) statsCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandStats {
+		return
+	}
+
+	role := ""
+	periodName := defaultStatsPeriod
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "role":
+			role = resolvedRole(i, opt).Name
+		case "period":
+			periodName = opt.StringValue()
+		}
+	}
+
+	var period *statsPeriod
+	for _, p := range statsPeriods {
+		if p.name == periodName {
+			period = &p
+		}
+	}
+	if period == nil {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "stats.invalid-period", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	stats, err := b.db.GetTaskStats(role, period.since(time.Now()))
+	if err != nil {
+		fmt.Printf("[stats] Failed to get task stats: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "stats.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	members := make([]messageData, 0, len(stats.Members))
+	for _, member := range stats.Members {
+		members = append(members, messageData{
+			"DiscordID":             member.DiscordID,
+			"Completed":             member.Completed,
+			"AverageTimeToComplete": durationData(member.AverageTimeToComplete),
+		})
+	}
+
+	fmt.Printf("[stats] Retrieved the stats of role %q for period %s\n", role, period.name)
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: truncate(b.render(i.Locale, "stats.summary", messageData{
+				"Role":                  role,
+				"Period":                period.name,
+				"Created":               stats.Created,
+				"Completed":             stats.Completed,
+				"AverageTimeToComplete": durationData(stats.AverageTimeToComplete),
+				"Started":               stats.Started,
+				"AverageTimeInProgress": durationData(stats.AverageTimeInProgress),
+				"Members":               members,
+			}), 2000),
+		},
+	})
+}
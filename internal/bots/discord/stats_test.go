@@ -0,0 +1,24 @@
+package discord
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadLength(t *testing.T) {
+	bot, session := newTestBot(t)
+	createTestUser(t, bot.db, "111", "author")
+	for i := 0; i < 100; i++ {
+		discordID := fmt.Sprintf("%d", 100000000000000000+i)
+		createTestUser(t, bot.db, discordID, fmt.Sprintf("member%d", i))
+		createTestTask(t, bot.db, "Wire the BMS", testRole.Name, discordID)
+	}
+
+	bot.handleInteraction(session, commandInteraction(CommandWorkload, testMember("1", "alice", 0), roleOption("role", testRole)))
+	content := session.content(t)
+	assert.Contains(t, content, "Open tasks per member")
+	assert.LessOrEqual(t, utf8.RuneCountInString(content), 2000)
+}
@@ -0,0 +1,253 @@
+package discord
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Unsubscribe buttons have the form "subscription-unsubscribe:<scope>:<subscription ID>",
+// the scope telling which listing to show again once unsubscribed
+const (
+	subscriptionScopeChannel = "channel"
+	subscriptionScopeAll     = "all"
+)
+
+// Discord allows at most 5 rows of buttons per message
+const maxUnsubscribeButtons = 5 * maxButtonsPerRow
+
+// subscriptionMatchesBranch reports whether a push to branch is posted for the subscription
+func subscriptionMatchesBranch(subscription db.WebhookSubscription, branch string) bool {
+	if subscription.BranchFilter == "" {
+		return true
+	}
+	matched, err := path.Match(subscription.BranchFilter, branch)
+	return err == nil && matched
+}
+
+func isValidBranchFilter(filter string) bool {
+	_, err := path.Match(filter, "")
+	return err == nil
+}
+
+// missingPostPermissions returns the names of the permissions needed to post
+// in a channel that are not in permissions
+func missingPostPermissions(permissions int64) []string {
+	missing := make([]string, 0)
+	if permissions&discordgo.PermissionViewChannel == 0 {
+		missing = append(missing, "View Channel")
+	}
+	if permissions&discordgo.PermissionSendMessages == 0 {
+		missing = append(missing, "Send Messages")
+	}
+	return missing
+}
+
+// checkBotCanPost returns an error if the bot can't post messages in the channel
+func (b *DiscordBot) checkBotCanPost(s Session, channelID string) error {
+	botUser := b.botUser()
+	if botUser == nil {
+		return fmt.Errorf("bot user not available")
+	}
+
+	permissions, err := s.UserChannelPermissions(botUser.ID, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to get channel permissions: %w", err)
+	}
+
+	if missing := missingPostPermissions(permissions); len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, " and "))
+	}
+	return nil
+}
+
+func unsubscribeButtonCustomID(scope string, subscriptionID uint) string {
+	return fmt.Sprintf("%s:%s:%d", ButtonUnsubscribe, scope, subscriptionID)
+}
+
+// parseUnsubscribeButtonCustomID returns the scope and the subscription ID of an unsubscribe button
+func parseUnsubscribeButtonCustomID(customID string) (string, uint, bool) {
+	parts := strings.Split(customID, ":")
+	if len(parts) != 3 || parts[0] != ButtonUnsubscribe {
+		return "", 0, false
+	}
+
+	if parts[1] != subscriptionScopeChannel && parts[1] != subscriptionScopeAll {
+		return "", 0, false
+	}
+
+	subscriptionID, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || subscriptionID == 0 {
+		return "", 0, false
+	}
+
+	return parts[1], uint(subscriptionID), true
+}
+
+// subscriptionComponents renders an unsubscribe button per subscription,
+// numbered as in the listing
+func (b *DiscordBot) subscriptionComponents(locale discordgo.Locale, subscriptions []db.WebhookSubscription, scope string) []discordgo.MessageComponent {
+	rows := make([]discordgo.MessageComponent, 0)
+	buttons := make([]discordgo.MessageComponent, 0, maxButtonsPerRow)
+
+	for n, subscription := range subscriptions[:min(len(subscriptions), maxUnsubscribeButtons)] {
+		buttons = append(buttons, discordgo.Button{
+			Label:    truncate(b.render(locale, "subscriptions.unsubscribe-button", messageData{"Number": n + 1, "Repository": subscription.Repository.Name}), 80),
+			Style:    discordgo.SecondaryButton,
+			CustomID: unsubscribeButtonCustomID(scope, subscription.ID),
+			Emoji:    &discordgo.ComponentEmoji{Name: "🔕"},
+		})
+		if len(buttons) == maxButtonsPerRow {
+			rows = append(rows, discordgo.ActionsRow{Components: buttons})
+			buttons = make([]discordgo.MessageComponent, 0, maxButtonsPerRow)
+		}
+	}
+	if len(buttons) > 0 {
+		rows = append(rows, discordgo.ActionsRow{Components: buttons})
+	}
+
+	return rows
+}
+
+// subscriptionsResponseData lists the subscriptions of a channel, or all of
+// them, with their unsubscribe buttons
+func (b *DiscordBot) subscriptionsResponseData(locale discordgo.Locale, scope string, channelID string) (*discordgo.InteractionResponseData, error) {
+	var subscriptions []db.WebhookSubscription
+	var err error
+	if scope == subscriptionScopeAll {
+		subscriptions, err = b.db.GetAllWebhookSubscriptions()
+	} else {
+		subscriptions, err = b.db.GetWebhookSubscriptionsByChannel(channelID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordgo.InteractionResponseData{
+		Content:    truncate(b.render(locale, "subscriptions.list", messageData{"All": scope == subscriptionScopeAll, "Subscriptions": subscriptions}), 2000),
+		Components: b.subscriptionComponents(locale, subscriptions, scope),
+		Flags:      discordgo.MessageFlagsEphemeral,
+	}, nil
+}
+
+func (b *DiscordBot) allSubscriptionsCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandAllSubscriptions {
+		return
+	}
+
+	if !isAdmin(i) {
+		fmt.Printf("[all-subscriptions] User is not an administrator\n")
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	respData, err := b.subscriptionsResponseData(i.Locale, subscriptionScopeAll, i.ChannelID)
+	if err != nil {
+		fmt.Printf("[all-subscriptions] Failed to get webhook subscriptions: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "subscriptions.all-failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: respData,
+	})
+}
+
+func (b *DiscordBot) unsubscribeButtonComponent(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+	if !strings.HasPrefix(customID, ButtonUnsubscribe+":") {
+		return
+	}
+
+	scope, subscriptionID, ok := parseUnsubscribeButtonCustomID(customID)
+	if !ok {
+		fmt.Printf("[unsubscribe] Invalid unsubscribe button: %s\n", customID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "subscriptions.invalid-button", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	subscription, err := b.db.GetWebhookSubscriptionByID(subscriptionID)
+	if err != nil {
+		fmt.Printf("[unsubscribe] Failed to get webhook subscription %d: %v\n", subscriptionID, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "subscriptions.not-found", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	// The buttons of /all-subscriptions list the subscriptions of any channel
+	if !b.canManageSubscriptionsIn(s, i, subscription.ChannelID) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "common.cannot-manage-subscriptions", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+
+	subscription, err = b.db.DeleteWebhookSubscriptionByID(subscriptionID)
+	if err != nil {
+		fmt.Printf("[unsubscribe] Failed to delete webhook subscription %d: %v\n", subscriptionID, err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "subscriptions.not-found", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	fmt.Printf("[unsubscribe] Channel %s unsubscribed from push webhook for repository %s\n", subscription.ChannelID, subscription.Repository.Name)
+
+	respData, err := b.subscriptionsResponseData(i.Locale, scope, i.ChannelID)
+	if err != nil {
+		fmt.Printf("[unsubscribe] Failed to get webhook subscriptions: %v\n", err)
+		respData = &discordgo.InteractionResponseData{Components: []discordgo.MessageComponent{}}
+	}
+	respData.Content = truncate(b.render(i.Locale, "subscriptions.unsubscribed", messageData{
+		"Repository":    subscription.Repository.Name,
+		"Subscriptions": respData.Content,
+	}), 2000)
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: respData,
+	})
+}
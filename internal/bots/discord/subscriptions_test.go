@@ -0,0 +1,134 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionMatchesBranch(t *testing.T) {
+	tests := []struct {
+		filter string
+		branch string
+		want   bool
+	}{
+		{"", "main", true},
+		{"main", "main", true},
+		{"main", "develop", false},
+		{"release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"feature-*", "feature-telemetry", true},
+		{"[", "main", false},
+	}
+
+	for _, tt := range tests {
+		subscription := db.WebhookSubscription{BranchFilter: tt.filter}
+		assert.Equal(t, tt.want, subscriptionMatchesBranch(subscription, tt.branch), "filter %q, branch %q", tt.filter, tt.branch)
+	}
+}
+
+func TestParseUnsubscribeButtonCustomID(t *testing.T) {
+	scope, subscriptionID, ok := parseUnsubscribeButtonCustomID(unsubscribeButtonCustomID(subscriptionScopeAll, 42))
+	assert.True(t, ok)
+	assert.Equal(t, subscriptionScopeAll, scope)
+	assert.Equal(t, uint(42), subscriptionID)
+
+	for _, customID := range []string{
+		"subscription-unsubscribe:channel",
+		"subscription-unsubscribe:guild:1",
+		"subscription-unsubscribe:channel:0",
+		"task-card:start:1",
+	} {
+		_, _, ok := parseUnsubscribeButtonCustomID(customID)
+		assert.False(t, ok, customID)
+	}
+}
+
+func TestSubscriptionComponents(t *testing.T) {
+	subscriptions := make([]db.WebhookSubscription, 30)
+	for n := range subscriptions {
+		subscriptions[n].ID = uint(n + 1)
+		subscriptions[n].Repository.Name = "repo"
+	}
+
+	rows := (&DiscordBot{}).subscriptionComponents("", subscriptions, subscriptionScopeChannel)
+	assert.Len(t, rows, 5)
+
+	assert.Empty(t, (&DiscordBot{}).subscriptionComponents("", nil, subscriptionScopeChannel))
+}
+
+func TestMissingPostPermissions(t *testing.T) {
+	assert.Empty(t, missingPostPermissions(discordgo.PermissionViewChannel|discordgo.PermissionSendMessages))
+	assert.Equal(t, []string{"Send Messages"}, missingPostPermissions(discordgo.PermissionViewChannel))
+	assert.Equal(t, []string{"View Channel", "Send Messages"}, missingPostPermissions(0))
+}
+
+func TestCanManageSubscriptions(t *testing.T) {
+	bot := &DiscordBot{options: BotOptions{AdminRoleID: "role-admin"}}
+	interaction := func(member *discordgo.Member) *discordgo.InteractionCreate {
+		return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{Member: member}}
+	}
+
+	assert.True(t, bot.canManageSubscriptions(interaction(&discordgo.Member{Permissions: discordgo.PermissionManageChannels})))
+	assert.True(t, bot.canManageSubscriptions(interaction(&discordgo.Member{Permissions: discordgo.PermissionAdministrator})))
+	assert.True(t, bot.canManageSubscriptions(interaction(&discordgo.Member{Roles: []string{"role-other", "role-admin"}})))
+	assert.False(t, bot.canManageSubscriptions(interaction(&discordgo.Member{Roles: []string{"role-other"}})))
+	assert.False(t, bot.canManageSubscriptions(interaction(nil)))
+
+	// Without an admin role configured, no role grants access
+	bot.options.AdminRoleID = ""
+	assert.False(t, bot.canManageSubscriptions(interaction(&discordgo.Member{Roles: []string{""}})))
+}
+
+func TestUnsubscribeButton(t *testing.T) {
+	bot, session := newTestBot(t)
+	require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "king"}))
+	require.NoError(t, bot.db.CreateRepository(&db.Repository{Name: "telemetry"}))
+	subscription, err := bot.db.CreateWebhookSubscription("king", "channel-1")
+	require.NoError(t, err)
+	_, err = bot.db.CreateWebhookSubscription("telemetry", "channel-2")
+	require.NoError(t, err)
+
+	// Only administrators can list the subscriptions of every channel
+	bot.handleInteraction(session, commandInteraction(CommandAllSubscriptions, testMember("1", "alice", discordgo.PermissionManageChannels)))
+	assert.Contains(t, session.content(t), "Only administrators")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandAllSubscriptions, testMember("9", "root", discordgo.PermissionAdministrator)))
+	resp := session.response(t)
+	assert.Contains(t, resp.Data.Content, "`king`")
+	assert.Contains(t, resp.Data.Content, "`telemetry`")
+	assert.NotEmpty(t, resp.Data.Components)
+
+	customID := unsubscribeButtonCustomID(subscriptionScopeAll, subscription.ID)
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(customID, testMember("1", "alice", 0)))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	bot.handleInteraction(session, componentInteraction(customID, testMember("1", "alice", discordgo.PermissionManageChannels)))
+	resp = session.response(t)
+	assert.Equal(t, discordgo.InteractionResponseUpdateMessage, resp.Type)
+	assert.Contains(t, resp.Data.Content, "Channel unsubscribed from push webhook for repository `king`")
+
+	subscriptions, err := bot.db.GetAllWebhookSubscriptions()
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, "channel-2", subscriptions[0].ChannelID)
+
+	// The button of a subscription of another channel needs the permission there
+	customID = unsubscribeButtonCustomID(subscriptionScopeAll, subscriptions[0].ID)
+	session.reset()
+	session.setMemberPermissions("1", "channel-2", discordgo.PermissionViewChannel)
+	bot.handleInteraction(session, componentInteraction(customID, testMember("1", "alice", discordgo.PermissionManageChannels)))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	session.setMemberPermissions("1", "channel-2", discordgo.PermissionManageChannels)
+	bot.handleInteraction(session, componentInteraction(customID, testMember("1", "alice", discordgo.PermissionManageChannels)))
+	assert.Contains(t, session.response(t).Data.Content, "Channel unsubscribed from push webhook for repository `telemetry`")
+}
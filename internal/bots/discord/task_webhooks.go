@@ -0,0 +1,348 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Task events are posted to the task webhooks as JSON, signed like GitHub
+// signs its webhooks: the X-King-Signature header is "sha256=" followed by
+// the hex HMAC-SHA256 of the body, keyed with the secret of the webhook.
+// Deliveries are queued and retried like the outbound messages.
+const (
+	taskWebhookEventHeader     = "X-King-Event"
+	taskWebhookDeliveryHeader  = "X-King-Delivery"
+	taskWebhookSignatureHeader = "X-King-Signature"
+
+	taskWebhookTimeout = 10 * time.Second
+)
+
+var taskWebhookClient = &http.Client{Timeout: taskWebhookTimeout}
+
+// taskEventPayload is the body of a task webhook delivery
+type taskEventPayload struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Task       apiTask   `json:"task"`
+}
+
+// emitTaskEvent queues an event of a task, loaded with its author and
+// assignees, for the task webhooks
+func (b *DiscordBot) emitTaskEvent(event string, task *db.Task) {
+	payload, err := json.Marshal(taskEventPayload{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Task:       newAPITask(*task),
+	})
+	if err != nil {
+		fmt.Printf("[task-webhooks] Failed to encode %s event of task %d: %v\n", event, task.ID, err)
+		return
+	}
+
+	queued, err := b.db.EnqueueTaskEvent(event, payload)
+	if err != nil {
+		fmt.Printf("[task-webhooks] Failed to queue %s event of task %d: %v\n", event, task.ID, err)
+		return
+	}
+	if queued == 0 {
+		return
+	}
+
+	select {
+	case b.taskWebhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// signTaskWebhookPayload returns the signature of a delivery
+func signTaskWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// runTaskWebhookQueue delivers the queued task events until the context is
+// cancelled
+func (b *DiscordBot) runTaskWebhookQueue(ctx context.Context) {
+	runQueue(ctx, outboundPollInterval, b.taskWebhookWake, b.processTaskWebhookDeliveries)
+}
+
+// processTaskWebhookDeliveries attempts the deliveries due at now and returns
+// how many were handled, i.e. delivered or rescheduled
+func (b *DiscordBot) processTaskWebhookDeliveries(ctx context.Context, now time.Time) int {
+	deliveries, err := b.db.GetDueTaskWebhookDeliveries(now, outboundBatchSize)
+	if err != nil {
+		fmt.Printf("[task-webhooks] Failed to get due deliveries: %v\n", err)
+		return 0
+	}
+
+	workers := b.options.OutboundWorkers
+	if workers <= 0 {
+		workers = defaultOutboundWorkers
+	}
+
+	var handled atomic.Int64
+	sem := make(chan struct{}, workers)
+	wg := sync.WaitGroup{}
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery db.TaskWebhookDelivery) {
+			defer wg.Done()
+			if b.deliverTaskWebhook(ctx, &delivery) {
+				handled.Add(1)
+			}
+			<-sem
+		}(delivery)
+	}
+	wg.Wait()
+
+	return int(handled.Load())
+}
+
+// postTaskWebhook posts a delivery and returns the status of the response
+func postTaskWebhook(ctx context.Context, delivery *db.TaskWebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "king-task-webhooks")
+	req.Header.Set(taskWebhookEventHeader, delivery.Event)
+	req.Header.Set(taskWebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(taskWebhookSignatureHeader, signTaskWebhookPayload(delivery.Webhook.Secret, []byte(delivery.Payload)))
+
+	resp, err := taskWebhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// deliverTaskWebhook attempts a delivery and reports whether its outcome was
+// recorded
+func (b *DiscordBot) deliverTaskWebhook(ctx context.Context, delivery *db.TaskWebhookDelivery) bool {
+	statusCode, err := postTaskWebhook(ctx, delivery)
+	if err == nil {
+		if err := b.db.MarkTaskWebhookDeliverySent(delivery, time.Now()); err != nil {
+			fmt.Printf("[task-webhooks] Failed to mark delivery %d as sent: %v\n", delivery.ID, err)
+			return false
+		}
+		return true
+	}
+
+	switch {
+	case statusCode >= 400 && statusCode < 500 && statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests:
+		// The endpoint rejected the event, it won't accept it on retry
+		fmt.Printf("[task-webhooks] Delivery %d rejected by %s: %v\n", delivery.ID, delivery.Webhook.URL, err)
+		err = b.db.FailTaskWebhookDelivery(delivery, err.Error())
+
+	case delivery.Attempts+1 >= outboundMaxAttempts:
+		fmt.Printf("[task-webhooks] Giving up on delivery %d after %d attempts: %v\n", delivery.ID, delivery.Attempts+1, err)
+		err = b.db.FailTaskWebhookDelivery(delivery, err.Error())
+
+	default:
+		backoff := outboundBackoff(delivery.Attempts + 1)
+		fmt.Printf("[task-webhooks] Failed to deliver %d to %s, retrying in %s: %v\n", delivery.ID, delivery.Webhook.URL, backoff, err)
+		err = b.db.RetryTaskWebhookDelivery(delivery, time.Now().Add(backoff), err.Error())
+	}
+
+	if err != nil {
+		fmt.Printf("[task-webhooks] Failed to update delivery %d: %v\n", delivery.ID, err)
+		return false
+	}
+	return true
+}
+
+// parseTaskWebhookURL checks that a task webhook URL is an absolute HTTP(S) URL
+func parseTaskWebhookURL(value string) (string, bool) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return u.String(), true
+}
+
+func newTaskWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (b *DiscordBot) addTaskWebhookCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandAddTaskWebhook {
+		return
+	}
+
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if !isAdmin(i) || i.Member.User == nil {
+		respond(b.render(i.Locale, "task-webhook.admin-only", nil))
+		return
+	}
+
+	rawURL := ""
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "url" {
+			rawURL = opt.StringValue()
+		}
+	}
+
+	webhookURL, ok := parseTaskWebhookURL(rawURL)
+	if !ok {
+		respond(b.render(i.Locale, "add-task-webhook.invalid-url", messageData{"URL": rawURL}))
+		return
+	}
+
+	user, err := b.getOrCreateUser(i.Member.User.ID, i.Member.User.Username)
+	if err != nil {
+		fmt.Printf("[add-task-webhook] Failed to get or create user: %v\n", err)
+		respond(b.render(i.Locale, "task-webhook.failed", nil))
+		return
+	}
+
+	secret, err := newTaskWebhookSecret()
+	if err == nil {
+		_, err = b.db.CreateTaskWebhook(webhookURL, secret, user.ID)
+	}
+	if errors.Is(err, db.ErrTaskWebhookURLTaken) {
+		respond(b.render(i.Locale, "add-task-webhook.url-taken", messageData{"URL": webhookURL}))
+		return
+	}
+	if err != nil {
+		fmt.Printf("[add-task-webhook] Failed to create task webhook: %v\n", err)
+		respond(b.render(i.Locale, "task-webhook.failed", nil))
+		return
+	}
+
+	fmt.Printf("[add-task-webhook] Task webhook %s added by %s\n", webhookURL, i.Member.User.Username)
+	respond(b.render(i.Locale, "add-task-webhook.success", messageData{
+		"URL":             webhookURL,
+		"Secret":          secret,
+		"SignatureHeader": taskWebhookSignatureHeader,
+		"EventHeader":     taskWebhookEventHeader,
+	}))
+}
+
+func (b *DiscordBot) listTaskWebhooksCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandListTaskWebhooks {
+		return
+	}
+
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if !isAdmin(i) {
+		respond(b.render(i.Locale, "task-webhook.admin-only", nil))
+		return
+	}
+
+	webhooks, err := b.db.GetTaskWebhooks()
+	if err != nil {
+		fmt.Printf("[list-task-webhooks] Failed to get task webhooks: %v\n", err)
+		respond(b.render(i.Locale, "task-webhook.failed", nil))
+		return
+	}
+
+	if len(webhooks) == 0 {
+		respond(b.render(i.Locale, "list-task-webhooks.none", nil))
+		return
+	}
+	respond(b.render(i.Locale, "list-task-webhooks.list", messageData{"Webhooks": webhooks}))
+}
+
+func (b *DiscordBot) removeTaskWebhookCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandRemoveTaskWebhook {
+		return
+	}
+
+	respond := func(content string) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if !isAdmin(i) {
+		respond(b.render(i.Locale, "task-webhook.admin-only", nil))
+		return
+	}
+
+	var webhookID int64
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "id" {
+			webhookID = opt.IntValue()
+		}
+	}
+
+	err := b.db.DeleteTaskWebhook(uint(webhookID))
+	switch {
+	case errors.Is(err, db.ErrTaskWebhookNotFound):
+		respond(b.render(i.Locale, "remove-task-webhook.not-found", messageData{"ID": webhookID}))
+	case err != nil:
+		fmt.Printf("[remove-task-webhook] Failed to delete task webhook %d: %v\n", webhookID, err)
+		respond(b.render(i.Locale, "task-webhook.failed", nil))
+	default:
+		fmt.Printf("[remove-task-webhook] Task webhook %d removed\n", webhookID)
+		respond(b.render(i.Locale, "remove-task-webhook.success", messageData{"ID": webhookID}))
+	}
+}
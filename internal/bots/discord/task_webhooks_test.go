@@ -0,0 +1,165 @@
+package discord
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taskWebhookReceiver is an endpoint recording the task events posted to it
+type taskWebhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newTaskWebhookReceiver(t *testing.T) (*taskWebhookReceiver, string) {
+	receiver := &taskWebhookReceiver{status: http.StatusNoContent}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		w.WriteHeader(receiver.status)
+	}))
+	t.Cleanup(server.Close)
+	return receiver, server.URL + "/hook"
+}
+
+func (r *taskWebhookReceiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func TestTaskWebhookDelivery(t *testing.T) {
+	bot, session := newTestBot(t)
+	receiver, url := newTaskWebhookReceiver(t)
+	const secret = "0123456789abcdef"
+	_, err := bot.db.CreateTaskWebhook(url, secret, 1)
+	require.NoError(t, err)
+
+	member := testMember("1", "alice", 0)
+	bot.handleInteraction(session, commandInteraction(CommandCreateTask, member, stringOption("title", "Wire the BMS")))
+	require.Equal(t, 1, bot.processTaskWebhookDeliveries(context.Background(), time.Now()))
+
+	require.Len(t, receiver.requests, 1)
+	req, body := receiver.requests[0], receiver.bodies[0]
+	assert.Equal(t, db.TASK_EVENT_CREATED, req.Header.Get(taskWebhookEventHeader))
+	assert.NotEmpty(t, req.Header.Get(taskWebhookDeliveryHeader))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	// The signature is checked the way a receiver would
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.Header.Get(taskWebhookSignatureHeader))
+
+	payload := taskEventPayload{}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, db.TASK_EVENT_CREATED, payload.Event)
+	assert.Equal(t, "Wire the BMS", payload.Task.Title)
+	assert.Equal(t, "alice", payload.Task.Author.Username)
+
+	webhooks, err := bot.db.GetTaskWebhooks()
+	require.NoError(t, err)
+	assert.NotNil(t, webhooks[0].LastDeliveredAt)
+
+	t.Run("events", func(t *testing.T) {
+		receiver.requests, receiver.bodies = nil, nil
+		task, err := bot.db.GetTaskByID(payload.Task.ID)
+		require.NoError(t, err)
+
+		bot.handleInteraction(session, commandInteraction(CommandClaimTask, member, intOption("id", int(task.ID))))
+		bot.handleInteraction(session, commandInteraction(CommandUpdateTaskStatus, member,
+			intOption("task-id", int(task.ID)),
+			stringOption("status", db.TASK_COMPLETED),
+		))
+		bot.handleInteraction(session, componentInteraction(fmt.Sprintf("%s:%d", ButtonConfirmDeleteTask, task.ID), member))
+
+		// The events of a webhook are delivered one at a time, in order
+		for range 3 {
+			require.Equal(t, 1, bot.processTaskWebhookDeliveries(context.Background(), time.Now()))
+		}
+		events := make([]string, 0)
+		for _, req := range receiver.requests {
+			events = append(events, req.Header.Get(taskWebhookEventHeader))
+		}
+		assert.Equal(t, []string{db.TASK_EVENT_ASSIGNED, db.TASK_EVENT_STATUS_CHANGED, db.TASK_EVENT_DELETED}, events)
+		assert.Contains(t, string(receiver.bodies[1]), `"status":"Completed"`)
+	})
+
+	t.Run("retried on server errors", func(t *testing.T) {
+		receiver.setStatus(http.StatusBadGateway)
+		bot.emitTaskEvent(db.TASK_EVENT_CREATED, &db.Task{Title: "Order bolts"})
+		require.Equal(t, 1, bot.processTaskWebhookDeliveries(context.Background(), time.Now()))
+
+		// Not due again until the backoff expires
+		assert.Zero(t, bot.processTaskWebhookDeliveries(context.Background(), time.Now()))
+		webhooks, err := bot.db.GetTaskWebhooks()
+		require.NoError(t, err)
+		assert.Contains(t, webhooks[0].LastError, "502")
+
+		receiver.setStatus(http.StatusOK)
+		require.Equal(t, 1, bot.processTaskWebhookDeliveries(context.Background(), time.Now().Add(outboundBaseBackoff)))
+		webhooks, err = bot.db.GetTaskWebhooks()
+		require.NoError(t, err)
+		assert.Empty(t, webhooks[0].LastError)
+	})
+
+	t.Run("rejected on client errors", func(t *testing.T) {
+		receiver.setStatus(http.StatusGone)
+		bot.emitTaskEvent(db.TASK_EVENT_CREATED, &db.Task{Title: "Order nuts"})
+		require.Equal(t, 1, bot.processTaskWebhookDeliveries(context.Background(), time.Now()))
+		assert.Zero(t, bot.processTaskWebhookDeliveries(context.Background(), time.Now().Add(outboundMaxBackoff)))
+	})
+}
+
+func TestTaskWebhookCommands(t *testing.T) {
+	bot, session := newTestBot(t)
+	admin := testMember("9", "root", discordgo.PermissionAdministrator)
+
+	bot.handleInteraction(session, commandInteraction(CommandAddTaskWebhook, admin, stringOption("url", "https://sheets.example.com/hook")))
+	content := session.content(t)
+	assert.Contains(t, content, "Task webhook added")
+
+	webhooks, err := bot.db.GetTaskWebhooks()
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	// The secret is shown once, to configure the endpoint
+	assert.Len(t, webhooks[0].Secret, 64)
+	assert.Contains(t, content, webhooks[0].Secret)
+
+	for _, url := range []string{"sheets.example.com/hook", "ftp://sheets.example.com", "https://"} {
+		session.reset()
+		bot.handleInteraction(session, commandInteraction(CommandAddTaskWebhook, admin, stringOption("url", url)))
+		assert.Contains(t, session.content(t), "Invalid URL")
+	}
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandAddTaskWebhook, testMember("1", "alice", 0), stringOption("url", "https://ci.example.com/hook")))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandRemoveTaskWebhook, admin, intOption("id", int(webhooks[0].ID))))
+	assert.Contains(t, session.content(t), "Task webhook removed")
+	webhooks, err = bot.db.GetTaskWebhooks()
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+}
@@ -1,28 +1,37 @@
 package discord
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/Formula-SAE/discord/internal/db"
 	"github.com/Formula-SAE/discord/internal/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-github/v74/github"
 )
 
 type Author struct {
 	Name string `json:"name"`
+
+	// Username is the GitHub login of a commit author, when GitHub knows it
+	Username string `json:"username"`
 }
 
 type PushEvent struct {
 	Repository struct {
-		Name string `json:"name"`
+		Name          string `json:"name"`
+		DefaultBranch string `json:"default_branch"`
 	} `json:"repository"`
 	Ref     string `json:"ref"`
 	Pusher  Author `json:"pusher"`
 	Forced  bool   `json:"forced"`
 	Commits []struct {
+		ID        string `json:"id"`
 		Message   string `json:"message"`
 		Timestamp string `json:"timestamp"`
 		URL       string `json:"url"`
@@ -32,10 +41,28 @@ type PushEvent struct {
 	Created bool `json:"created"`
 }
 
+// GitHub webhook deliveries are acknowledged as soon as they are recorded and
+// processed in the background, so that slow Discord sends don't make GitHub
+// time out and redeliver them.
+const (
+	webhookPollInterval = 30 * time.Second
+	webhookBatchSize    = 50
+
+	// Processed deliveries are kept for inspection for a month
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+)
+
 func (b *DiscordBot) onPushWebhook(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
+	// Deliveries complete tasks and change them, so only GitHub can send them
+	if b.options.WebhookSecret == "" {
+		log.Printf("Rejecting webhook delivery %s: no webhook secret configured", r.Header.Get("X-GitHub-Delivery"))
+		http.Error(w, "webhook secret not configured", http.StatusUnauthorized)
+		return
+	}
+	body, err := github.ValidatePayload(r, []byte(b.options.WebhookSecret))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		log.Printf("Rejecting webhook delivery %s: %v", r.Header.Get("X-GitHub-Delivery"), err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
 		return
 	}
 
@@ -44,77 +71,178 @@ func (b *DiscordBot) onPushWebhook(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	log.Printf("Received push event:\n%+v", payload)
 
-	var msg string
-	if payload.Deleted {
-		msg = formatBranchDeleted(payload)
-	} else if len(payload.Commits) == 0 {
-		msg = formatBranchCreated(payload)
-	} else {
-		msg = formatStandardPush(payload)
+	deliveryID := r.Header.Get("X-GitHub-Delivery")
+	if deliveryID == "" {
+		http.Error(w, "missing X-GitHub-Delivery header", http.StatusBadRequest)
+		return
 	}
 
-	log.Printf("Formatted push event: %s", msg)
+	event := r.Header.Get("X-GitHub-Event")
+	if event == "" {
+		event = "push"
+	}
 
-	subscriptions, err := b.db.GetWebhookSubscriptionsByRepository(payload.Repository.Name)
+	_, created, err := b.db.RecordWebhookDelivery(deliveryID, event, payload.Repository.Name, body)
 	if err != nil {
-		log.Printf("Error getting webhook subscriptions: %v", err)
+		log.Printf("Error recording webhook delivery %s: %v", deliveryID, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if !created {
+		log.Printf("Ignoring duplicate webhook delivery %s", deliveryID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	log.Printf("Received %s event for repository %s (delivery %s)", event, payload.Repository.Name, deliveryID)
 
-	for _, subscription := range subscriptions {
-		_, err := b.session.ChannelMessageSend(subscription.ChannelID, msg)
+	select {
+	case b.webhookWake <- struct{}{}:
+	default:
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// runWebhookProcessor processes the recorded deliveries until the context is
+// cancelled. Deliveries left pending by a restart are processed on start.
+func (b *DiscordBot) runWebhookProcessor(ctx context.Context) {
+	// Interrupted deliveries may have run some of their side effects, so
+	// they are left for the admins to check in /webhook-deliveries
+	failed, err := b.db.FailInterruptedWebhookDeliveries()
+	if err != nil {
+		log.Printf("Error failing interrupted webhook deliveries: %v", err)
+	} else if failed > 0 {
+		log.Printf("Marked %d webhook deliveries interrupted by a restart as failed", failed)
+	}
+
+	runQueue(ctx, webhookPollInterval, b.webhookWake, func(ctx context.Context, now time.Time) int {
+		return b.processWebhookDeliveries()
+	})
+}
+
+// processWebhookDeliveries processes the pending deliveries in the order they
+// were received and returns how many were handled. Each delivery is claimed
+// before being processed: one whose outcome can't be recorded stays claimed
+// instead of running its side effects, like notifications, again, until the
+// next start marks it as failed.
+func (b *DiscordBot) processWebhookDeliveries() int {
+	deliveries, err := b.db.GetPendingWebhookDeliveries(webhookBatchSize)
+	if err != nil {
+		log.Printf("Error getting pending webhook deliveries: %v", err)
+		return 0
+	}
+
+	handled := 0
+	for _, delivery := range deliveries {
+		claimed, err := b.db.ClaimWebhookDelivery(delivery.ID)
 		if err != nil {
-			log.Printf("Error sending message to channel %s: %v", subscription.ChannelID, err)
+			log.Printf("Error claiming webhook delivery %s: %v", delivery.DeliveryID, err)
+			break
+		}
+		if !claimed {
+			continue
+		}
+
+		status, outcome := b.processWebhookDelivery(delivery)
+		log.Printf("Webhook delivery %s %s: %s", delivery.DeliveryID, status, outcome)
+
+		if err := b.db.CompleteWebhookDelivery(delivery.ID, status, outcome); err != nil {
+			log.Printf("Error completing webhook delivery %s: %v", delivery.DeliveryID, err)
 		}
+		handled++
 	}
 
-	w.WriteHeader(http.StatusOK)
+	return handled
 }
 
-func formatStandardPush(payload *PushEvent) string {
-	msg := fmt.Sprintf("🚀 %s: %s\n", utils.Bold("New push in repository"), utils.InlineCode(payload.Repository.Name))
-	branchLine := fmt.Sprintf("🌿 %s: %s", utils.Bold("Branch"), utils.InlineCode(getBranchName(payload.Ref)))
-	if payload.Created {
-		branchLine += fmt.Sprintf(" (🆕 %s)\n", utils.Italic("NEW BRANCH"))
-	} else {
-		branchLine += "\n"
-	}
-	msg += branchLine
-	msg += fmt.Sprintf("👤 %s: %s\n", utils.Bold("Author"), utils.InlineCode(payload.Pusher.Name))
-	if payload.Forced {
-		msg += fmt.Sprintf("⚠️ %s\n", utils.Bold("FORCED PUSH"))
-	}
-	msg += "\n"
-
-	msg += fmt.Sprintf("📝 %s:\n", utils.Bold("Commits"))
-	for i, commit := range payload.Commits {
-		msg += getCommitMessage(
-			commit.Message,
-			commit.URL,
-			commit.Author.Name,
-		)
-		if i < len(payload.Commits)-1 {
-			msg += "\n"
+// processWebhookDelivery queues the messages of a delivery for its subscribed
+// channels and returns the resulting status and a description of the outcome
+func (b *DiscordBot) processWebhookDelivery(delivery db.WebhookDelivery) (string, string) {
+	switch delivery.Event {
+	case "push":
+	case "pull_request", "pull_request_review":
+		return b.processPullRequestDelivery(delivery)
+	case "issues":
+		return b.processIssuesDelivery(delivery)
+	default:
+		return db.WEBHOOK_DELIVERY_IGNORED, fmt.Sprintf("unsupported event %s", delivery.Event)
+	}
+
+	payload := &PushEvent{}
+	if err := json.Unmarshal([]byte(delivery.Payload), payload); err != nil {
+		return db.WEBHOOK_DELIVERY_FAILED, fmt.Sprintf("invalid payload: %v", err)
+	}
+
+	discordIDs := b.getPushDiscordIDs(payload)
+
+	// Tasks are linked even if no channel is subscribed to the repository
+	b.linkCommitsToTasks(payload, discordIDs)
+
+	subscriptions, err := b.db.GetWebhookSubscriptionsByRepository(payload.Repository.Name)
+	if err != nil {
+		return db.WEBHOOK_DELIVERY_FAILED, fmt.Sprintf("failed to get subscriptions: %v", err)
+	}
+
+	branch := getBranchName(payload.Ref)
+	matching := make([]db.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if subscriptionMatchesBranch(subscription, branch) {
+			matching = append(matching, subscription)
 		}
 	}
-	return msg
-}
+	subscriptions = matching
+
+	if len(subscriptions) == 0 {
+		return db.WEBHOOK_DELIVERY_IGNORED, "no subscribed channels"
+	}
 
-func formatBranchCreated(payload *PushEvent) string {
-	msg := fmt.Sprintf("🚀 %s: %s\n", utils.Bold("New branch created"), utils.InlineCode(payload.Repository.Name))
-	msg += fmt.Sprintf("🌿 %s: %s\n", utils.Bold("Branch"), utils.InlineCode(getBranchName(payload.Ref)))
-	msg += fmt.Sprintf("👤 %s: %s\n", utils.Bold("Author"), utils.InlineCode(payload.Pusher.Name))
-	return msg
+	msg := b.formatPushEvent(payload, discordIDs)
+	queued := 0
+	for _, subscription := range subscriptions {
+		err := b.enqueueMessage(subscription.ChannelID, msg, &subscription.ID)
+		if err != nil {
+			log.Printf("Error queueing message for channel %s: %v", subscription.ChannelID, err)
+			continue
+		}
+		queued++
+	}
+
+	if queued < len(subscriptions) {
+		return db.WEBHOOK_DELIVERY_FAILED, fmt.Sprintf("queued for %d of %d channel(s)", queued, len(subscriptions))
+	}
+	return db.WEBHOOK_DELIVERY_PROCESSED, fmt.Sprintf("queued for %d channel(s)", queued)
 }
 
-func formatBranchDeleted(payload *PushEvent) string {
-	msg := fmt.Sprintf("🗑️ %s: %s\n", utils.Bold("Branch deleted"), utils.InlineCode(payload.Repository.Name))
-	msg += fmt.Sprintf("🌿 %s: %s\n", utils.Bold("Branch"), utils.InlineCode(getBranchName(payload.Ref)))
-	msg += fmt.Sprintf("👤 %s: %s\n", utils.Bold("Author"), utils.InlineCode(payload.Pusher.Name))
-	return msg
+// formatPushEvent renders a push, mentioning the members whose GitHub login
+// is a key of discordIDs
+func (b *DiscordBot) formatPushEvent(payload *PushEvent, discordIDs map[string]string) string {
+	data := messageData{
+		"Repository": payload.Repository.Name,
+		"Branch":     getBranchName(payload.Ref),
+		"Author":     formatGitHubUser(payload.Pusher.Name, utils.EscapedInlineCode(payload.Pusher.Name), discordIDs),
+	}
+
+	if payload.Deleted {
+		return b.render(b.options.DefaultLocale, "push.branch-deleted", data)
+	} else if len(payload.Commits) == 0 {
+		return b.render(b.options.DefaultLocale, "push.branch-created", data)
+	}
+
+	commits := make([]messageData, 0, len(payload.Commits))
+	for _, commit := range payload.Commits {
+		title, body, _ := strings.Cut(commit.Message, "\n\n")
+		commits = append(commits, messageData{
+			"Title":  title,
+			"URL":    commit.URL,
+			"Author": formatGitHubUser(commit.Author.Username, utils.EscapeMarkdown(commit.Author.Name), discordIDs),
+			"Body":   body,
+		})
+	}
+	data["Created"] = payload.Created
+	data["Forced"] = payload.Forced
+	data["Commits"] = commits
+
+	return b.render(b.options.DefaultLocale, "push.commits", data)
 }
 
 func getBranchName(ref string) string {
@@ -124,19 +252,74 @@ func getBranchName(ref string) string {
 	return ref
 }
 
-func getCommitMessage(commit string, link string, author string) string {
-	parts := strings.SplitN(commit, "\n\n", 2)
-	if len(parts) == 0 {
-		return ""
+func (b *DiscordBot) getWebhookDeliveriesCommand(s Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
+	if i.ApplicationCommandData().Name != CommandWebhookDeliveries {
+		return
+	}
+
+	if !isAdmin(i) {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "webhook-deliveries.admin-only", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
 	}
-	title := parts[0]
 
-	msg := fmt.Sprintf("  🔸 %s\n", utils.Link(title, link))
-	msg += fmt.Sprintf("     ✍️ %s", author)
+	deliveries, err := b.db.GetRecentWebhookDeliveries(15)
+	if err != nil {
+		fmt.Printf("[webhook-deliveries] Failed to get webhook deliveries: %v\n", err)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "webhook-deliveries.failed", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
 
-	if len(parts) == 2 {
-		msg += "\n\n" + parts[1]
+	if len(deliveries) == 0 {
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: b.render(i.Locale, "webhook-deliveries.none", nil),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
 	}
 
-	return msg
+	respContent := b.render(i.Locale, "webhook-deliveries.list", messageData{"Deliveries": deliveries})
+
+	fmt.Printf("[webhook-deliveries] Retrieved %d deliveries\n", len(deliveries))
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: respContent,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// getDeliveryStatusIcon returns the emoji icon for a webhook delivery status
+func getDeliveryStatusIcon(status string) string {
+	switch status {
+	case db.WEBHOOK_DELIVERY_RECEIVED, db.WEBHOOK_DELIVERY_PROCESSING:
+		return "⏳"
+	case db.WEBHOOK_DELIVERY_PROCESSED:
+		return "✅"
+	case db.WEBHOOK_DELIVERY_IGNORED:
+		return "➖"
+	case db.WEBHOOK_DELIVERY_FAILED:
+		return "❌"
+	default:
+		return "❓"
+	}
 }
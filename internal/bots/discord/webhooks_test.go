@@ -0,0 +1,90 @@
+package discord
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Formula-SAE/discord/internal/db"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-github/v74/github"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveriesCommand(t *testing.T) {
+	bot, session := newTestBot(t)
+	admin := testMember("1", "alice", discordgo.PermissionAdministrator)
+
+	bot.handleInteraction(session, commandInteraction(CommandWebhookDeliveries, admin))
+	assert.Contains(t, session.content(t), "No webhook deliveries received yet")
+
+	delivery, _, err := bot.db.RecordWebhookDelivery("72d3162e-cc78-11e3-81ab-4c9367dc0958", "push", "king", []byte("{}"))
+	require.NoError(t, err)
+	require.NoError(t, bot.db.CompleteWebhookDelivery(delivery.ID, db.WEBHOOK_DELIVERY_FAILED, "invalid payload"))
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandWebhookDeliveries, testMember("2", "bob", 0)))
+	assert.Contains(t, session.content(t), "Access denied")
+
+	session.reset()
+	bot.handleInteraction(session, commandInteraction(CommandWebhookDeliveries, admin))
+	content := session.content(t)
+	assert.Contains(t, content, "push on `king`")
+	assert.Contains(t, content, "invalid payload")
+}
+
+// pushRequest returns a push webhook delivery, signed with secret unless it
+// is empty
+func pushRequest(deliveryID string, body string, secret string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/push", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-GitHub-Delivery", deliveryID)
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(body))
+		req.Header.Set(github.SHA256SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return req
+}
+
+func TestPushWebhookSignature(t *testing.T) {
+	bot, _ := newTestBot(t)
+	bot.router = mux.NewRouter()
+	bot.initWebhookHandlers()
+	bot.options.WebhookSecret = "s3cret"
+	const body = `{"repository":{"name":"king"},"ref":"refs/heads/main"}`
+
+	serve := func(req *http.Request) int {
+		rec := httptest.NewRecorder()
+		bot.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(pushRequest("unsigned", body, "")))
+	assert.Equal(t, http.StatusUnauthorized, serve(pushRequest("forged", body, "wrong")))
+	tampered := pushRequest("tampered", body, "s3cret")
+	tampered.Body = io.NopCloser(strings.NewReader(strings.Replace(body, "main", "dev", 1)))
+	assert.Equal(t, http.StatusUnauthorized, serve(tampered))
+
+	deliveries, err := bot.db.GetRecentWebhookDeliveries(10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	assert.Equal(t, http.StatusAccepted, serve(pushRequest("signed", body, "s3cret")))
+	deliveries, err = bot.db.GetRecentWebhookDeliveries(10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "signed", deliveries[0].DeliveryID)
+
+	// Without a secret, no delivery can be verified
+	bot.options.WebhookSecret = ""
+	assert.Equal(t, http.StatusUnauthorized, serve(pushRequest("no-secret", body, "")))
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAPITokenNameTaken = errors.New("an API token with this name already exists")
+	ErrAPITokenNotFound  = errors.New("API token not found")
+)
+
+// apiTokenPrefixLength is how much of a token is kept to recognize it
+const apiTokenPrefixLength = 12
+
+func hashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateAPIToken stores the hash of a new token under a name. Write tokens
+// can also create and update tasks.
+func (d *DB) CreateAPIToken(name string, token string, createdByID uint, write bool) (*APIToken, error) {
+	if name == "" || len(token) <= apiTokenPrefixLength {
+		return nil, fmt.Errorf("name and token cannot be empty")
+	}
+
+	var taken int64
+	if err := d.db.Model(&APIToken{}).Where("name = ?", name).Count(&taken).Error; err != nil {
+		return nil, fmt.Errorf("failed to check API token name: %w", err)
+	}
+	if taken > 0 {
+		return nil, ErrAPITokenNameTaken
+	}
+
+	apiToken := &APIToken{
+		Name:        name,
+		TokenHash:   hashAPIToken(token),
+		Prefix:      token[:apiTokenPrefixLength],
+		Write:       write,
+		CreatedByID: createdByID,
+	}
+	if err := d.db.Create(apiToken).Error; err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return apiToken, nil
+}
+
+// AuthenticateAPIToken returns the stored token matching a token presented
+// to the API, with the user who created it, and records that it was used at
+// now
+func (d *DB) AuthenticateAPIToken(token string, now time.Time) (*APIToken, error) {
+	apiToken := &APIToken{}
+
+	result := d.db.Preload("CreatedBy").Where("token_hash = ?", hashAPIToken(token)).Limit(1).Find(apiToken)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAPITokenNotFound
+	}
+
+	if err := d.db.Model(apiToken).UpdateColumn("last_used_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to update API token: %w", err)
+	}
+	apiToken.LastUsedAt = &now
+
+	return apiToken, nil
+}
+
+// GetAPITokens returns every token, with the user who created it, by name
+func (d *DB) GetAPITokens() ([]APIToken, error) {
+	tokens := make([]APIToken, 0)
+
+	if err := d.db.Preload("CreatedBy").Order("name").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// DeleteAPIToken revokes the token with a name
+func (d *DB) DeleteAPIToken(name string) error {
+	result := d.db.Unscoped().Where("name = ?", name).Delete(&APIToken{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete API token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
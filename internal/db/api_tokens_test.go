@@ -0,0 +1,71 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITokens(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	admin := &User{Username: "Admin", DiscordID: "admin123"}
+	require.NoError(t, db.CreateUser(admin))
+
+	const token = "king_0123456789abcdef0123456789abcdef"
+	created, err := db.CreateAPIToken("grafana", token, admin.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "king_0123456", created.Prefix)
+
+	// The token itself is not stored
+	stored := &APIToken{}
+	require.NoError(t, gormDB.First(stored, created.ID).Error)
+	assert.NotContains(t, stored.TokenHash, "0123456789abcdef")
+	assert.Len(t, stored.TokenHash, 64)
+
+	_, err = db.CreateAPIToken("grafana", "king_another-token-of-grafana", admin.ID, false)
+	assert.ErrorIs(t, err, ErrAPITokenNameTaken)
+
+	t.Run("authenticate", func(t *testing.T) {
+		now := time.Now().Truncate(time.Second)
+		authenticated, err := db.AuthenticateAPIToken(token, now)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, authenticated.ID)
+		assert.Equal(t, "Admin", authenticated.CreatedBy.Username)
+		assert.False(t, authenticated.Write)
+
+		require.NoError(t, gormDB.First(stored, created.ID).Error)
+		require.NotNil(t, stored.LastUsedAt)
+		assert.True(t, now.Equal(*stored.LastUsedAt))
+
+		_, err = db.AuthenticateAPIToken("king_wrong", now)
+		assert.ErrorIs(t, err, ErrAPITokenNotFound)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		_, err := db.CreateAPIToken("dashboard", "king_fedcba9876543210fedcba9876543210", admin.ID, true)
+		require.NoError(t, err)
+
+		tokens, err := db.GetAPITokens()
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, "dashboard", tokens[0].Name)
+		assert.True(t, tokens[0].Write)
+		assert.Equal(t, "Admin", tokens[1].CreatedBy.Username)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, db.DeleteAPIToken("grafana"))
+		assert.ErrorIs(t, db.DeleteAPIToken("grafana"), ErrAPITokenNotFound)
+
+		_, err := db.AuthenticateAPIToken(token, time.Now())
+		assert.ErrorIs(t, err, ErrAPITokenNotFound)
+
+		// The name can be reused once revoked
+		_, err = db.CreateAPIToken("grafana", token, admin.ID, false)
+		assert.NoError(t, err)
+	})
+}
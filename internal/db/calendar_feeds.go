@@ -0,0 +1,62 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// CreateCalendarFeed stores the hash of the token of a feed of a user, of
+// the tasks of a role or, with an empty role, of the tasks assigned to the
+// user. It replaces the previous feed of the user for the same tasks, so
+// that its link stops working.
+func (d *DB) CreateCalendarFeed(userID uint, role string, token string) (*CalendarFeed, error) {
+	if token == "" {
+		return nil, fmt.Errorf("token cannot be empty")
+	}
+
+	feed := &CalendarFeed{
+		TokenHash: hashAPIToken(token),
+		UserID:    userID,
+		Role:      role,
+	}
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ? AND role = ?", userID, role).Delete(&CalendarFeed{}).Error; err != nil {
+			return fmt.Errorf("failed to delete previous calendar feed: %w", err)
+		}
+		if err := tx.Create(feed).Error; err != nil {
+			return fmt.Errorf("failed to create calendar feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// GetCalendarFeed returns the feed with a token, with its user, and records
+// that it was fetched at now
+func (d *DB) GetCalendarFeed(token string, now time.Time) (*CalendarFeed, error) {
+	feed := &CalendarFeed{}
+
+	result := d.db.Preload("User").Where("token_hash = ?", hashAPIToken(token)).Limit(1).Find(feed)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get calendar feed: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	if err := d.db.Model(feed).UpdateColumn("last_fetched_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to update calendar feed: %w", err)
+	}
+	feed.LastFetchedAt = &now
+
+	return feed, nil
+}
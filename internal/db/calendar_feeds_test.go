@@ -0,0 +1,60 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarFeeds(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	alice := &User{Username: "Alice", DiscordID: "alice456"}
+	require.NoError(t, db.CreateUser(alice))
+
+	const token = "0123456789abcdef0123456789abcdef"
+	created, err := db.CreateCalendarFeed(alice.ID, "", token)
+	require.NoError(t, err)
+
+	// The token itself is not stored
+	stored := &CalendarFeed{}
+	require.NoError(t, gormDB.First(stored, created.ID).Error)
+	assert.NotContains(t, stored.TokenHash, "0123456789abcdef")
+
+	now := time.Now().Truncate(time.Second)
+	feed, err := db.GetCalendarFeed(token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", feed.User.Username)
+	assert.Empty(t, feed.Role)
+	require.NoError(t, gormDB.First(stored, created.ID).Error)
+	require.NotNil(t, stored.LastFetchedAt)
+	assert.True(t, now.Equal(*stored.LastFetchedAt))
+
+	_, err = db.GetCalendarFeed("wrong", now)
+	assert.ErrorIs(t, err, ErrCalendarFeedNotFound)
+
+	t.Run("role feeds are separate", func(t *testing.T) {
+		_, err := db.CreateCalendarFeed(alice.ID, "developer", "fedcba9876543210fedcba9876543210")
+		require.NoError(t, err)
+
+		feed, err := db.GetCalendarFeed("fedcba9876543210fedcba9876543210", now)
+		require.NoError(t, err)
+		assert.Equal(t, "developer", feed.Role)
+
+		_, err = db.GetCalendarFeed(token, now)
+		assert.NoError(t, err)
+	})
+
+	t.Run("a new link replaces the previous one", func(t *testing.T) {
+		_, err := db.CreateCalendarFeed(alice.ID, "", "00112233445566778899aabbccddeeff")
+		require.NoError(t, err)
+
+		_, err = db.GetCalendarFeed(token, now)
+		assert.ErrorIs(t, err, ErrCalendarFeedNotFound)
+		_, err = db.GetCalendarFeed("00112233445566778899aabbccddeeff", now)
+		assert.NoError(t, err)
+	})
+}
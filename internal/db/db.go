@@ -1,19 +1,40 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrTaskAlreadyAssigned    = errors.New("task is already assigned")
+	ErrTaskCompleted          = errors.New("task is already completed")
+	ErrInProgressLimitReached = errors.New("in-progress task limit reached")
+)
+
 type UserRetrieveOptions struct {
 	WithAssignedTasks bool
 	WithCreatedTasks  bool
 }
 
+type TaskUpdateOptions struct {
+	Title        *string
+	Description  *string
+	DueDate      *time.Time
+	ClearDueDate bool
+}
+
 type DB struct {
 	db *gorm.DB
+
+	// claimMu serializes task claims so that the limit check and the
+	// assignment happen atomically
+	claimMu sync.Mutex
 }
 
 func NewDB(db *gorm.DB) *DB {
@@ -140,6 +161,9 @@ func (d *DB) GetTasksByRole(role string) ([]Task, error) {
 	return tasks, nil
 }
 
+// unassignedCondition selects the tasks nobody is assigned to
+const unassignedCondition = "NOT EXISTS (SELECT 1 FROM task_assignments WHERE task_assignments.task_id = tasks.id)"
+
 func (d *DB) GetUnassignedTasksByRole(role string) ([]Task, error) {
 	tasks := make([]Task, 0)
 	if role == "" {
@@ -147,18 +171,12 @@ func (d *DB) GetUnassignedTasksByRole(role string) ([]Task, error) {
 	}
 	if err := d.db.Preload("Author").Preload("AssignedUsers").
 		Where("role = ? AND status != ?", role, TASK_COMPLETED).
+		Where(unassignedCondition).
 		Find(&tasks).Error; err != nil {
 		return nil, err
 	}
 
-	unassignedTasks := make([]Task, 0)
-	for _, task := range tasks {
-		if len(task.AssignedUsers) == 0 {
-			unassignedTasks = append(unassignedTasks, task)
-		}
-	}
-
-	return unassignedTasks, nil
+	return tasks, nil
 }
 
 func (d *DB) AssignTask(taskID uint, userID uint) error {
@@ -177,6 +195,66 @@ func (d *DB) AssignTask(taskID uint, userID uint) error {
 	return nil
 }
 
+// ClaimTask assigns an unassigned task to the user and starts it. If
+// maxInProgress is positive, the claim fails once the user already has that
+// many tasks in progress.
+func (d *DB) ClaimTask(taskID uint, userID uint, maxInProgress int) (*Task, error) {
+	d.claimMu.Lock()
+	defer d.claimMu.Unlock()
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		task := &Task{}
+		if err := tx.First(task, taskID).Error; err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+		if task.Status == TASK_COMPLETED {
+			return ErrTaskCompleted
+		}
+
+		if maxInProgress > 0 {
+			var inProgress int64
+			if err := tx.Model(&Task{}).
+				Joins("JOIN task_assignments ON task_assignments.task_id = tasks.id").
+				Where("task_assignments.user_id = ? AND tasks.status = ?", userID, TASK_IN_PROGRESS).
+				Count(&inProgress).Error; err != nil {
+				return fmt.Errorf("failed to count in-progress tasks: %w", err)
+			}
+			if inProgress >= int64(maxInProgress) {
+				return ErrInProgressLimitReached
+			}
+		}
+
+		// The existence check and the insert are a single statement, so two
+		// concurrent claims can't both succeed
+		result := tx.Exec(
+			"INSERT INTO task_assignments (task_id, user_id) SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM task_assignments WHERE task_id = ?)",
+			taskID, userID, taskID,
+		)
+		if result.Error != nil {
+			return fmt.Errorf("failed to claim task: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskAlreadyAssigned
+		}
+
+		if task.Status == TASK_NOT_STARTED {
+			if err := tx.Model(task).Update("status", TASK_IN_PROGRESS).Error; err != nil {
+				return fmt.Errorf("failed to start task: %w", err)
+			}
+			if err := recordStatusChange(tx, task.ID, TASK_NOT_STARTED, TASK_IN_PROGRESS); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return d.GetTaskByID(taskID)
+}
+
 func (d *DB) UpdateTaskStatus(taskID uint, status string) (*Task, error) {
 	validStatuses := []string{TASK_NOT_STARTED, TASK_IN_PROGRESS, TASK_COMPLETED}
 	isValid := slices.Contains(validStatuses, status)
@@ -190,25 +268,334 @@ func (d *DB) UpdateTaskStatus(taskID uint, status string) (*Task, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
+	if status == TASK_COMPLETED && task.Status != TASK_COMPLETED {
+		completedAt := time.Now()
+		task.CompletedAt = &completedAt
+	} else if status != TASK_COMPLETED {
+		task.CompletedAt = nil
+	}
+	previousStatus := task.Status
 	task.Status = status
 
-	if err := d.db.Save(task).Error; err != nil {
-		return nil, fmt.Errorf("failed to update task status: %w", err)
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(task).Error; err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+		return recordStatusChange(tx, task.ID, previousStatus, status)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return task, nil
 }
 
+// recordStatusChange records the transition of a task to a new status, if
+// the status actually changed
+func recordStatusChange(tx *gorm.DB, taskID uint, from string, to string) error {
+	if from == to {
+		return nil
+	}
+
+	change := &TaskStatusChange{TaskID: taskID, FromStatus: from, ToStatus: to}
+	if err := tx.Create(change).Error; err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+	return nil
+}
+
+// UpdateTask applies the non-nil fields of options to the task and records a
+// TaskChange for every field whose value actually changed.
+func (d *DB) UpdateTask(taskID uint, editorID uint, options *TaskUpdateOptions) (*Task, []TaskChange, error) {
+	if options == nil {
+		return nil, nil, fmt.Errorf("update options cannot be nil")
+	}
+	if options.Title != nil && strings.TrimSpace(*options.Title) == "" {
+		return nil, nil, fmt.Errorf("title cannot be empty")
+	}
+
+	task, err := d.GetTaskByID(taskID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	changes := make([]TaskChange, 0, 2)
+	if options.Title != nil && *options.Title != task.Title {
+		changes = append(changes, TaskChange{
+			Field:    TASK_FIELD_TITLE,
+			OldValue: task.Title,
+			NewValue: *options.Title,
+			TaskID:   task.ID,
+			AuthorID: editorID,
+		})
+		task.Title = *options.Title
+	}
+	if options.Description != nil && *options.Description != task.Description {
+		changes = append(changes, TaskChange{
+			Field:    TASK_FIELD_DESCRIPTION,
+			OldValue: task.Description,
+			NewValue: *options.Description,
+			TaskID:   task.ID,
+			AuthorID: editorID,
+		})
+		task.Description = *options.Description
+	}
+
+	if options.DueDate != nil || options.ClearDueDate {
+		var dueDate *time.Time
+		if !options.ClearDueDate {
+			dueDate = options.DueDate
+		}
+		if formatDueDate(dueDate) != formatDueDate(task.DueDate) {
+			changes = append(changes, TaskChange{
+				Field:    TASK_FIELD_DUE_DATE,
+				OldValue: formatDueDate(task.DueDate),
+				NewValue: formatDueDate(dueDate),
+				TaskID:   task.ID,
+				AuthorID: editorID,
+			})
+			task.DueDate = dueDate
+		}
+	}
+
+	if len(changes) == 0 {
+		return task, changes, nil
+	}
+
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(task).Select("Title", "Description", "DueDate").Updates(task).Error; err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+		if err := tx.Create(&changes).Error; err != nil {
+			return fmt.Errorf("failed to record task changes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return task, changes, nil
+}
+
+// TaskPatch is a set of changes made to a task at once. The fields left nil
+// or zero are not changed.
+type TaskPatch struct {
+	TaskUpdateOptions
+
+	Status *string
+	// AssigneeID is a user added to the assignees of the task
+	AssigneeID uint
+}
+
+// PatchTask edits the fields of a task, changes its status and adds an
+// assignee in a single transaction, so that either every change is made or
+// none is. It returns the task, with its author and assignees, and the
+// changes of its fields.
+func (d *DB) PatchTask(taskID uint, editorID uint, patch *TaskPatch) (*Task, []TaskChange, error) {
+	if patch == nil {
+		return nil, nil, fmt.Errorf("patch cannot be nil")
+	}
+
+	var changes []TaskChange
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		txDB := NewDB(tx)
+
+		var err error
+		if _, changes, err = txDB.UpdateTask(taskID, editorID, &patch.TaskUpdateOptions); err != nil {
+			return err
+		}
+		if patch.Status != nil {
+			if _, err := txDB.UpdateTaskStatus(taskID, *patch.Status); err != nil {
+				return err
+			}
+		}
+		if patch.AssigneeID != 0 {
+			if err := txDB.AssignTask(taskID, patch.AssigneeID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task, err := d.GetTaskByID(taskID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	return task, changes, nil
+}
+
+func (d *DB) GetTaskChanges(taskID uint) ([]TaskChange, error) {
+	changes := make([]TaskChange, 0)
+
+	if err := d.db.Preload("Author").
+		Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Find(&changes).Error; err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (d *DB) AddTaskComment(taskID uint, authorID uint, text string) (*TaskComment, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("comment cannot be empty")
+	}
+
+	if err := d.db.First(&Task{}, taskID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	comment := &TaskComment{
+		Text:     text,
+		TaskID:   taskID,
+		AuthorID: authorID,
+	}
+	if err := d.db.Create(comment).Error; err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// AddTaskCommitComment adds a comment linking a commit to a task. A commit is
+// linked to a task only once, even if it is pushed again, e.g. to another
+// branch: in that case false is returned and no comment is added.
+func (d *DB) AddTaskCommitComment(taskID uint, authorID uint, commitSHA string, text string) (*TaskComment, bool, error) {
+	if commitSHA == "" {
+		return nil, false, fmt.Errorf("commit SHA cannot be empty")
+	}
+
+	var linked int64
+	if err := d.db.Model(&TaskComment{}).
+		Where("task_id = ? AND commit_sha = ?", taskID, commitSHA).
+		Count(&linked).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to check linked commits: %w", err)
+	}
+	if linked > 0 {
+		return nil, false, nil
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return nil, false, fmt.Errorf("comment cannot be empty")
+	}
+
+	if err := d.db.First(&Task{}, taskID).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	comment := &TaskComment{
+		Text:      text,
+		CommitSHA: commitSHA,
+		TaskID:    taskID,
+		AuthorID:  authorID,
+	}
+	if err := d.db.Create(comment).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	return comment, true, nil
+}
+
+func (d *DB) GetTaskComments(taskID uint) ([]TaskComment, error) {
+	comments := make([]TaskComment, 0)
+
+	if err := d.db.Preload("Author").
+		Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
 func (d *DB) DeleteTask(taskID uint) error {
 	return d.db.Model(&Task{}).Where("id = ?", taskID).Delete(&Task{}).Error
 }
 
+func (d *DB) GetDeletedTasks() ([]Task, error) {
+	tasks := make([]Task, 0)
+
+	if err := d.db.Unscoped().Preload("Author").Preload("AssignedUsers").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (d *DB) RestoreTask(taskID uint) (*Task, error) {
+	result := d.db.Unscoped().Model(&Task{}).
+		Where("id = ? AND deleted_at IS NOT NULL", taskID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to restore task: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("task %d is not in the trash", taskID)
+	}
+
+	return d.GetTaskByID(taskID)
+}
+
+// PurgeDeletedTasks permanently removes the tasks soft-deleted before the given
+// time, together with their assignments, comments and change history.
+func (d *DB) PurgeDeletedTasks(deletedBefore time.Time) (int64, error) {
+	var purged int64
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		taskIDs := make([]uint, 0)
+		if err := tx.Unscoped().Model(&Task{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Pluck("id", &taskIDs).Error; err != nil {
+			return fmt.Errorf("failed to get deleted tasks: %w", err)
+		}
+		if len(taskIDs) == 0 {
+			return nil
+		}
+
+		if err := tx.Exec("DELETE FROM task_assignments WHERE task_id IN ?", taskIDs).Error; err != nil {
+			return fmt.Errorf("failed to delete task assignments: %w", err)
+		}
+		if err := tx.Unscoped().Where("task_id IN ?", taskIDs).Delete(&TaskComment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete task comments: %w", err)
+		}
+		if err := tx.Unscoped().Where("task_id IN ?", taskIDs).Delete(&TaskChange{}).Error; err != nil {
+			return fmt.Errorf("failed to delete task changes: %w", err)
+		}
+		if err := tx.Unscoped().Where("task_id IN ?", taskIDs).Delete(&TaskStatusChange{}).Error; err != nil {
+			return fmt.Errorf("failed to delete task status changes: %w", err)
+		}
+
+		result := tx.Unscoped().Where("id IN ?", taskIDs).Delete(&Task{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to purge tasks: %w", result.Error)
+		}
+		purged = result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
 func (d *DB) GetWebhookSubscriptionsByRepository(repoName string) ([]WebhookSubscription, error) {
 	subscriptions := make([]WebhookSubscription, 0)
 
 	if err := d.db.Preload("Repository").
 		Joins("JOIN repositories ON repositories.id = webhook_subscriptions.repository_id").
-		Where("repositories.name = ?", repoName).
+		Where("repositories.name = ? AND webhook_subscriptions.disabled = ?", repoName, false).
 		Find(&subscriptions).Error; err != nil {
 		return nil, err
 	}
@@ -216,6 +603,50 @@ func (d *DB) GetWebhookSubscriptionsByRepository(repoName string) ([]WebhookSubs
 	return subscriptions, nil
 }
 
+// GetWebhookSubscriptionsByChannel returns the subscriptions of a channel,
+// including the disabled ones
+func (d *DB) GetWebhookSubscriptionsByChannel(channelID string) ([]WebhookSubscription, error) {
+	subscriptions := make([]WebhookSubscription, 0)
+
+	if err := d.db.Preload("Repository").
+		Joins("JOIN repositories ON repositories.id = webhook_subscriptions.repository_id").
+		Where("webhook_subscriptions.channel_id = ?", channelID).
+		Order("repositories.name").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// GetAllWebhookSubscriptions returns every subscription, grouped by channel
+func (d *DB) GetAllWebhookSubscriptions() ([]WebhookSubscription, error) {
+	subscriptions := make([]WebhookSubscription, 0)
+
+	if err := d.db.Preload("Repository").
+		Joins("JOIN repositories ON repositories.id = webhook_subscriptions.repository_id").
+		Order("webhook_subscriptions.channel_id, repositories.name").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (d *DB) SetWebhookSubscriptionBranchFilter(repoName string, channelID string, branchFilter string) error {
+	result := d.db.Model(&WebhookSubscription{}).
+		Where("channel_id = ? AND repository_id IN (?)", channelID, d.db.Model(&Repository{}).Select("id").Where("name = ?", repoName)).
+		Update("branch_filter", branchFilter)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set branch filter: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("channel %s is not subscribed to repository %s", channelID, repoName)
+	}
+
+	return nil
+}
+
 func (d *DB) CreateWebhookSubscription(repoName string, channelID string) (*WebhookSubscription, error) {
 	repo := &Repository{}
 
@@ -223,6 +654,21 @@ func (d *DB) CreateWebhookSubscription(repoName string, channelID string) (*Webh
 		return nil, fmt.Errorf("failed to get repository: %w", err)
 	}
 
+	// Subscribing again re-enables a subscription disabled after a delivery failure
+	disabled := &WebhookSubscription{}
+	result := d.db.Where("repository_id = ? AND channel_id = ? AND disabled = ?", repo.ID, channelID, true).Limit(1).Find(disabled)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		if err := d.db.Model(disabled).Updates(map[string]any{"disabled": false, "disabled_reason": ""}).Error; err != nil {
+			return nil, fmt.Errorf("failed to enable webhook subscription: %w", err)
+		}
+		disabled.Disabled = false
+		disabled.DisabledReason = ""
+		return disabled, nil
+	}
+
 	subscription := &WebhookSubscription{
 		RepositoryID: repo.ID,
 		ChannelID:    channelID,
@@ -247,6 +693,32 @@ func (d *DB) DeleteWebhookSubscription(repoName string, channelID string) error
 	return d.db.Unscoped().Delete(webhookSubscription).Error
 }
 
+// GetWebhookSubscriptionByID returns a subscription, with its repository
+func (d *DB) GetWebhookSubscriptionByID(subscriptionID uint) (*WebhookSubscription, error) {
+	subscription := &WebhookSubscription{}
+
+	if err := d.db.Preload("Repository").First(subscription, subscriptionID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// DeleteWebhookSubscriptionByID deletes a subscription and returns it
+func (d *DB) DeleteWebhookSubscriptionByID(subscriptionID uint) (*WebhookSubscription, error) {
+	subscription := &WebhookSubscription{}
+
+	if err := d.db.Preload("Repository").First(subscription, subscriptionID).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	if err := d.db.Unscoped().Delete(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
 func (d *DB) CreateRepository(repo *Repository) error {
 	return d.db.Create(repo).Error
 }
@@ -260,3 +732,10 @@ func (d *DB) GetAllRepositories() ([]Repository, error) {
 
 	return repositories, nil
 }
+
+func formatDueDate(dueDate *time.Time) string {
+	if dueDate == nil {
+		return ""
+	}
+	return dueDate.Format(DueDateFormat)
+}
@@ -2,7 +2,9 @@ package db
 
 import (
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -3172,3 +3174,1076 @@ func TestDeleteTask(t *testing.T) {
 		assert.NotContains(t, remainingIDs, tasks[0].ID)
 	})
 }
+
+func TestUpdateTask(t *testing.T) {
+	t.Run("successful title and description update", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		assignee := &User{
+			Username:  "Assignee",
+			DiscordID: "assignee456",
+		}
+		err = db.CreateUser(assignee)
+		require.NoError(t, err)
+
+		// Create task
+		task := &Task{
+			Title:       "Old Title",
+			Description: "Old Description",
+			Role:        "developer",
+		}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "assignee456")
+		require.NoError(t, err)
+
+		// Update title and description
+		title := "New Title"
+		description := "New Description"
+		updatedTask, changes, err := db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			Title:       &title,
+			Description: &description,
+		})
+		assert.NoError(t, err)
+		require.NotNil(t, updatedTask)
+		assert.Equal(t, title, updatedTask.Title)
+		assert.Equal(t, description, updatedTask.Description)
+		assert.Len(t, updatedTask.AssignedUsers, 1)
+
+		// Verify the recorded changes
+		require.Len(t, changes, 2)
+		assert.Equal(t, TASK_FIELD_TITLE, changes[0].Field)
+		assert.Equal(t, "Old Title", changes[0].OldValue)
+		assert.Equal(t, title, changes[0].NewValue)
+		assert.Equal(t, TASK_FIELD_DESCRIPTION, changes[1].Field)
+		assert.Equal(t, "Old Description", changes[1].OldValue)
+		assert.Equal(t, description, changes[1].NewValue)
+
+		// Verify the update in database
+		retrievedTask, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, title, retrievedTask.Title)
+		assert.Equal(t, description, retrievedTask.Description)
+		assert.Equal(t, "developer", retrievedTask.Role)
+		assert.Equal(t, TASK_NOT_STARTED, retrievedTask.Status)
+
+		// Verify the changes were persisted
+		storedChanges, err := db.GetTaskChanges(task.ID)
+		require.NoError(t, err)
+		require.Len(t, storedChanges, 2)
+		for _, change := range storedChanges {
+			assert.Equal(t, author.ID, change.AuthorID)
+			assert.Equal(t, author.Username, change.Author.Username)
+		}
+	})
+
+	t.Run("only title update", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create user
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		// Create task
+		task := &Task{
+			Title:       "Old Title",
+			Description: "Description",
+		}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		// Update only the title
+		title := "New Title"
+		updatedTask, changes, err := db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			Title: &title,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, title, updatedTask.Title)
+		assert.Equal(t, "Description", updatedTask.Description)
+		require.Len(t, changes, 1)
+		assert.Equal(t, TASK_FIELD_TITLE, changes[0].Field)
+	})
+
+	t.Run("clearing the description", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create user
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		// Create task
+		task := &Task{
+			Title:       "Title",
+			Description: "Description",
+		}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		// Clear the description
+		description := ""
+		_, changes, err := db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			Description: &description,
+		})
+		assert.NoError(t, err)
+		require.Len(t, changes, 1)
+
+		// Verify the update in database
+		retrievedTask, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Empty(t, retrievedTask.Description)
+	})
+
+	t.Run("unchanged values record no changes", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create user
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		// Create task
+		task := &Task{
+			Title:       "Title",
+			Description: "Description",
+		}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		// Submit the same values
+		title := "Title"
+		description := "Description"
+		updatedTask, changes, err := db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			Title:       &title,
+			Description: &description,
+		})
+		assert.NoError(t, err)
+		assert.NotNil(t, updatedTask)
+		assert.Empty(t, changes)
+
+		storedChanges, err := db.GetTaskChanges(task.ID)
+		require.NoError(t, err)
+		assert.Empty(t, storedChanges)
+	})
+
+	t.Run("empty title should return error", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create user
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		// Create task
+		task := &Task{
+			Title: "Title",
+		}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		// Try to set a blank title
+		title := "   "
+		updatedTask, changes, err := db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			Title: &title,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, updatedTask)
+		assert.Nil(t, changes)
+
+		// Verify the title was not changed
+		retrievedTask, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Title", retrievedTask.Title)
+	})
+
+	t.Run("nil options should return error", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		updatedTask, changes, err := db.UpdateTask(1, 1, nil)
+		assert.Error(t, err)
+		assert.Nil(t, updatedTask)
+		assert.Nil(t, changes)
+	})
+
+	t.Run("non-existent task should return error", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		title := "Title"
+		updatedTask, changes, err := db.UpdateTask(999, 1, &TaskUpdateOptions{
+			Title: &title,
+		})
+		assert.Error(t, err)
+		assert.Nil(t, updatedTask)
+		assert.Nil(t, changes)
+	})
+}
+
+func TestPatchTask(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	author := &User{Username: "Author", DiscordID: "author123"}
+	require.NoError(t, db.CreateUser(author))
+	assignee := &User{Username: "Assignee", DiscordID: "assignee456"}
+	require.NoError(t, db.CreateUser(assignee))
+
+	task := &Task{Title: "Old Title", Role: "developer"}
+	require.NoError(t, db.CreateTaskWithUserDiscordID(task, "author123", ""))
+
+	t.Run("every change is made", func(t *testing.T) {
+		title := "New Title"
+		status := TASK_IN_PROGRESS
+		patched, changes, err := db.PatchTask(task.ID, author.ID, &TaskPatch{
+			TaskUpdateOptions: TaskUpdateOptions{Title: &title},
+			Status:            &status,
+			AssigneeID:        assignee.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, TASK_FIELD_TITLE, changes[0].Field)
+		assert.Equal(t, "New Title", patched.Title)
+		assert.Equal(t, TASK_IN_PROGRESS, patched.Status)
+		require.Len(t, patched.AssignedUsers, 1)
+		assert.Equal(t, "Assignee", patched.AssignedUsers[0].Username)
+	})
+
+	t.Run("no change is made if one fails", func(t *testing.T) {
+		title := "Newer Title"
+		status := TASK_COMPLETED
+		_, _, err := db.PatchTask(task.ID, author.ID, &TaskPatch{
+			TaskUpdateOptions: TaskUpdateOptions{Title: &title},
+			Status:            &status,
+			AssigneeID:        9999,
+		})
+		require.Error(t, err)
+
+		stored, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "New Title", stored.Title)
+		assert.Equal(t, TASK_IN_PROGRESS, stored.Status)
+		changes, err := db.GetTaskChanges(task.ID)
+		require.NoError(t, err)
+		assert.Len(t, changes, 1)
+	})
+}
+
+func TestGetDeletedTasks(t *testing.T) {
+	t.Run("returns only soft-deleted tasks", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create user
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		// Create tasks
+		keptTask := &Task{Title: "Kept Task"}
+		err = db.CreateTaskWithUserDiscordID(keptTask, "author123", "")
+		require.NoError(t, err)
+
+		deletedTask := &Task{Title: "Deleted Task"}
+		err = db.CreateTaskWithUserDiscordID(deletedTask, "author123", "")
+		require.NoError(t, err)
+
+		// Soft delete one task
+		err = db.DeleteTask(deletedTask.ID)
+		require.NoError(t, err)
+
+		tasks, err := db.GetDeletedTasks()
+		assert.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, deletedTask.ID, tasks[0].ID)
+		assert.Equal(t, "Deleted Task", tasks[0].Title)
+		assert.True(t, tasks[0].DeletedAt.Valid)
+		assert.Equal(t, author.Username, tasks[0].Author.Username)
+	})
+
+	t.Run("empty trash", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		tasks, err := db.GetDeletedTasks()
+		assert.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+}
+
+func TestRestoreTask(t *testing.T) {
+	t.Run("successful restore keeps ID and assignees", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		assignee := &User{
+			Username:  "Assignee",
+			DiscordID: "assignee456",
+		}
+		err = db.CreateUser(assignee)
+		require.NoError(t, err)
+
+		// Create and delete a task
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "assignee456")
+		require.NoError(t, err)
+		err = db.DeleteTask(task.ID)
+		require.NoError(t, err)
+
+		_, err = db.GetTaskByID(task.ID)
+		require.Error(t, err)
+
+		// Restore the task
+		restoredTask, err := db.RestoreTask(task.ID)
+		assert.NoError(t, err)
+		require.NotNil(t, restoredTask)
+		assert.Equal(t, task.ID, restoredTask.ID)
+		assert.False(t, restoredTask.DeletedAt.Valid)
+		require.Len(t, restoredTask.AssignedUsers, 1)
+		assert.Equal(t, assignee.ID, restoredTask.AssignedUsers[0].ID)
+
+		// Verify the task is no longer in the trash
+		deletedTasks, err := db.GetDeletedTasks()
+		require.NoError(t, err)
+		assert.Empty(t, deletedTasks)
+	})
+
+	t.Run("restoring a task that is not deleted fails", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		restoredTask, err := db.RestoreTask(task.ID)
+		assert.Error(t, err)
+		assert.Nil(t, restoredTask)
+	})
+
+	t.Run("restoring a non-existent task fails", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		restoredTask, err := db.RestoreTask(999)
+		assert.Error(t, err)
+		assert.Nil(t, restoredTask)
+	})
+}
+
+func TestPurgeDeletedTasks(t *testing.T) {
+	t.Run("purges tasks deleted before the cutoff", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		assignee := &User{
+			Username:  "Assignee",
+			DiscordID: "assignee456",
+		}
+		err = db.CreateUser(assignee)
+		require.NoError(t, err)
+
+		// Create tasks
+		oldTask := &Task{Title: "Old Deleted Task"}
+		err = db.CreateTaskWithUserDiscordID(oldTask, "author123", "assignee456")
+		require.NoError(t, err)
+		err = gormDB.Create(&TaskComment{Text: "Comment", TaskID: oldTask.ID}).Error
+		require.NoError(t, err)
+
+		recentTask := &Task{Title: "Recently Deleted Task"}
+		err = db.CreateTaskWithUserDiscordID(recentTask, "author123", "")
+		require.NoError(t, err)
+
+		activeTask := &Task{Title: "Active Task"}
+		err = db.CreateTaskWithUserDiscordID(activeTask, "author123", "")
+		require.NoError(t, err)
+
+		// Delete two tasks, backdating one of them
+		err = db.DeleteTask(oldTask.ID)
+		require.NoError(t, err)
+		err = gormDB.Unscoped().Model(&Task{}).Where("id = ?", oldTask.ID).
+			Update("deleted_at", time.Now().AddDate(0, 0, -60)).Error
+		require.NoError(t, err)
+		err = db.DeleteTask(recentTask.ID)
+		require.NoError(t, err)
+
+		purged, err := db.PurgeDeletedTasks(time.Now().AddDate(0, 0, -30))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		// Verify the old task is gone for good
+		var count int64
+		err = gormDB.Unscoped().Model(&Task{}).Where("id = ?", oldTask.ID).Count(&count).Error
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		err = gormDB.Table("task_assignments").Where("task_id = ?", oldTask.ID).Count(&count).Error
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		err = gormDB.Unscoped().Model(&TaskComment{}).Where("task_id = ?", oldTask.ID).Count(&count).Error
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		// Verify the other tasks are untouched
+		deletedTasks, err := db.GetDeletedTasks()
+		require.NoError(t, err)
+		require.Len(t, deletedTasks, 1)
+		assert.Equal(t, recentTask.ID, deletedTasks[0].ID)
+
+		_, err = db.GetTaskByID(activeTask.ID)
+		assert.NoError(t, err)
+
+		// Verify the assignee still exists
+		_, err = db.GetUserByID(assignee.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("nothing to purge", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		purged, err := db.PurgeDeletedTasks(time.Now())
+		assert.NoError(t, err)
+		assert.Zero(t, purged)
+	})
+}
+
+func TestAddTaskComment(t *testing.T) {
+	t.Run("successful comment creation", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create user
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		// Create task
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		comment, err := db.AddTaskComment(task.ID, author.ID, "Looks good")
+		assert.NoError(t, err)
+		require.NotNil(t, comment)
+		assert.NotZero(t, comment.ID)
+		assert.Equal(t, task.ID, comment.TaskID)
+		assert.Equal(t, author.ID, comment.AuthorID)
+		assert.Equal(t, "Looks good", comment.Text)
+	})
+
+	t.Run("empty comment should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		comment, err := db.AddTaskComment(task.ID, author.ID, "  \n ")
+		assert.Error(t, err)
+		assert.Nil(t, comment)
+	})
+
+	t.Run("comment on non-existent task should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		comment, err := db.AddTaskComment(999, 1, "Comment")
+		assert.Error(t, err)
+		assert.Nil(t, comment)
+	})
+
+	t.Run("comment on deleted task should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+		err = db.DeleteTask(task.ID)
+		require.NoError(t, err)
+
+		comment, err := db.AddTaskComment(task.ID, author.ID, "Comment")
+		assert.Error(t, err)
+		assert.Nil(t, comment)
+	})
+}
+
+func TestGetTaskComments(t *testing.T) {
+	t.Run("returns comments in creation order with authors", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		commenter := &User{
+			Username:  "Commenter",
+			DiscordID: "commenter456",
+		}
+		err = db.CreateUser(commenter)
+		require.NoError(t, err)
+
+		// Create tasks
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		otherTask := &Task{Title: "Other Task"}
+		err = db.CreateTaskWithUserDiscordID(otherTask, "author123", "")
+		require.NoError(t, err)
+
+		_, err = db.AddTaskComment(task.ID, author.ID, "First")
+		require.NoError(t, err)
+		_, err = db.AddTaskComment(task.ID, commenter.ID, "Second")
+		require.NoError(t, err)
+		_, err = db.AddTaskComment(otherTask.ID, author.ID, "Elsewhere")
+		require.NoError(t, err)
+
+		comments, err := db.GetTaskComments(task.ID)
+		assert.NoError(t, err)
+		require.Len(t, comments, 2)
+		assert.Equal(t, "First", comments[0].Text)
+		assert.Equal(t, author.Username, comments[0].Author.Username)
+		assert.Equal(t, "Second", comments[1].Text)
+		assert.Equal(t, commenter.Username, comments[1].Author.Username)
+	})
+
+	t.Run("task without comments", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		comments, err := db.GetTaskComments(1)
+		assert.NoError(t, err)
+		assert.Empty(t, comments)
+	})
+}
+
+func TestClaimTask(t *testing.T) {
+	t.Run("successful claim assigns and starts the task", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		claimer := &User{
+			Username:  "Claimer",
+			DiscordID: "claimer456",
+		}
+		err = db.CreateUser(claimer)
+		require.NoError(t, err)
+
+		// Create unassigned task
+		task := &Task{Title: "Task", Role: "developer"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		claimedTask, err := db.ClaimTask(task.ID, claimer.ID, 0)
+		assert.NoError(t, err)
+		require.NotNil(t, claimedTask)
+		assert.Equal(t, TASK_IN_PROGRESS, claimedTask.Status)
+		require.Len(t, claimedTask.AssignedUsers, 1)
+		assert.Equal(t, claimer.ID, claimedTask.AssignedUsers[0].ID)
+		assert.Equal(t, author.ID, claimedTask.Author.ID)
+
+		// Verify the task is no longer unassigned
+		unassignedTasks, err := db.GetUnassignedTasksByRole("developer")
+		require.NoError(t, err)
+		assert.Empty(t, unassignedTasks)
+	})
+
+	t.Run("claiming an assigned task fails", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		assignee := &User{
+			Username:  "Assignee",
+			DiscordID: "assignee456",
+		}
+		err = db.CreateUser(assignee)
+		require.NoError(t, err)
+
+		claimer := &User{
+			Username:  "Claimer",
+			DiscordID: "claimer789",
+		}
+		err = db.CreateUser(claimer)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "assignee456")
+		require.NoError(t, err)
+
+		claimedTask, err := db.ClaimTask(task.ID, claimer.ID, 0)
+		assert.ErrorIs(t, err, ErrTaskAlreadyAssigned)
+		assert.Nil(t, claimedTask)
+
+		// Verify the assignment is unchanged
+		retrievedTask, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		require.Len(t, retrievedTask.AssignedUsers, 1)
+		assert.Equal(t, assignee.ID, retrievedTask.AssignedUsers[0].ID)
+		assert.Equal(t, TASK_NOT_STARTED, retrievedTask.Status)
+	})
+
+	t.Run("claiming a completed task fails", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task", Status: TASK_COMPLETED}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		claimedTask, err := db.ClaimTask(task.ID, author.ID, 0)
+		assert.ErrorIs(t, err, ErrTaskCompleted)
+		assert.Nil(t, claimedTask)
+	})
+
+	t.Run("claiming a non-existent task fails", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		claimedTask, err := db.ClaimTask(999, 1, 0)
+		assert.Error(t, err)
+		assert.Nil(t, claimedTask)
+	})
+
+	t.Run("in-progress limit is enforced", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		claimer := &User{
+			Username:  "Claimer",
+			DiscordID: "claimer456",
+		}
+		err = db.CreateUser(claimer)
+		require.NoError(t, err)
+
+		tasks := make([]*Task, 3)
+		for i := range tasks {
+			tasks[i] = &Task{Title: fmt.Sprintf("Task %d", i+1)}
+			err = db.CreateTaskWithUserDiscordID(tasks[i], "author123", "")
+			require.NoError(t, err)
+		}
+
+		_, err = db.ClaimTask(tasks[0].ID, claimer.ID, 2)
+		require.NoError(t, err)
+		_, err = db.ClaimTask(tasks[1].ID, claimer.ID, 2)
+		require.NoError(t, err)
+
+		claimedTask, err := db.ClaimTask(tasks[2].ID, claimer.ID, 2)
+		assert.ErrorIs(t, err, ErrInProgressLimitReached)
+		assert.Nil(t, claimedTask)
+
+		// Completing a task frees a slot
+		_, err = db.UpdateTaskStatus(tasks[0].ID, TASK_COMPLETED)
+		require.NoError(t, err)
+
+		claimedTask, err = db.ClaimTask(tasks[2].ID, claimer.ID, 2)
+		assert.NoError(t, err)
+		assert.NotNil(t, claimedTask)
+	})
+
+	t.Run("concurrent claims assign the task once", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		claimers := make([]*User, 5)
+		for i := range claimers {
+			claimers[i] = &User{
+				Username:  fmt.Sprintf("Claimer %d", i),
+				DiscordID: fmt.Sprintf("claimer%d", i),
+			}
+			err = db.CreateUser(claimers[i])
+			require.NoError(t, err)
+		}
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(claimers))
+		for i, claimer := range claimers {
+			wg.Add(1)
+			go func(i int, userID uint) {
+				defer wg.Done()
+				_, errs[i] = db.ClaimTask(task.ID, userID, 0)
+			}(i, claimer.ID)
+		}
+		wg.Wait()
+
+		successes := 0
+		for _, err := range errs {
+			if err == nil {
+				successes++
+			} else {
+				assert.ErrorIs(t, err, ErrTaskAlreadyAssigned)
+			}
+		}
+		assert.Equal(t, 1, successes)
+
+		retrievedTask, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Len(t, retrievedTask.AssignedUsers, 1)
+	})
+}
+
+func TestUpdateTaskDueDate(t *testing.T) {
+	t.Run("set and clear the due date", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+		require.NoError(t, err)
+
+		// Set the due date
+		dueDate := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+		updatedTask, changes, err := db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			DueDate: &dueDate,
+		})
+		assert.NoError(t, err)
+		require.NotNil(t, updatedTask.DueDate)
+		require.Len(t, changes, 1)
+		assert.Equal(t, TASK_FIELD_DUE_DATE, changes[0].Field)
+		assert.Empty(t, changes[0].OldValue)
+		assert.Equal(t, "2026-11-01", changes[0].NewValue)
+
+		retrievedTask, err := db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrievedTask.DueDate)
+		assert.True(t, dueDate.Equal(*retrievedTask.DueDate))
+
+		// Clear the due date
+		_, changes, err = db.UpdateTask(task.ID, author.ID, &TaskUpdateOptions{
+			ClearDueDate: true,
+		})
+		assert.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "2026-11-01", changes[0].OldValue)
+		assert.Empty(t, changes[0].NewValue)
+
+		retrievedTask, err = db.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Nil(t, retrievedTask.DueDate)
+	})
+}
+
+func TestUpdateTaskStatusCompletedAt(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	author := &User{
+		Username:  "Author",
+		DiscordID: "author123",
+	}
+	err := db.CreateUser(author)
+	require.NoError(t, err)
+
+	task := &Task{Title: "Task"}
+	err = db.CreateTaskWithUserDiscordID(task, "author123", "")
+	require.NoError(t, err)
+
+	// Completing the task records when it happened
+	updatedTask, err := db.UpdateTaskStatus(task.ID, TASK_COMPLETED)
+	require.NoError(t, err)
+	require.NotNil(t, updatedTask.CompletedAt)
+	completedAt := *updatedTask.CompletedAt
+
+	// Completing it again keeps the original timestamp
+	updatedTask, err = db.UpdateTaskStatus(task.ID, TASK_COMPLETED)
+	require.NoError(t, err)
+	require.NotNil(t, updatedTask.CompletedAt)
+	assert.True(t, completedAt.Equal(*updatedTask.CompletedAt))
+
+	// Reopening the task clears it
+	updatedTask, err = db.UpdateTaskStatus(task.ID, TASK_IN_PROGRESS)
+	require.NoError(t, err)
+	assert.Nil(t, updatedTask.CompletedAt)
+
+	retrievedTask, err := db.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, retrievedTask.CompletedAt)
+}
+
+func TestAddTaskCommitComment(t *testing.T) {
+	t.Run("a commit is linked to a task only once", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		bot := &User{
+			Username:  "Bot",
+			DiscordID: "bot123",
+		}
+		err := db.CreateUser(bot)
+		require.NoError(t, err)
+
+		task := &Task{Title: "Task"}
+		err = db.CreateTaskWithUserDiscordID(task, "bot123", "")
+		require.NoError(t, err)
+
+		otherTask := &Task{Title: "Other Task"}
+		err = db.CreateTaskWithUserDiscordID(otherTask, "bot123", "")
+		require.NoError(t, err)
+
+		comment, created, err := db.AddTaskCommitComment(task.ID, bot.ID, "abc123", "Referenced in a commit")
+		assert.NoError(t, err)
+		assert.True(t, created)
+		require.NotNil(t, comment)
+		assert.Equal(t, "abc123", comment.CommitSHA)
+
+		// Pushing the same commit again doesn't add another comment
+		comment, created, err = db.AddTaskCommitComment(task.ID, bot.ID, "abc123", "Referenced in a commit")
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Nil(t, comment)
+
+		// The same commit can reference other tasks
+		_, created, err = db.AddTaskCommitComment(otherTask.ID, bot.ID, "abc123", "Referenced in a commit")
+		assert.NoError(t, err)
+		assert.True(t, created)
+
+		comments, err := db.GetTaskComments(task.ID)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 1)
+	})
+
+	t.Run("missing task or commit should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, _, err := db.AddTaskCommitComment(999, 1, "abc123", "Referenced in a commit")
+		assert.Error(t, err)
+
+		_, _, err = db.AddTaskCommitComment(1, 1, "", "Referenced in a commit")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetWebhookSubscriptionsByChannel(t *testing.T) {
+	t.Run("returns the subscriptions of the channel only", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		for _, name := range []string{"repo-b", "repo-a", "repo-c"} {
+			require.NoError(t, gormDB.Create(&Repository{Name: name}).Error)
+		}
+		_, err := db.CreateWebhookSubscription("repo-b", "channel1")
+		require.NoError(t, err)
+		_, err = db.CreateWebhookSubscription("repo-a", "channel1")
+		require.NoError(t, err)
+		_, err = db.CreateWebhookSubscription("repo-c", "channel2")
+		require.NoError(t, err)
+
+		subscriptions, err := db.GetWebhookSubscriptionsByChannel("channel1")
+		assert.NoError(t, err)
+		require.Len(t, subscriptions, 2)
+		assert.Equal(t, "repo-a", subscriptions[0].Repository.Name)
+		assert.Equal(t, "repo-b", subscriptions[1].Repository.Name)
+	})
+
+	t.Run("includes disabled subscriptions", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		require.NoError(t, gormDB.Create(&Repository{Name: "test-repo"}).Error)
+		_, err := db.CreateWebhookSubscription("test-repo", "channel1")
+		require.NoError(t, err)
+		_, err = db.DisableChannelDeliveries("channel1", "Missing Access")
+		require.NoError(t, err)
+
+		subscriptions, err := db.GetWebhookSubscriptionsByChannel("channel1")
+		assert.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.True(t, subscriptions[0].Disabled)
+		assert.Equal(t, "Missing Access", subscriptions[0].DisabledReason)
+	})
+
+	t.Run("channel without subscriptions", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		subscriptions, err := db.GetWebhookSubscriptionsByChannel("channel1")
+		assert.NoError(t, err)
+		assert.Empty(t, subscriptions)
+	})
+}
+
+func TestGetAllWebhookSubscriptions(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	require.NoError(t, gormDB.Create(&Repository{Name: "repo-a"}).Error)
+	require.NoError(t, gormDB.Create(&Repository{Name: "repo-b"}).Error)
+	_, err := db.CreateWebhookSubscription("repo-b", "channel2")
+	require.NoError(t, err)
+	_, err = db.CreateWebhookSubscription("repo-a", "channel2")
+	require.NoError(t, err)
+	_, err = db.CreateWebhookSubscription("repo-b", "channel1")
+	require.NoError(t, err)
+
+	subscriptions, err := db.GetAllWebhookSubscriptions()
+	assert.NoError(t, err)
+	require.Len(t, subscriptions, 3)
+	assert.Equal(t, "channel1", subscriptions[0].ChannelID)
+	assert.Equal(t, "repo-a", subscriptions[1].Repository.Name)
+	assert.Equal(t, "repo-b", subscriptions[2].Repository.Name)
+}
+
+func TestSetWebhookSubscriptionBranchFilter(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	require.NoError(t, gormDB.Create(&Repository{Name: "test-repo"}).Error)
+	_, err := db.CreateWebhookSubscription("test-repo", "channel1")
+	require.NoError(t, err)
+
+	require.NoError(t, db.SetWebhookSubscriptionBranchFilter("test-repo", "channel1", "release/*"))
+	subscriptions, err := db.GetWebhookSubscriptionsByRepository("test-repo")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, "release/*", subscriptions[0].BranchFilter)
+
+	err = db.SetWebhookSubscriptionBranchFilter("test-repo", "channel2", "main")
+	assert.Error(t, err)
+}
+
+func TestDeleteWebhookSubscriptionByID(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	require.NoError(t, gormDB.Create(&Repository{Name: "test-repo"}).Error)
+	subscription, err := db.CreateWebhookSubscription("test-repo", "channel1")
+	require.NoError(t, err)
+
+	deleted, err := db.DeleteWebhookSubscriptionByID(subscription.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-repo", deleted.Repository.Name)
+
+	subscriptions, err := db.GetWebhookSubscriptionsByChannel("channel1")
+	assert.NoError(t, err)
+	assert.Empty(t, subscriptions)
+
+	// The channel can subscribe again
+	_, err = db.CreateWebhookSubscription("test-repo", "channel1")
+	assert.NoError(t, err)
+
+	_, err = db.DeleteWebhookSubscriptionByID(999)
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+type MemberWorkload struct {
+	UserID     uint
+	DiscordID  string
+	Username   string
+	NotStarted int64
+	InProgress int64
+}
+
+type RoleDigest struct {
+	Role      string
+	Since     time.Time
+	Until     time.Time
+	Completed []Task
+	Created   []Task
+	Overdue   []Task
+	Workload  []MemberWorkload
+}
+
+func (d *DB) CreateDigestSchedule(channelID string, role string, lastPostedAt time.Time) (*DigestSchedule, error) {
+	if channelID == "" || role == "" {
+		return nil, fmt.Errorf("channel and role cannot be empty")
+	}
+
+	schedule := &DigestSchedule{
+		ChannelID:    channelID,
+		Role:         role,
+		LastPostedAt: &lastPostedAt,
+	}
+	if err := d.db.Create(schedule).Error; err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (d *DB) DeleteDigestSchedule(channelID string, role string) error {
+	result := d.db.Unscoped().
+		Where("channel_id = ? AND role = ?", channelID, role).
+		Delete(&DigestSchedule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no digest for role '%s' in channel %s", role, channelID)
+	}
+
+	return nil
+}
+
+func (d *DB) GetDigestSchedules() ([]DigestSchedule, error) {
+	schedules := make([]DigestSchedule, 0)
+
+	if err := d.db.Order("channel_id, role").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (d *DB) GetDigestSchedulesByChannel(channelID string) ([]DigestSchedule, error) {
+	schedules := make([]DigestSchedule, 0)
+
+	if err := d.db.Where("channel_id = ?", channelID).Order("role").Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// ClaimDigestRun marks the digest of the period starting at periodStart as
+// posted. It returns false if that digest was already claimed, so that a
+// digest is never posted twice, even across restarts.
+func (d *DB) ClaimDigestRun(scheduleID uint, periodStart time.Time) (bool, error) {
+	result := d.db.Model(&DigestSchedule{}).
+		Where("id = ? AND (last_posted_at IS NULL OR last_posted_at < ?)", scheduleID, periodStart).
+		Update("last_posted_at", periodStart)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseDigestRun gives back a claimed run whose digest could not be posted,
+// so that it is retried.
+func (d *DB) ReleaseDigestRun(scheduleID uint, previous *time.Time) error {
+	return d.db.Model(&DigestSchedule{}).
+		Where("id = ?", scheduleID).
+		Update("last_posted_at", previous).Error
+}
+
+// GetRoleDigest collects the activity of a role between since and until:
+// completed and created tasks, open tasks overdue at until and the open
+// workload of every member.
+func (d *DB) GetRoleDigest(role string, since time.Time, until time.Time) (*RoleDigest, error) {
+	if role == "" {
+		return nil, fmt.Errorf("role cannot be empty")
+	}
+
+	digest := &RoleDigest{
+		Role:      role,
+		Since:     since,
+		Until:     until,
+		Completed: make([]Task, 0),
+		Created:   make([]Task, 0),
+		Overdue:   make([]Task, 0),
+	}
+
+	if err := d.db.Preload("AssignedUsers").
+		Where("role = ? AND status = ? AND completed_at >= ? AND completed_at < ?", role, TASK_COMPLETED, since, until).
+		Order("completed_at").
+		Find(&digest.Completed).Error; err != nil {
+		return nil, fmt.Errorf("failed to get completed tasks: %w", err)
+	}
+
+	if err := d.db.Preload("Author").
+		Where("role = ? AND created_at >= ? AND created_at < ?", role, since, until).
+		Order("created_at").
+		Find(&digest.Created).Error; err != nil {
+		return nil, fmt.Errorf("failed to get created tasks: %w", err)
+	}
+
+	if err := d.db.Preload("AssignedUsers").
+		Where("role = ? AND status != ? AND due_date IS NOT NULL AND due_date < ?", role, TASK_COMPLETED, until).
+		Order("due_date").
+		Find(&digest.Overdue).Error; err != nil {
+		return nil, fmt.Errorf("failed to get overdue tasks: %w", err)
+	}
+
+	workload, err := d.GetMemberWorkload(role)
+	if err != nil {
+		return nil, err
+	}
+	digest.Workload = workload
+
+	return digest, nil
+}
+
+// GetMemberWorkload counts the open tasks of every member by status. An empty
+// role counts the tasks of all roles.
+func (d *DB) GetMemberWorkload(role string) ([]MemberWorkload, error) {
+	workload := make([]MemberWorkload, 0)
+
+	query := d.db.Table("tasks").
+		Select(
+			"users.id AS user_id, users.discord_id, users.username, "+
+				"SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END) AS not_started, "+
+				"SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END) AS in_progress",
+			TASK_NOT_STARTED, TASK_IN_PROGRESS,
+		).
+		Joins("JOIN task_assignments ON task_assignments.task_id = tasks.id").
+		Joins("JOIN users ON users.id = task_assignments.user_id").
+		Where("tasks.deleted_at IS NULL AND tasks.status != ?", TASK_COMPLETED)
+	if role != "" {
+		query = query.Where("tasks.role = ?", role)
+	}
+
+	if err := query.
+		Group("users.id, users.discord_id, users.username").
+		Order("in_progress + not_started DESC, users.username").
+		Scan(&workload).Error; err != nil {
+		return nil, fmt.Errorf("failed to get member workload: %w", err)
+	}
+
+	return workload, nil
+}
@@ -0,0 +1,306 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDigestSchedule(t *testing.T) {
+	t.Run("successful schedule creation", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		lastPostedAt := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+		schedule, err := db.CreateDigestSchedule("channel123", "developer", lastPostedAt)
+		assert.NoError(t, err)
+		require.NotNil(t, schedule)
+		assert.NotZero(t, schedule.ID)
+		assert.Equal(t, "channel123", schedule.ChannelID)
+		assert.Equal(t, "developer", schedule.Role)
+		require.NotNil(t, schedule.LastPostedAt)
+		assert.True(t, lastPostedAt.Equal(*schedule.LastPostedAt))
+	})
+
+	t.Run("duplicate channel and role should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, err := db.CreateDigestSchedule("channel123", "developer", time.Now())
+		require.NoError(t, err)
+
+		_, err = db.CreateDigestSchedule("channel123", "developer", time.Now())
+		assert.Error(t, err)
+
+		// Same role in another channel is allowed
+		_, err = db.CreateDigestSchedule("channel456", "developer", time.Now())
+		assert.NoError(t, err)
+	})
+
+	t.Run("empty channel or role should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, err := db.CreateDigestSchedule("", "developer", time.Now())
+		assert.Error(t, err)
+
+		_, err = db.CreateDigestSchedule("channel123", "", time.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteDigestSchedule(t *testing.T) {
+	t.Run("successful schedule deletion", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, err := db.CreateDigestSchedule("channel123", "developer", time.Now())
+		require.NoError(t, err)
+		_, err = db.CreateDigestSchedule("channel123", "designer", time.Now())
+		require.NoError(t, err)
+
+		err = db.DeleteDigestSchedule("channel123", "developer")
+		assert.NoError(t, err)
+
+		schedules, err := db.GetDigestSchedulesByChannel("channel123")
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.Equal(t, "designer", schedules[0].Role)
+
+		// The schedule can be created again after deletion
+		_, err = db.CreateDigestSchedule("channel123", "developer", time.Now())
+		assert.NoError(t, err)
+	})
+
+	t.Run("deleting a non-existent schedule fails", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		err := db.DeleteDigestSchedule("channel123", "developer")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetDigestSchedules(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	_, err := db.CreateDigestSchedule("channel2", "developer", time.Now())
+	require.NoError(t, err)
+	_, err = db.CreateDigestSchedule("channel1", "designer", time.Now())
+	require.NoError(t, err)
+
+	schedules, err := db.GetDigestSchedules()
+	assert.NoError(t, err)
+	require.Len(t, schedules, 2)
+	assert.Equal(t, "channel1", schedules[0].ChannelID)
+	assert.Equal(t, "channel2", schedules[1].ChannelID)
+}
+
+func TestClaimDigestRun(t *testing.T) {
+	t.Run("a run is claimed only once", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		previousRun := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+		schedule, err := db.CreateDigestSchedule("channel123", "developer", previousRun)
+		require.NoError(t, err)
+
+		periodStart := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+		claimed, err := db.ClaimDigestRun(schedule.ID, periodStart)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// A second claim for the same period, e.g. after a restart, fails
+		claimed, err = db.ClaimDigestRun(schedule.ID, periodStart)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		// The next period can be claimed
+		claimed, err = db.ClaimDigestRun(schedule.ID, periodStart.AddDate(0, 0, 7))
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+
+	t.Run("a released run can be claimed again", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		previousRun := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+		schedule, err := db.CreateDigestSchedule("channel123", "developer", previousRun)
+		require.NoError(t, err)
+
+		periodStart := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+		claimed, err := db.ClaimDigestRun(schedule.ID, periodStart)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		err = db.ReleaseDigestRun(schedule.ID, &previousRun)
+		assert.NoError(t, err)
+
+		claimed, err = db.ClaimDigestRun(schedule.ID, periodStart)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+	})
+}
+
+func TestGetRoleDigest(t *testing.T) {
+	t.Run("collects the activity of the period", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		assignee := &User{
+			Username:  "Assignee",
+			DiscordID: "assignee456",
+		}
+		err = db.CreateUser(assignee)
+		require.NoError(t, err)
+
+		since := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+		until := since.AddDate(0, 0, 7)
+		inPeriod := since.AddDate(0, 0, 2)
+		beforePeriod := since.AddDate(0, 0, -3)
+		pastDue := since.AddDate(0, 0, 1)
+		futureDue := until.AddDate(0, 0, 7)
+
+		tasks := []*Task{
+			// Completed during the period
+			{Title: "Completed", Role: "developer", Status: TASK_COMPLETED, CompletedAt: &inPeriod, AuthorID: author.ID},
+			// Completed before the period
+			{Title: "Completed Before", Role: "developer", Status: TASK_COMPLETED, CompletedAt: &beforePeriod, AuthorID: author.ID},
+			// Open and overdue
+			{Title: "Overdue", Role: "developer", Status: TASK_IN_PROGRESS, DueDate: &pastDue, AuthorID: author.ID},
+			// Open and due later
+			{Title: "Due Later", Role: "developer", Status: TASK_NOT_STARTED, DueDate: &futureDue, AuthorID: author.ID},
+			// Another role
+			{Title: "Other Role", Role: "designer", Status: TASK_COMPLETED, CompletedAt: &inPeriod, AuthorID: author.ID},
+		}
+		for _, task := range tasks {
+			task.AssignedUsers = []User{*assignee}
+			err = gormDB.Create(task).Error
+			require.NoError(t, err)
+		}
+
+		// Backdate all tasks except the overdue one, which is created during the period
+		for _, task := range tasks {
+			createdAt := beforePeriod
+			if task.Title == "Overdue" {
+				createdAt = inPeriod
+			}
+			err = gormDB.Model(task).UpdateColumn("created_at", createdAt).Error
+			require.NoError(t, err)
+		}
+
+		digest, err := db.GetRoleDigest("developer", since, until)
+		assert.NoError(t, err)
+		require.NotNil(t, digest)
+
+		require.Len(t, digest.Completed, 1)
+		assert.Equal(t, "Completed", digest.Completed[0].Title)
+		require.Len(t, digest.Completed[0].AssignedUsers, 1)
+
+		require.Len(t, digest.Created, 1)
+		assert.Equal(t, "Overdue", digest.Created[0].Title)
+		assert.Equal(t, author.Username, digest.Created[0].Author.Username)
+
+		require.Len(t, digest.Overdue, 1)
+		assert.Equal(t, "Overdue", digest.Overdue[0].Title)
+
+		require.Len(t, digest.Workload, 1)
+		assert.Equal(t, assignee.DiscordID, digest.Workload[0].DiscordID)
+		assert.Equal(t, int64(1), digest.Workload[0].NotStarted)
+		assert.Equal(t, int64(1), digest.Workload[0].InProgress)
+	})
+
+	t.Run("empty role should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		digest, err := db.GetRoleDigest("", time.Now().AddDate(0, 0, -7), time.Now())
+		assert.Error(t, err)
+		assert.Nil(t, digest)
+	})
+}
+
+func TestGetMemberWorkload(t *testing.T) {
+	t.Run("counts open tasks per member", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{
+			Username:  "Author",
+			DiscordID: "author123",
+		}
+		err := db.CreateUser(author)
+		require.NoError(t, err)
+
+		busy := &User{
+			Username:  "Busy",
+			DiscordID: "busy456",
+		}
+		err = db.CreateUser(busy)
+		require.NoError(t, err)
+
+		idle := &User{
+			Username:  "Idle",
+			DiscordID: "idle789",
+		}
+		err = db.CreateUser(idle)
+		require.NoError(t, err)
+
+		tasks := []*Task{
+			{Title: "Task 1", Role: "developer", Status: TASK_IN_PROGRESS, AuthorID: author.ID, AssignedUsers: []User{*busy}},
+			{Title: "Task 2", Role: "developer", Status: TASK_IN_PROGRESS, AuthorID: author.ID, AssignedUsers: []User{*busy}},
+			{Title: "Task 3", Role: "designer", Status: TASK_NOT_STARTED, AuthorID: author.ID, AssignedUsers: []User{*busy, *idle}},
+			{Title: "Task 4", Role: "developer", Status: TASK_COMPLETED, AuthorID: author.ID, AssignedUsers: []User{*idle}},
+		}
+		for _, task := range tasks {
+			err = gormDB.Create(task).Error
+			require.NoError(t, err)
+		}
+
+		// Deleted tasks are not counted
+		deletedTask := &Task{Title: "Deleted", Role: "developer", Status: TASK_IN_PROGRESS, AuthorID: author.ID, AssignedUsers: []User{*idle}}
+		err = gormDB.Create(deletedTask).Error
+		require.NoError(t, err)
+		err = db.DeleteTask(deletedTask.ID)
+		require.NoError(t, err)
+
+		workload, err := db.GetMemberWorkload("")
+		assert.NoError(t, err)
+		require.Len(t, workload, 2)
+		assert.Equal(t, busy.ID, workload[0].UserID)
+		assert.Equal(t, int64(2), workload[0].InProgress)
+		assert.Equal(t, int64(1), workload[0].NotStarted)
+		assert.Equal(t, idle.ID, workload[1].UserID)
+		assert.Equal(t, int64(0), workload[1].InProgress)
+		assert.Equal(t, int64(1), workload[1].NotStarted)
+
+		workload, err = db.GetMemberWorkload("developer")
+		assert.NoError(t, err)
+		require.Len(t, workload, 1)
+		assert.Equal(t, busy.ID, workload[0].UserID)
+		assert.Equal(t, int64(2), workload[0].InProgress)
+		assert.Equal(t, int64(0), workload[0].NotStarted)
+	})
+
+	t.Run("no open tasks", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		workload, err := db.GetMemberWorkload("developer")
+		assert.NoError(t, err)
+		assert.Empty(t, workload)
+	})
+}
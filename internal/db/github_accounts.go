@@ -0,0 +1,143 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrGitHubLoginTaken          = errors.New("GitHub account is already linked to another user")
+	ErrGitHubLinkRequestNotFound = errors.New("no pending GitHub link request")
+)
+
+// CreateGitHubLinkRequest starts the link of a GitHub account to a user,
+// replacing any pending request of the user
+func (d *DB) CreateGitHubLinkRequest(userID uint, login string, code string, expiresAt time.Time) (*GitHubLinkRequest, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if login == "" || code == "" {
+		return nil, fmt.Errorf("login and code cannot be empty")
+	}
+
+	request := &GitHubLinkRequest{
+		UserID:    userID,
+		Login:     login,
+		Code:      code,
+		ExpiresAt: expiresAt,
+	}
+	err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"login", "code", "expires_at", "updated_at"}),
+	}).Create(request).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub link request: %w", err)
+	}
+
+	return request, nil
+}
+
+// GetGitHubLinkRequest returns the pending request of a user, if it hasn't expired at now
+func (d *DB) GetGitHubLinkRequest(userID uint, now time.Time) (*GitHubLinkRequest, error) {
+	request := &GitHubLinkRequest{}
+
+	result := d.db.Where("user_id = ? AND expires_at > ?", userID, now).Limit(1).Find(request)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to get GitHub link request: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrGitHubLinkRequestNotFound
+	}
+
+	return request, nil
+}
+
+// LinkGitHubLogin links a verified GitHub account to a user and drops the
+// pending request of the user
+func (d *DB) LinkGitHubLogin(userID uint, login string) (*User, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+	if login == "" {
+		return nil, fmt.Errorf("login cannot be empty")
+	}
+
+	user := &User{}
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&User{}).
+			Where("github_login = ? AND id != ?", login, userID).
+			Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to check GitHub login: %w", err)
+		}
+		if taken > 0 {
+			return ErrGitHubLoginTaken
+		}
+
+		if err := tx.First(user, userID).Error; err != nil {
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := tx.Model(user).Update("github_login", login).Error; err != nil {
+			return fmt.Errorf("failed to link GitHub login: %w", err)
+		}
+
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&GitHubLinkRequest{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (d *DB) UnlinkGitHubLogin(userID uint) error {
+	result := d.db.Model(&User{}).
+		Where("id = ? AND github_login IS NOT NULL", userID).
+		Update("github_login", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to unlink GitHub login: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no GitHub account linked to user %d", userID)
+	}
+
+	return nil
+}
+
+func (d *DB) GetUserByGitHubLogin(login string) (*User, error) {
+	user := &User{}
+
+	if err := d.db.Where("github_login = ?", strings.ToLower(strings.TrimSpace(login))).
+		First(user).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetDiscordIDsByGitHubLogins maps the given GitHub logins, lowercased, to
+// the Discord IDs of the users they are linked to. Unlinked logins are left out.
+func (d *DB) GetDiscordIDsByGitHubLogins(logins []string) (map[string]string, error) {
+	discordIDs := make(map[string]string)
+	if len(logins) == 0 {
+		return discordIDs, nil
+	}
+
+	lowered := make([]string, len(logins))
+	for i, login := range logins {
+		lowered[i] = strings.ToLower(login)
+	}
+
+	users := make([]User, 0)
+	if err := d.db.Where("github_login IN ?", lowered).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users by GitHub login: %w", err)
+	}
+
+	for _, user := range users {
+		discordIDs[*user.GitHubLogin] = user.DiscordID
+	}
+
+	return discordIDs, nil
+}
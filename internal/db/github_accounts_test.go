@@ -0,0 +1,156 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateGitHubLinkRequest(t *testing.T) {
+	t.Run("a new request replaces the pending one", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+
+		_, err := db.CreateGitHubLinkRequest(user.ID, "OldLogin", "code-1", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		_, err = db.CreateGitHubLinkRequest(user.ID, "NewLogin", "code-2", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		request, err := db.GetGitHubLinkRequest(user.ID, time.Now())
+		assert.NoError(t, err)
+		require.NotNil(t, request)
+		assert.Equal(t, "newlogin", request.Login)
+		assert.Equal(t, "code-2", request.Code)
+
+		var count int64
+		gormDB.Model(&GitHubLinkRequest{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("expired requests are not returned", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+
+		_, err := db.CreateGitHubLinkRequest(user.ID, "login", "code", time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+
+		_, err = db.GetGitHubLinkRequest(user.ID, time.Now())
+		assert.ErrorIs(t, err, ErrGitHubLinkRequestNotFound)
+	})
+
+	t.Run("empty login or code should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, err := db.CreateGitHubLinkRequest(1, "", "code", time.Now())
+		assert.Error(t, err)
+
+		_, err = db.CreateGitHubLinkRequest(1, "login", "", time.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestLinkGitHubLogin(t *testing.T) {
+	t.Run("successful link", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+		_, err := db.CreateGitHubLinkRequest(user.ID, "Octocat", "code", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		linked, err := db.LinkGitHubLogin(user.ID, "Octocat")
+		assert.NoError(t, err)
+		require.NotNil(t, linked.GitHubLogin)
+		assert.Equal(t, "octocat", *linked.GitHubLogin)
+
+		// The pending request is consumed
+		_, err = db.GetGitHubLinkRequest(user.ID, time.Now())
+		assert.ErrorIs(t, err, ErrGitHubLinkRequestNotFound)
+
+		found, err := db.GetUserByGitHubLogin("OCTOCAT")
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+	})
+
+	t.Run("a login can be linked to one user only", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user1 := &User{Username: "user1", DiscordID: "111"}
+		user2 := &User{Username: "user2", DiscordID: "222"}
+		require.NoError(t, db.CreateUser(user1))
+		require.NoError(t, db.CreateUser(user2))
+
+		_, err := db.LinkGitHubLogin(user1.ID, "octocat")
+		require.NoError(t, err)
+
+		_, err = db.LinkGitHubLogin(user2.ID, "Octocat")
+		assert.ErrorIs(t, err, ErrGitHubLoginTaken)
+
+		// Linking the same login again to the same user is fine
+		_, err = db.LinkGitHubLogin(user1.ID, "octocat")
+		assert.NoError(t, err)
+	})
+
+	t.Run("unlinked users don't conflict", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		require.NoError(t, db.CreateUser(&User{Username: "user1", DiscordID: "111"}))
+		require.NoError(t, db.CreateUser(&User{Username: "user2", DiscordID: "222"}))
+
+		var count int64
+		gormDB.Model(&User{}).Where("github_login IS NULL").Count(&count)
+		assert.Equal(t, int64(2), count)
+	})
+}
+
+func TestUnlinkGitHubLogin(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	user := &User{Username: "testuser", DiscordID: "123456789"}
+	require.NoError(t, db.CreateUser(user))
+
+	err := db.UnlinkGitHubLogin(user.ID)
+	assert.Error(t, err)
+
+	_, err = db.LinkGitHubLogin(user.ID, "octocat")
+	require.NoError(t, err)
+
+	err = db.UnlinkGitHubLogin(user.ID)
+	assert.NoError(t, err)
+
+	_, err = db.GetUserByGitHubLogin("octocat")
+	assert.Error(t, err)
+}
+
+func TestGetDiscordIDsByGitHubLogins(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	user1 := &User{Username: "user1", DiscordID: "111"}
+	user2 := &User{Username: "user2", DiscordID: "222"}
+	require.NoError(t, db.CreateUser(user1))
+	require.NoError(t, db.CreateUser(user2))
+	_, err := db.LinkGitHubLogin(user1.ID, "octocat")
+	require.NoError(t, err)
+
+	discordIDs, err := db.GetDiscordIDsByGitHubLogins([]string{"Octocat", "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"octocat": "111"}, discordIDs)
+
+	discordIDs, err = db.GetDiscordIDsByGitHubLogins(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, discordIDs)
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetTasksForExport returns every task of a role, in any status, with its
+// author and assignees. An empty role returns the tasks of all roles.
+func (d *DB) GetTasksForExport(role string) ([]Task, error) {
+	tasks := make([]Task, 0)
+
+	query := d.db.Preload("Author").Preload("AssignedUsers").Order("id")
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if err := query.Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetUsersByUsernames returns the users with the given usernames, compared
+// case-insensitively. Usernames without a user are left out.
+func (d *DB) GetUsersByUsernames(usernames []string) ([]User, error) {
+	users := make([]User, 0)
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	lowered := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lowered = append(lowered, strings.ToLower(username))
+	}
+	if err := d.db.Where("LOWER(username) IN ?", lowered).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	return users, nil
+}
+
+// ImportTasks creates the tasks in one transaction, so that either all of
+// them are created or none is. Tasks that don't start as Not Started have
+// their status transition recorded, and completed ones are completed now
+// unless they have a completion time.
+func (d *DB) ImportTasks(tasks []Task) error {
+	validStatuses := []string{TASK_NOT_STARTED, TASK_IN_PROGRESS, TASK_COMPLETED}
+	now := time.Now()
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		for i := range tasks {
+			task := &tasks[i]
+			if task.Status == "" {
+				task.Status = TASK_NOT_STARTED
+			}
+			if !slices.Contains(validStatuses, task.Status) {
+				return fmt.Errorf("invalid status '%s' of task %q", task.Status, task.Title)
+			}
+			if task.Status == TASK_COMPLETED && task.CompletedAt == nil {
+				task.CompletedAt = &now
+			}
+
+			if err := tx.Create(task).Error; err != nil {
+				return fmt.Errorf("failed to create task %q: %w", task.Title, err)
+			}
+			if err := recordStatusChange(tx, task.ID, TASK_NOT_STARTED, task.Status); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
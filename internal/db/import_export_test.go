@@ -0,0 +1,108 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTasksForExport(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	author := &User{Username: "Author", DiscordID: "author123"}
+	require.NoError(t, db.CreateUser(author))
+
+	for _, task := range []*Task{
+		{Title: "Wiring", Role: "developer", Status: TASK_NOT_STARTED, AuthorID: author.ID, AssignedUsers: []User{*author}},
+		{Title: "Firmware", Role: "developer", Status: TASK_COMPLETED, AuthorID: author.ID},
+		{Title: "Livery", Role: "designer", Status: TASK_IN_PROGRESS, AuthorID: author.ID},
+		{Title: "Deleted", Role: "developer", Status: TASK_NOT_STARTED, AuthorID: author.ID},
+	} {
+		require.NoError(t, gormDB.Create(task).Error)
+		if task.Title == "Deleted" {
+			require.NoError(t, db.DeleteTask(task.ID))
+		}
+	}
+
+	tasks, err := db.GetTasksForExport("developer")
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "Wiring", tasks[0].Title)
+	assert.Equal(t, "Author", tasks[0].Author.Username)
+	require.Len(t, tasks[0].AssignedUsers, 1)
+	assert.Equal(t, "Firmware", tasks[1].Title)
+
+	tasks, err = db.GetTasksForExport("")
+	require.NoError(t, err)
+	assert.Len(t, tasks, 3)
+}
+
+func TestGetUsersByUsernames(t *testing.T) {
+	db := NewDB(CreateTestDB())
+
+	require.NoError(t, db.CreateUser(&User{Username: "Alice", DiscordID: "alice456"}))
+	require.NoError(t, db.CreateUser(&User{Username: "bob", DiscordID: "bob789"}))
+
+	users, err := db.GetUsersByUsernames([]string{"alice", "BOB", "carol"})
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	users, err = db.GetUsersByUsernames(nil)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestImportTasks(t *testing.T) {
+	t.Run("creates every task", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{Username: "Author", DiscordID: "author123"}
+		require.NoError(t, db.CreateUser(author))
+
+		tasks := []Task{
+			{Title: "Wiring", Role: "developer", AuthorID: author.ID, AssignedUsers: []User{*author}},
+			{Title: "Firmware", Role: "developer", Status: TASK_IN_PROGRESS, AuthorID: author.ID},
+			{Title: "Livery", Role: "designer", Status: TASK_COMPLETED, AuthorID: author.ID},
+		}
+		require.NoError(t, db.ImportTasks(tasks))
+
+		wiring, err := db.GetTaskByID(tasks[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, TASK_NOT_STARTED, wiring.Status)
+		require.Len(t, wiring.AssignedUsers, 1)
+
+		livery, err := db.GetTaskByID(tasks[2].ID)
+		require.NoError(t, err)
+		assert.NotNil(t, livery.CompletedAt)
+
+		// The tasks that don't start as Not Started have their transition
+		changes := make([]TaskStatusChange, 0)
+		require.NoError(t, gormDB.Order("id").Find(&changes).Error)
+		require.Len(t, changes, 2)
+		assert.Equal(t, tasks[1].ID, changes[0].TaskID)
+		assert.Equal(t, TASK_IN_PROGRESS, changes[0].ToStatus)
+		assert.Equal(t, tasks[2].ID, changes[1].TaskID)
+		assert.Equal(t, TASK_COMPLETED, changes[1].ToStatus)
+	})
+
+	t.Run("creates nothing when a task is invalid", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		author := &User{Username: "Author", DiscordID: "author123"}
+		require.NoError(t, db.CreateUser(author))
+
+		err := db.ImportTasks([]Task{
+			{Title: "Wiring", Role: "developer", AuthorID: author.ID},
+			{Title: "Firmware", Role: "developer", Status: "Blocked", AuthorID: author.ID},
+		})
+		assert.ErrorContains(t, err, "Blocked")
+
+		var count int64
+		require.NoError(t, gormDB.Model(&Task{}).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrTaskAlreadyLinked = errors.New("task is already linked to a GitHub issue")
+
+// LinkTaskToIssue stores the GitHub issue opened from a task. A task can be
+// linked to one issue only.
+func (d *DB) LinkTaskToIssue(taskID uint, repository string, number int, url string) (*Task, error) {
+	if repository == "" || number <= 0 {
+		return nil, fmt.Errorf("invalid issue %s#%d", repository, number)
+	}
+
+	result := d.db.Model(&Task{}).
+		Where("id = ? AND issue_number = 0", taskID).
+		Updates(map[string]any{
+			"issue_repository": repository,
+			"issue_number":     number,
+			"issue_url":        url,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to link task to issue: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		task, err := d.GetTaskByID(taskID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get task: %w", err)
+		}
+		if task.IssueNumber != 0 {
+			return nil, ErrTaskAlreadyLinked
+		}
+		return nil, fmt.Errorf("task %d was not linked to issue %s#%d", taskID, repository, number)
+	}
+
+	return d.GetTaskByID(taskID)
+}
+
+// GetTaskByIssue returns the task linked to a GitHub issue
+func (d *DB) GetTaskByIssue(repository string, number int) (*Task, error) {
+	task := &Task{}
+
+	if err := d.db.Preload("Author").Preload("AssignedUsers").
+		Where("issue_repository = ? AND issue_number = ?", repository, number).
+		First(task).Error; err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
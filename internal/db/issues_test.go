@@ -0,0 +1,70 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLinkTaskToIssue(t *testing.T) {
+	t.Run("successful link", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+		task := &Task{Title: "Test Task", AuthorID: user.ID}
+		require.NoError(t, gormDB.Create(task).Error)
+
+		linked, err := db.LinkTaskToIssue(task.ID, "king", 12, "https://github.com/ApexCorse/king/issues/12")
+		assert.NoError(t, err)
+		assert.Equal(t, "king", linked.IssueRepository)
+		assert.Equal(t, 12, linked.IssueNumber)
+
+		found, err := db.GetTaskByIssue("king", 12)
+		assert.NoError(t, err)
+		assert.Equal(t, task.ID, found.ID)
+		assert.Equal(t, user.DiscordID, found.Author.DiscordID)
+	})
+
+	t.Run("a task can be linked once", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		task := &Task{Title: "Test Task"}
+		require.NoError(t, gormDB.Create(task).Error)
+
+		_, err := db.LinkTaskToIssue(task.ID, "king", 12, "")
+		require.NoError(t, err)
+
+		_, err = db.LinkTaskToIssue(task.ID, "king", 13, "")
+		assert.ErrorIs(t, err, ErrTaskAlreadyLinked)
+	})
+
+	t.Run("invalid issue or task should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, err := db.LinkTaskToIssue(1, "", 12, "")
+		assert.Error(t, err)
+
+		_, err = db.LinkTaskToIssue(999, "king", 12, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("deleted tasks are not found by issue", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		task := &Task{Title: "Test Task"}
+		require.NoError(t, gormDB.Create(task).Error)
+		_, err := db.LinkTaskToIssue(task.ID, "king", 12, "")
+		require.NoError(t, err)
+		require.NoError(t, gormDB.Delete(task).Error)
+
+		_, err = db.GetTaskByIssue("king", 12)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
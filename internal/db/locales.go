@@ -0,0 +1,31 @@
+package db
+
+import "fmt"
+
+// SetUserLocale sets the language of the bot messages for a user. An empty
+// locale follows the language of their Discord client again.
+func (d *DB) SetUserLocale(userID uint, locale string) error {
+	result := d.db.Model(&User{}).Where("id = ?", userID).Update("locale", locale)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set locale: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %d not found", userID)
+	}
+
+	return nil
+}
+
+// GetUserLocale returns the locale chosen by the user with the given Discord
+// ID. Users without a preference, or not registered yet, have none.
+func (d *DB) GetUserLocale(discordID string) (string, error) {
+	// Find instead of First, as most members never choose a locale and a
+	// missing user isn't an error worth logging on every interaction
+	user := &User{}
+	result := d.db.Select("locale").Where("discord_id = ?", discordID).Limit(1).Find(user)
+	if result.Error != nil {
+		return "", fmt.Errorf("failed to get locale: %w", result.Error)
+	}
+
+	return user.Locale, nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserLocale(t *testing.T) {
+	t.Run("set and clear the locale", func(t *testing.T) {
+		db := NewDB(CreateTestDB())
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+
+		locale, err := db.GetUserLocale(user.DiscordID)
+		assert.NoError(t, err)
+		assert.Empty(t, locale)
+
+		require.NoError(t, db.SetUserLocale(user.ID, "it"))
+		locale, err = db.GetUserLocale(user.DiscordID)
+		assert.NoError(t, err)
+		assert.Equal(t, "it", locale)
+
+		require.NoError(t, db.SetUserLocale(user.ID, ""))
+		locale, err = db.GetUserLocale(user.DiscordID)
+		assert.NoError(t, err)
+		assert.Empty(t, locale)
+	})
+
+	t.Run("unregistered users have no locale", func(t *testing.T) {
+		db := NewDB(CreateTestDB())
+
+		locale, err := db.GetUserLocale("unknown")
+		assert.NoError(t, err)
+		assert.Empty(t, locale)
+
+		assert.Error(t, db.SetUserLocale(42, "it"))
+	})
+}
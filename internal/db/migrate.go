@@ -0,0 +1,60 @@
+package db
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// models are the tables of the database
+var models = []any{
+	&User{},
+	&Task{},
+	&TaskComment{},
+	&TaskChange{},
+	&TaskStatusChange{},
+	&WebhookSubscription{},
+	&Repository{},
+	&DigestSchedule{},
+	&NotificationPreference{},
+	&OutboundMessage{},
+	&WebhookDelivery{},
+	&GitHubLinkRequest{},
+	&ReviewRequest{},
+	&APIToken{},
+	&TaskWebhook{},
+	&TaskWebhookDelivery{},
+	&CalendarFeed{},
+}
+
+// columnBackfill is the value of a column in the rows written while the
+// column was nullable, before it is made NOT NULL
+type columnBackfill struct {
+	model  any
+	column string
+	value  any
+}
+
+var columnBackfills = []columnBackfill{
+	{&WebhookSubscription{}, "disabled", false},
+	{&Task{}, "issue_number", 0},
+}
+
+// Migrate creates the tables of the database and adds the missing columns.
+// The columns added to existing tables are NOT NULL with a default, and the
+// rows that were written while they were nullable are backfilled first.
+func Migrate(db *gorm.DB) error {
+	for _, backfill := range columnBackfills {
+		if !db.Migrator().HasColumn(backfill.model, backfill.column) {
+			continue
+		}
+		err := db.Unscoped().Model(backfill.model).
+			Where(backfill.column+" IS NULL").
+			UpdateColumn(backfill.column, backfill.value).Error
+		if err != nil {
+			return fmt.Errorf("failed to backfill column %s: %w", backfill.column, err)
+		}
+	}
+
+	return db.AutoMigrate(models...)
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// The tables as they were before the columns added to them by the migrations
+
+type baselineTask struct {
+	gorm.Model
+
+	Title       string
+	Description string
+	Role        string
+	Status      string `gorm:"default:Not Started"`
+	AuthorID    uint
+}
+
+func (baselineTask) TableName() string { return "tasks" }
+
+type baselineWebhookSubscription struct {
+	gorm.Model
+
+	ChannelID    string `gorm:"uniqueIndex:idx_channel_id_repository_id"`
+	RepositoryID uint   `gorm:"uniqueIndex:idx_channel_id_repository_id"`
+}
+
+func (baselineWebhookSubscription) TableName() string { return "webhook_subscriptions" }
+
+// createBaselineDB returns a database with the tables of the first release,
+// a task, a repository and a subscription of a channel to it
+func createBaselineDB(t *testing.T) *gorm.DB {
+	gormDB := openTestDB()
+	require.NoError(t, gormDB.AutoMigrate(&User{}, &baselineTask{}, &Repository{}, &baselineWebhookSubscription{}))
+
+	author := &User{Username: "author", DiscordID: "111"}
+	require.NoError(t, gormDB.Create(author).Error)
+	require.NoError(t, gormDB.Create(&baselineTask{Title: "Fix the telemetry", AuthorID: author.ID}).Error)
+	repo := &Repository{Name: "king"}
+	require.NoError(t, gormDB.Create(repo).Error)
+	require.NoError(t, gormDB.Create(&baselineWebhookSubscription{ChannelID: "channel-1", RepositoryID: repo.ID}).Error)
+	return gormDB
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("from the baseline schema", func(t *testing.T) {
+		gormDB := createBaselineDB(t)
+		require.NoError(t, Migrate(gormDB))
+		db := NewDB(gormDB)
+
+		subscriptions, err := db.GetWebhookSubscriptionsByRepository("king")
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.False(t, subscriptions[0].Disabled)
+
+		task, err := db.LinkTaskToIssue(1, "king", 12, "")
+		require.NoError(t, err)
+		assert.Equal(t, 12, task.IssueNumber)
+	})
+
+	t.Run("nullable columns are backfilled", func(t *testing.T) {
+		// The columns were first added without a default
+		gormDB := createBaselineDB(t)
+		require.NoError(t, gormDB.Exec("ALTER TABLE webhook_subscriptions ADD disabled numeric").Error)
+		require.NoError(t, gormDB.Exec("ALTER TABLE tasks ADD issue_number integer").Error)
+		require.NoError(t, Migrate(gormDB))
+		db := NewDB(gormDB)
+
+		subscriptions, err := db.GetWebhookSubscriptionsByRepository("king")
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+
+		disabled, err := db.DisableChannelDeliveries("channel-1", "Missing Access")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), disabled)
+
+		// Subscribing again enables the subscription
+		_, err = db.CreateWebhookSubscription("king", "channel-1")
+		require.NoError(t, err)
+		subscriptions, err = db.GetWebhookSubscriptionsByRepository("king")
+		require.NoError(t, err)
+		assert.Len(t, subscriptions, 1)
+
+		_, err = db.LinkTaskToIssue(1, "king", 12, "")
+		require.NoError(t, err)
+		_, err = db.LinkTaskToIssue(1, "king", 13, "")
+		assert.ErrorIs(t, err, ErrTaskAlreadyLinked)
+	})
+
+	t.Run("migrating twice", func(t *testing.T) {
+		gormDB := createBaselineDB(t)
+		require.NoError(t, Migrate(gormDB))
+		require.NoError(t, Migrate(gormDB))
+	})
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm/clause"
+)
+
+func (d *DB) SetNotificationPreference(userID uint, event string, method string) error {
+	if !slices.Contains(NotificationEvents, event) {
+		return fmt.Errorf("invalid notification event: %s", event)
+	}
+	if !slices.Contains(NotificationMethods, method) {
+		return fmt.Errorf("invalid notification method: %s", method)
+	}
+
+	preference := &NotificationPreference{
+		UserID: userID,
+		Event:  event,
+		Method: method,
+	}
+	err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}},
+		DoUpdates: clause.AssignmentColumns([]string{"method", "updated_at"}),
+	}).Create(preference).Error
+	if err != nil {
+		return fmt.Errorf("failed to set notification preference: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotificationPreferences returns the method of every notification event
+// for a user, including the events left to the default.
+func (d *DB) GetNotificationPreferences(userID uint) (map[string]string, error) {
+	stored := make([]NotificationPreference, 0)
+	if err := d.db.Where("user_id = ?", userID).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	preferences := make(map[string]string, len(NotificationEvents))
+	for _, event := range NotificationEvents {
+		preferences[event] = NOTIFICATION_METHOD_DM
+	}
+	for _, preference := range stored {
+		preferences[preference.Event] = preference.Method
+	}
+
+	return preferences, nil
+}
+
+// GetNotificationMethod returns how the user with the given Discord ID wants
+// to be notified of an event. Users without a preference, or not registered
+// yet, are notified by DM.
+func (d *DB) GetNotificationMethod(discordID string, event string) (string, error) {
+	methods := make([]string, 0, 1)
+	err := d.db.Model(&NotificationPreference{}).
+		Joins("JOIN users ON users.id = notification_preferences.user_id").
+		Where("users.discord_id = ? AND notification_preferences.event = ?", discordID, event).
+		Limit(1).
+		Pluck("notification_preferences.method", &methods).Error
+	if err != nil {
+		return NOTIFICATION_METHOD_DM, fmt.Errorf("failed to get notification method: %w", err)
+	}
+
+	if len(methods) == 0 {
+		return NOTIFICATION_METHOD_DM, nil
+	}
+	return methods[0], nil
+}
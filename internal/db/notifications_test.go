@@ -0,0 +1,103 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetNotificationPreference(t *testing.T) {
+	t.Run("successful preference update", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+
+		err := db.SetNotificationPreference(user.ID, NOTIFICATION_EVENT_STATUS, NOTIFICATION_METHOD_MENTION)
+		assert.NoError(t, err)
+
+		// Setting the same event again replaces the method
+		err = db.SetNotificationPreference(user.ID, NOTIFICATION_EVENT_STATUS, NOTIFICATION_METHOD_NONE)
+		assert.NoError(t, err)
+
+		var count int64
+		gormDB.Model(&NotificationPreference{}).Where("user_id = ?", user.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+
+		method, err := db.GetNotificationMethod(user.DiscordID, NOTIFICATION_EVENT_STATUS)
+		assert.NoError(t, err)
+		assert.Equal(t, NOTIFICATION_METHOD_NONE, method)
+	})
+
+	t.Run("invalid event or method should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+
+		err := db.SetNotificationPreference(user.ID, "unknown", NOTIFICATION_METHOD_DM)
+		assert.Error(t, err)
+
+		err = db.SetNotificationPreference(user.ID, NOTIFICATION_EVENT_ASSIGNED, "carrier-pigeon")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetNotificationPreferences(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	user := &User{Username: "testuser", DiscordID: "123456789"}
+	require.NoError(t, db.CreateUser(user))
+	require.NoError(t, db.SetNotificationPreference(user.ID, NOTIFICATION_EVENT_COMMENT, NOTIFICATION_METHOD_NONE))
+
+	preferences, err := db.GetNotificationPreferences(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, preferences, len(NotificationEvents))
+	assert.Equal(t, NOTIFICATION_METHOD_NONE, preferences[NOTIFICATION_EVENT_COMMENT])
+	assert.Equal(t, NOTIFICATION_METHOD_DM, preferences[NOTIFICATION_EVENT_ASSIGNED])
+}
+
+func TestGetNotificationMethod(t *testing.T) {
+	t.Run("defaults to DM", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user := &User{Username: "testuser", DiscordID: "123456789"}
+		require.NoError(t, db.CreateUser(user))
+
+		method, err := db.GetNotificationMethod(user.DiscordID, NOTIFICATION_EVENT_ASSIGNED)
+		assert.NoError(t, err)
+		assert.Equal(t, NOTIFICATION_METHOD_DM, method)
+
+		method, err = db.GetNotificationMethod("unknown", NOTIFICATION_EVENT_ASSIGNED)
+		assert.NoError(t, err)
+		assert.Equal(t, NOTIFICATION_METHOD_DM, method)
+	})
+
+	t.Run("preferences are per user and event", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		user1 := &User{Username: "user1", DiscordID: "111"}
+		user2 := &User{Username: "user2", DiscordID: "222"}
+		require.NoError(t, db.CreateUser(user1))
+		require.NoError(t, db.CreateUser(user2))
+		require.NoError(t, db.SetNotificationPreference(user1.ID, NOTIFICATION_EVENT_ASSIGNED, NOTIFICATION_METHOD_MENTION))
+
+		method, err := db.GetNotificationMethod("111", NOTIFICATION_EVENT_ASSIGNED)
+		assert.NoError(t, err)
+		assert.Equal(t, NOTIFICATION_METHOD_MENTION, method)
+
+		method, err = db.GetNotificationMethod("111", NOTIFICATION_EVENT_STATUS)
+		assert.NoError(t, err)
+		assert.Equal(t, NOTIFICATION_METHOD_DM, method)
+
+		method, err = db.GetNotificationMethod("222", NOTIFICATION_EVENT_ASSIGNED)
+		assert.NoError(t, err)
+		assert.Equal(t, NOTIFICATION_METHOD_DM, method)
+	})
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (d *DB) EnqueueOutboundMessage(channelID string, content string, subscriptionID *uint) (*OutboundMessage, error) {
+	if channelID == "" {
+		return nil, fmt.Errorf("channel cannot be empty")
+	}
+	if content == "" {
+		return nil, fmt.Errorf("content cannot be empty")
+	}
+
+	message := &OutboundMessage{
+		ChannelID:      channelID,
+		Content:        content,
+		Status:         OUTBOUND_PENDING,
+		NextAttemptAt:  time.Now(),
+		SubscriptionID: subscriptionID,
+	}
+	if err := d.db.Create(message).Error; err != nil {
+		return nil, fmt.Errorf("failed to enqueue message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetDueOutboundMessages returns the pending messages that can be sent at now.
+// Only the oldest pending message of each channel is considered, so messages
+// are delivered to a channel in the order they were enqueued and at most one
+// message per channel is returned.
+func (d *DB) GetDueOutboundMessages(now time.Time, limit int) ([]OutboundMessage, error) {
+	messages := make([]OutboundMessage, 0)
+
+	oldestPerChannel := d.db.Model(&OutboundMessage{}).
+		Select("MIN(id)").
+		Where("status = ?", OUTBOUND_PENDING).
+		Group("channel_id")
+
+	if err := d.db.Where("id IN (?) AND next_attempt_at <= ?", oldestPerChannel, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (d *DB) MarkOutboundMessageSent(messageID uint, sentAt time.Time) error {
+	err := d.db.Model(&OutboundMessage{}).
+		Where("id = ?", messageID).
+		Updates(map[string]any{
+			"status":     OUTBOUND_SENT,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"sent_at":    sentAt,
+			"last_error": "",
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	// Messages not sent for a subscription match no row
+	return d.db.Model(&WebhookSubscription{}).
+		Where("id IN (?)", d.db.Model(&OutboundMessage{}).Select("subscription_id").Where("id = ?", messageID)).
+		Update("last_delivered_at", sentAt).Error
+}
+
+// RetryOutboundMessage records a failed attempt and schedules the next one
+func (d *DB) RetryOutboundMessage(messageID uint, nextAttemptAt time.Time, lastError string) error {
+	return d.db.Model(&OutboundMessage{}).
+		Where("id = ?", messageID).
+		Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+}
+
+// FailOutboundMessage records a failed attempt and gives up on the message
+func (d *DB) FailOutboundMessage(messageID uint, lastError string) error {
+	return d.db.Model(&OutboundMessage{}).
+		Where("id = ?", messageID).
+		Updates(map[string]any{
+			"status":     OUTBOUND_FAILED,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": lastError,
+		}).Error
+}
+
+// DisableChannelDeliveries gives up on all pending messages of a channel and
+// disables its webhook subscriptions, for channels that can't be reached
+// anymore. It returns the number of disabled subscriptions.
+func (d *DB) DisableChannelDeliveries(channelID string, reason string) (int64, error) {
+	var disabled int64
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&OutboundMessage{}).
+			Where("channel_id = ? AND status = ?", channelID, OUTBOUND_PENDING).
+			Updates(map[string]any{
+				"status":     OUTBOUND_FAILED,
+				"last_error": reason,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to fail pending messages: %w", err)
+		}
+
+		result := tx.Model(&WebhookSubscription{}).
+			Where("channel_id = ? AND disabled = ?", channelID, false).
+			Updates(map[string]any{
+				"disabled":        true,
+				"disabled_reason": reason,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to disable subscriptions: %w", result.Error)
+		}
+		disabled = result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return disabled, nil
+}
+
+// CountOutboundMessages returns the number of messages in each delivery state
+func (d *DB) CountOutboundMessages() (map[string]int64, error) {
+	rows := make([]struct {
+		Status string
+		Count  int64
+	}, 0)
+
+	if err := d.db.Model(&OutboundMessage{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	counts := map[string]int64{
+		OUTBOUND_PENDING: 0,
+		OUTBOUND_SENT:    0,
+		OUTBOUND_FAILED:  0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+func (d *DB) GetFailedOutboundMessages(limit int) ([]OutboundMessage, error) {
+	messages := make([]OutboundMessage, 0)
+
+	if err := d.db.Where("status = ?", OUTBOUND_FAILED).
+		Order("updated_at DESC, id DESC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// PurgeSentOutboundMessages permanently deletes the messages sent before sentBefore
+func (d *DB) PurgeSentOutboundMessages(sentBefore time.Time) (int64, error) {
+	result := d.db.Unscoped().
+		Where("status = ? AND sent_at < ?", OUTBOUND_SENT, sentBefore).
+		Delete(&OutboundMessage{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge sent messages: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,235 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueueOutboundMessage(t *testing.T) {
+	t.Run("successful enqueue", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		subscriptionID := uint(7)
+		message, err := db.EnqueueOutboundMessage("channel123", "hello", &subscriptionID)
+		assert.NoError(t, err)
+		require.NotNil(t, message)
+		assert.NotZero(t, message.ID)
+		assert.Equal(t, OUTBOUND_PENDING, message.Status)
+		assert.Equal(t, 0, message.Attempts)
+		require.NotNil(t, message.SubscriptionID)
+		assert.Equal(t, subscriptionID, *message.SubscriptionID)
+	})
+
+	t.Run("empty channel or content should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, err := db.EnqueueOutboundMessage("", "hello", nil)
+		assert.Error(t, err)
+
+		_, err = db.EnqueueOutboundMessage("channel123", "", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetDueOutboundMessages(t *testing.T) {
+	t.Run("only the oldest pending message of each channel is due", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		first, err := db.EnqueueOutboundMessage("channel1", "first", nil)
+		require.NoError(t, err)
+		_, err = db.EnqueueOutboundMessage("channel1", "second", nil)
+		require.NoError(t, err)
+		other, err := db.EnqueueOutboundMessage("channel2", "other", nil)
+		require.NoError(t, err)
+
+		messages, err := db.GetDueOutboundMessages(time.Now(), 10)
+		assert.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, first.ID, messages[0].ID)
+		assert.Equal(t, other.ID, messages[1].ID)
+
+		// Once the first message is sent, the second one of the channel is due
+		require.NoError(t, db.MarkOutboundMessageSent(first.ID, time.Now()))
+		messages, err = db.GetDueOutboundMessages(time.Now(), 10)
+		assert.NoError(t, err)
+		require.Len(t, messages, 2)
+		assert.Equal(t, "second", messages[0].Content)
+	})
+
+	t.Run("a message waiting for a retry blocks its channel", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		first, err := db.EnqueueOutboundMessage("channel1", "first", nil)
+		require.NoError(t, err)
+		_, err = db.EnqueueOutboundMessage("channel1", "second", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, db.RetryOutboundMessage(first.ID, time.Now().Add(time.Minute), "server error"))
+
+		messages, err := db.GetDueOutboundMessages(time.Now(), 10)
+		assert.NoError(t, err)
+		assert.Empty(t, messages)
+
+		messages, err = db.GetDueOutboundMessages(time.Now().Add(2*time.Minute), 10)
+		assert.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, first.ID, messages[0].ID)
+		assert.Equal(t, 1, messages[0].Attempts)
+		assert.Equal(t, "server error", messages[0].LastError)
+	})
+
+	t.Run("failed messages don't block their channel", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		first, err := db.EnqueueOutboundMessage("channel1", "first", nil)
+		require.NoError(t, err)
+		_, err = db.EnqueueOutboundMessage("channel1", "second", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, db.FailOutboundMessage(first.ID, "bad request"))
+
+		messages, err := db.GetDueOutboundMessages(time.Now(), 10)
+		assert.NoError(t, err)
+		require.Len(t, messages, 1)
+		assert.Equal(t, "second", messages[0].Content)
+	})
+
+	t.Run("limit is respected", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		for _, channelID := range []string{"channel1", "channel2", "channel3"} {
+			_, err := db.EnqueueOutboundMessage(channelID, "hello", nil)
+			require.NoError(t, err)
+		}
+
+		messages, err := db.GetDueOutboundMessages(time.Now(), 2)
+		assert.NoError(t, err)
+		assert.Len(t, messages, 2)
+	})
+}
+
+func TestDisableChannelDeliveries(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	require.NoError(t, gormDB.Create(&Repository{Name: "test-repo"}).Error)
+	require.NoError(t, gormDB.Create(&Repository{Name: "other-repo"}).Error)
+	_, err := db.CreateWebhookSubscription("test-repo", "channel1")
+	require.NoError(t, err)
+	_, err = db.CreateWebhookSubscription("other-repo", "channel1")
+	require.NoError(t, err)
+	_, err = db.CreateWebhookSubscription("test-repo", "channel2")
+	require.NoError(t, err)
+
+	_, err = db.EnqueueOutboundMessage("channel1", "first", nil)
+	require.NoError(t, err)
+	_, err = db.EnqueueOutboundMessage("channel2", "other", nil)
+	require.NoError(t, err)
+
+	disabled, err := db.DisableChannelDeliveries("channel1", "Unknown Channel")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), disabled)
+
+	// Disabled subscriptions don't receive webhooks anymore
+	subscriptions, err := db.GetWebhookSubscriptionsByRepository("test-repo")
+	assert.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, "channel2", subscriptions[0].ChannelID)
+
+	messages, err := db.GetDueOutboundMessages(time.Now(), 10)
+	assert.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "channel2", messages[0].ChannelID)
+
+	// Subscribing again re-enables the subscription
+	subscription, err := db.CreateWebhookSubscription("test-repo", "channel1")
+	assert.NoError(t, err)
+	assert.False(t, subscription.Disabled)
+	subscriptions, err = db.GetWebhookSubscriptionsByRepository("test-repo")
+	assert.NoError(t, err)
+	assert.Len(t, subscriptions, 2)
+}
+
+func TestCountOutboundMessages(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	counts, err := db.CountOutboundMessages()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), counts[OUTBOUND_PENDING])
+
+	sent, err := db.EnqueueOutboundMessage("channel1", "sent", nil)
+	require.NoError(t, err)
+	failed, err := db.EnqueueOutboundMessage("channel2", "failed", nil)
+	require.NoError(t, err)
+	_, err = db.EnqueueOutboundMessage("channel3", "pending", nil)
+	require.NoError(t, err)
+	require.NoError(t, db.MarkOutboundMessageSent(sent.ID, time.Now()))
+	require.NoError(t, db.FailOutboundMessage(failed.ID, "Missing Access"))
+
+	counts, err = db.CountOutboundMessages()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), counts[OUTBOUND_PENDING])
+	assert.Equal(t, int64(1), counts[OUTBOUND_SENT])
+	assert.Equal(t, int64(1), counts[OUTBOUND_FAILED])
+
+	failedMessages, err := db.GetFailedOutboundMessages(10)
+	assert.NoError(t, err)
+	require.Len(t, failedMessages, 1)
+	assert.Equal(t, "Missing Access", failedMessages[0].LastError)
+}
+
+func TestPurgeSentOutboundMessages(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	old, err := db.EnqueueOutboundMessage("channel1", "old", nil)
+	require.NoError(t, err)
+	recent, err := db.EnqueueOutboundMessage("channel2", "recent", nil)
+	require.NoError(t, err)
+	_, err = db.EnqueueOutboundMessage("channel3", "pending", nil)
+	require.NoError(t, err)
+	require.NoError(t, db.MarkOutboundMessageSent(old.ID, time.Now().Add(-48*time.Hour)))
+	require.NoError(t, db.MarkOutboundMessageSent(recent.ID, time.Now()))
+
+	purged, err := db.PurgeSentOutboundMessages(time.Now().Add(-24 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var count int64
+	gormDB.Unscoped().Model(&OutboundMessage{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMarkOutboundMessageSentUpdatesSubscription(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	require.NoError(t, gormDB.Create(&Repository{Name: "test-repo"}).Error)
+	subscription, err := db.CreateWebhookSubscription("test-repo", "channel1")
+	require.NoError(t, err)
+
+	message, err := db.EnqueueOutboundMessage("channel1", "push", &subscription.ID)
+	require.NoError(t, err)
+	other, err := db.EnqueueOutboundMessage("channel1", "digest", nil)
+	require.NoError(t, err)
+
+	sentAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	require.NoError(t, db.MarkOutboundMessageSent(message.ID, sentAt))
+	require.NoError(t, db.MarkOutboundMessageSent(other.ID, time.Now()))
+
+	subscriptions, err := db.GetWebhookSubscriptionsByChannel("channel1")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	require.NotNil(t, subscriptions[0].LastDeliveredAt)
+	assert.True(t, sentAt.Equal(*subscriptions[0].LastDeliveredAt))
+}
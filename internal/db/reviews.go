@@ -0,0 +1,110 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// UpsertReviewRequest tracks a review requested on a pull request. Requesting
+// a review again from the same reviewer restarts the reminder clock.
+func (d *DB) UpsertReviewRequest(request *ReviewRequest) error {
+	request.ReviewerLogin = strings.ToLower(strings.TrimSpace(request.ReviewerLogin))
+	if request.Repository == "" || request.Number == 0 || request.ReviewerLogin == "" {
+		return fmt.Errorf("repository, number and reviewer cannot be empty")
+	}
+	if request.RequestedAt.IsZero() {
+		request.RequestedAt = time.Now()
+	}
+	request.RemindedAt = nil
+
+	err := d.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "repository"}, {Name: "number"}, {Name: "reviewer_login"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"owner", "title", "url", "author_login", "requested_at", "reminded_at", "updated_at",
+		}),
+	}).Create(request).Error
+	if err != nil {
+		return fmt.Errorf("failed to upsert review request: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteReviewRequest stops tracking the review of a reviewer, e.g. once
+// they submitted it or the request was removed
+func (d *DB) DeleteReviewRequest(repository string, number int, reviewerLogin string) error {
+	err := d.db.Unscoped().
+		Where("repository = ? AND number = ? AND reviewer_login = ?", repository, number, strings.ToLower(reviewerLogin)).
+		Delete(&ReviewRequest{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete review request: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePullRequestReviewRequests stops tracking all the reviews of a pull request
+func (d *DB) DeletePullRequestReviewRequests(repository string, number int) error {
+	err := d.db.Unscoped().
+		Where("repository = ? AND number = ?", repository, number).
+		Delete(&ReviewRequest{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete review requests: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DB) UpdatePullRequestTitle(repository string, number int, title string) error {
+	err := d.db.Model(&ReviewRequest{}).
+		Where("repository = ? AND number = ?", repository, number).
+		Update("title", title).Error
+	if err != nil {
+		return fmt.Errorf("failed to update pull request title: %w", err)
+	}
+
+	return nil
+}
+
+// GetReviewRequestsByReviewer returns the reviews awaiting a GitHub user, oldest first
+func (d *DB) GetReviewRequestsByReviewer(reviewerLogin string) ([]ReviewRequest, error) {
+	requests := make([]ReviewRequest, 0)
+
+	if err := d.db.Where("reviewer_login = ?", strings.ToLower(reviewerLogin)).
+		Order("requested_at").
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get review requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// GetDueReviewReminders returns the reviews of linked users pending since
+// before pendingSince, that haven't been reminded since then either
+func (d *DB) GetDueReviewReminders(pendingSince time.Time) ([]ReviewRequest, error) {
+	requests := make([]ReviewRequest, 0)
+
+	if err := d.db.Where("requested_at <= ?", pendingSince).
+		Where("reminded_at IS NULL OR reminded_at <= ?", pendingSince).
+		Where("reviewer_login IN (?)", d.db.Model(&User{}).Select("github_login").Where("github_login IS NOT NULL")).
+		Order("requested_at").
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due review reminders: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (d *DB) MarkReviewReminded(requestID uint, remindedAt time.Time) error {
+	err := d.db.Model(&ReviewRequest{}).
+		Where("id = ?", requestID).
+		Update("reminded_at", remindedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark review reminded: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertReviewRequest(t *testing.T) {
+	t.Run("requesting again restarts the reminder clock", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		requestedAt := time.Now().Add(-48 * time.Hour)
+		err := db.UpsertReviewRequest(&ReviewRequest{
+			Repository:    "king",
+			Number:        7,
+			ReviewerLogin: "Octocat",
+			Title:         "Old title",
+			RequestedAt:   requestedAt,
+		})
+		require.NoError(t, err)
+
+		var request ReviewRequest
+		require.NoError(t, gormDB.First(&request).Error)
+		require.NoError(t, db.MarkReviewReminded(request.ID, time.Now().Add(-time.Hour)))
+
+		err = db.UpsertReviewRequest(&ReviewRequest{
+			Repository:    "king",
+			Number:        7,
+			ReviewerLogin: "octocat",
+			Title:         "New title",
+		})
+		require.NoError(t, err)
+
+		requests, err := db.GetReviewRequestsByReviewer("OCTOCAT")
+		assert.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "New title", requests[0].Title)
+		assert.Nil(t, requests[0].RemindedAt)
+		assert.True(t, requests[0].RequestedAt.After(requestedAt))
+	})
+
+	t.Run("missing fields should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		err := db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 7})
+		assert.Error(t, err)
+	})
+}
+
+func TestDeleteReviewRequests(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	for _, reviewer := range []string{"alice", "bob"} {
+		require.NoError(t, db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 7, ReviewerLogin: reviewer}))
+	}
+	require.NoError(t, db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 8, ReviewerLogin: "alice"}))
+
+	require.NoError(t, db.DeleteReviewRequest("king", 7, "Alice"))
+	requests, err := db.GetReviewRequestsByReviewer("alice")
+	assert.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, 8, requests[0].Number)
+
+	// A deleted request can be tracked again
+	require.NoError(t, db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 7, ReviewerLogin: "alice"}))
+
+	require.NoError(t, db.DeletePullRequestReviewRequests("king", 7))
+	var count int64
+	gormDB.Unscoped().Model(&ReviewRequest{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestGetDueReviewReminders(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	user := &User{Username: "alice", DiscordID: "111"}
+	require.NoError(t, db.CreateUser(user))
+	_, err := db.LinkGitHubLogin(user.ID, "alice")
+	require.NoError(t, err)
+
+	now := time.Now()
+	threshold := 24 * time.Hour
+	require.NoError(t, db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 1, ReviewerLogin: "alice", RequestedAt: now.Add(-48 * time.Hour)}))
+	require.NoError(t, db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 2, ReviewerLogin: "alice", RequestedAt: now.Add(-time.Hour)}))
+	// Unlinked reviewers can't be reminded
+	require.NoError(t, db.UpsertReviewRequest(&ReviewRequest{Repository: "king", Number: 3, ReviewerLogin: "bob", RequestedAt: now.Add(-48 * time.Hour)}))
+
+	due, err := db.GetDueReviewReminders(now.Add(-threshold))
+	assert.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Number)
+
+	// Once reminded, the review is due again only after another threshold
+	require.NoError(t, db.MarkReviewReminded(due[0].ID, now))
+	due, err = db.GetDueReviewReminders(now.Add(-threshold))
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = db.GetDueReviewReminders(now.Add(threshold).Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, due, 2)
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemberStats are the tasks a member completed in a period
+type MemberStats struct {
+	UserID                uint
+	DiscordID             string
+	Username              string
+	Completed             int64
+	AverageTimeToComplete time.Duration
+}
+
+// TaskStats summarize the tasks of a role, or of every role, since a time
+type TaskStats struct {
+	Role  string
+	Since time.Time
+
+	Created   int64
+	Completed int64
+
+	// AverageTimeToComplete is the time from the creation to the completion
+	// of the completed tasks
+	AverageTimeToComplete time.Duration
+
+	// Started is how many of the completed tasks were in progress before,
+	// and AverageTimeInProgress the time from their first start to their
+	// completion
+	Started               int64
+	AverageTimeInProgress time.Duration
+
+	// Members are the members who completed tasks, the most productive first
+	Members []MemberStats
+}
+
+// secondsBetween is the SQL expression of the seconds between two times
+func secondsBetween(from string, to string) string {
+	return fmt.Sprintf("(julianday(%s) - julianday(%s)) * 86400", to, from)
+}
+
+// averageRow is the result of the queries counting tasks and averaging a
+// duration over them
+type averageRow struct {
+	Count          int64
+	AverageSeconds float64
+}
+
+func (r averageRow) average() time.Duration {
+	return time.Duration(r.AverageSeconds * float64(time.Second))
+}
+
+// GetTaskStats counts the tasks created and completed since a time, and how
+// long it took to complete them. An empty role counts the tasks of all roles
+// and a zero since counts all the tasks.
+func (d *DB) GetTaskStats(role string, since time.Time) (*TaskStats, error) {
+	stats := &TaskStats{Role: role, Since: since, Members: make([]MemberStats, 0)}
+
+	tasks := func() *gorm.DB {
+		query := d.db.Table("tasks").Where("tasks.deleted_at IS NULL")
+		if role != "" {
+			query = query.Where("tasks.role = ?", role)
+		}
+		return query
+	}
+	completed := func() *gorm.DB {
+		query := tasks().Where("tasks.status = ? AND tasks.completed_at IS NOT NULL", TASK_COMPLETED)
+		if !since.IsZero() {
+			query = query.Where("tasks.completed_at >= ?", since)
+		}
+		return query
+	}
+
+	created := tasks()
+	if !since.IsZero() {
+		created = created.Where("tasks.created_at >= ?", since)
+	}
+	if err := created.Count(&stats.Created).Error; err != nil {
+		return nil, fmt.Errorf("failed to count created tasks: %w", err)
+	}
+
+	row := averageRow{}
+	if err := completed().
+		Select("COUNT(*) AS count, COALESCE(AVG(" + secondsBetween("tasks.created_at", "tasks.completed_at") + "), 0) AS average_seconds").
+		Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to get completed tasks: %w", err)
+	}
+	stats.Completed = row.Count
+	stats.AverageTimeToComplete = row.average()
+
+	row = averageRow{}
+	if err := completed().
+		Select("COUNT(*) AS count, COALESCE(AVG("+secondsBetween("starts.started_at", "tasks.completed_at")+"), 0) AS average_seconds").
+		Joins(
+			"JOIN (SELECT task_id, MIN(created_at) AS started_at FROM task_status_changes WHERE to_status = ? AND deleted_at IS NULL GROUP BY task_id) AS starts ON starts.task_id = tasks.id",
+			TASK_IN_PROGRESS,
+		).
+		Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to get started tasks: %w", err)
+	}
+	stats.Started = row.Count
+	stats.AverageTimeInProgress = row.average()
+
+	members := make([]struct {
+		MemberStats
+		AverageSeconds float64
+	}, 0)
+	if err := completed().
+		Select(
+			"users.id AS user_id, users.discord_id, users.username, COUNT(*) AS completed, " +
+				"AVG(" + secondsBetween("tasks.created_at", "tasks.completed_at") + ") AS average_seconds",
+		).
+		Joins("JOIN task_assignments ON task_assignments.task_id = tasks.id").
+		Joins("JOIN users ON users.id = task_assignments.user_id").
+		Group("users.id, users.discord_id, users.username").
+		Order("completed DESC, users.username").
+		Scan(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to get member stats: %w", err)
+	}
+	for _, member := range members {
+		member.MemberStats.AverageTimeToComplete = averageRow{AverageSeconds: member.AverageSeconds}.average()
+		stats.Members = append(stats.Members, member.MemberStats)
+	}
+
+	return stats, nil
+}
+
+// CountUnassignedTasks counts the open tasks nobody is assigned to. An empty
+// role counts the tasks of all roles.
+func (d *DB) CountUnassignedTasks(role string) (int64, error) {
+	var count int64
+
+	query := d.db.Model(&Task{}).Where("status != ?", TASK_COMPLETED).Where(unassignedCondition)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unassigned tasks: %w", err)
+	}
+
+	return count, nil
+}
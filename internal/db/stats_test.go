@@ -0,0 +1,160 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTaskStats(t *testing.T) {
+	t.Run("counts and times the tasks of the period", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		// Create users
+		author := &User{Username: "Author", DiscordID: "author123"}
+		require.NoError(t, db.CreateUser(author))
+		alice := &User{Username: "Alice", DiscordID: "alice456"}
+		require.NoError(t, db.CreateUser(alice))
+		bob := &User{Username: "Bob", DiscordID: "bob789"}
+		require.NoError(t, db.CreateUser(bob))
+
+		since := time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)
+		// Times in other zones are compared by instant
+		cest := time.FixedZone("CEST", 2*60*60)
+		at := func(days int, hours int) time.Time {
+			return since.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour)
+		}
+		timePtr := func(t time.Time) *time.Time { return &t }
+
+		for _, tt := range []struct {
+			title     string
+			role      string
+			assignee  *User
+			createdAt time.Time
+			startedAt *time.Time
+			completed *time.Time
+		}{
+			// Created before the period, started and completed during it
+			{"Wiring", "developer", alice, at(-2, 0), timePtr(at(1, 0)), timePtr(at(1, 6))},
+			// Created and completed during the period, never started
+			{"Firmware", "developer", alice, at(1, 0), nil, timePtr(at(3, 0).In(cest))},
+			// Created and started during the period, still open
+			{"Telemetry", "developer", bob, at(2, 0), timePtr(at(2, 1)), nil},
+			// Completed before the period
+			{"Chassis", "developer", bob, at(-10, 0), nil, timePtr(at(-5, 0))},
+			// Another role
+			{"Livery", "designer", bob, at(1, 0), nil, timePtr(at(2, 0))},
+		} {
+			task := &Task{Title: tt.title, Role: tt.role, Status: TASK_NOT_STARTED, AuthorID: author.ID, AssignedUsers: []User{*tt.assignee}}
+			require.NoError(t, gormDB.Create(task).Error)
+			require.NoError(t, gormDB.Model(task).UpdateColumn("created_at", tt.createdAt).Error)
+
+			if tt.startedAt != nil {
+				change := &TaskStatusChange{TaskID: task.ID, FromStatus: TASK_NOT_STARTED, ToStatus: TASK_IN_PROGRESS}
+				change.CreatedAt = *tt.startedAt
+				require.NoError(t, gormDB.Create(change).Error)
+				require.NoError(t, gormDB.Model(task).UpdateColumn("status", TASK_IN_PROGRESS).Error)
+			}
+			if tt.completed != nil {
+				require.NoError(t, gormDB.Model(task).UpdateColumns(map[string]any{"status": TASK_COMPLETED, "completed_at": *tt.completed}).Error)
+			}
+		}
+
+		stats, err := db.GetTaskStats("developer", since)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), stats.Created)
+		assert.Equal(t, int64(2), stats.Completed)
+		// 3 days 6 hours and 2 days
+		assert.Equal(t, 63*time.Hour, stats.AverageTimeToComplete.Round(time.Second))
+		assert.Equal(t, int64(1), stats.Started)
+		assert.Equal(t, 6*time.Hour, stats.AverageTimeInProgress.Round(time.Second))
+
+		require.Len(t, stats.Members, 1)
+		assert.Equal(t, alice.DiscordID, stats.Members[0].DiscordID)
+		assert.Equal(t, int64(2), stats.Members[0].Completed)
+		assert.Equal(t, 63*time.Hour, stats.Members[0].AverageTimeToComplete.Round(time.Second))
+
+		// All roles and all time
+		stats, err = db.GetTaskStats("", time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(5), stats.Created)
+		assert.Equal(t, int64(4), stats.Completed)
+		// Members with as many completed tasks are sorted by name
+		require.Len(t, stats.Members, 2)
+		assert.Equal(t, alice.DiscordID, stats.Members[0].DiscordID)
+		assert.Equal(t, bob.DiscordID, stats.Members[1].DiscordID)
+		assert.Equal(t, int64(2), stats.Members[1].Completed)
+	})
+
+	t.Run("no tasks", func(t *testing.T) {
+		db := NewDB(CreateTestDB())
+
+		stats, err := db.GetTaskStats("developer", time.Now().AddDate(0, 0, -7))
+		require.NoError(t, err)
+
+		assert.Zero(t, stats.Created)
+		assert.Zero(t, stats.Completed)
+		assert.Zero(t, stats.AverageTimeToComplete)
+		assert.Empty(t, stats.Members)
+	})
+}
+
+func TestStatusChangesAreRecorded(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	author := &User{Username: "Author", DiscordID: "author123"}
+	require.NoError(t, db.CreateUser(author))
+	claimer := &User{Username: "Claimer", DiscordID: "claimer456"}
+	require.NoError(t, db.CreateUser(claimer))
+
+	task := &Task{Title: "Wiring", Role: "developer", AuthorID: author.ID}
+	require.NoError(t, gormDB.Create(task).Error)
+
+	_, err := db.ClaimTask(task.ID, claimer.ID, 0)
+	require.NoError(t, err)
+	_, err = db.UpdateTaskStatus(task.ID, TASK_IN_PROGRESS)
+	require.NoError(t, err)
+	_, err = db.UpdateTaskStatus(task.ID, TASK_COMPLETED)
+	require.NoError(t, err)
+
+	changes := make([]TaskStatusChange, 0)
+	require.NoError(t, gormDB.Where("task_id = ?", task.ID).Order("id").Find(&changes).Error)
+
+	// Updates that keep the status are not transitions
+	require.Len(t, changes, 2)
+	assert.Equal(t, TASK_NOT_STARTED, changes[0].FromStatus)
+	assert.Equal(t, TASK_IN_PROGRESS, changes[0].ToStatus)
+	assert.Equal(t, TASK_IN_PROGRESS, changes[1].FromStatus)
+	assert.Equal(t, TASK_COMPLETED, changes[1].ToStatus)
+}
+
+func TestCountUnassignedTasks(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	author := &User{Username: "Author", DiscordID: "author123"}
+	require.NoError(t, db.CreateUser(author))
+
+	for _, task := range []*Task{
+		{Title: "Unassigned", Role: "developer", Status: TASK_NOT_STARTED, AuthorID: author.ID},
+		{Title: "Assigned", Role: "developer", Status: TASK_IN_PROGRESS, AuthorID: author.ID, AssignedUsers: []User{*author}},
+		{Title: "Completed", Role: "developer", Status: TASK_COMPLETED, AuthorID: author.ID},
+		{Title: "Other Role", Role: "designer", Status: TASK_NOT_STARTED, AuthorID: author.ID},
+	} {
+		require.NoError(t, gormDB.Create(task).Error)
+	}
+
+	count, err := db.CountUnassignedTasks("developer")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = db.CountUnassignedTasks("")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
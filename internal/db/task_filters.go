@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskFilter selects tasks. Its zero value selects every task.
+type TaskFilter struct {
+	Role string
+	// Statuses selects the tasks in any of the statuses
+	Statuses []string
+	// AssigneeID selects the tasks assigned to a user
+	AssigneeID uint
+	// Unassigned selects the tasks nobody is assigned to
+	Unassigned bool
+	AuthorID   uint
+
+	// DueAfter and DueBefore select the tasks due in a range of dates,
+	// both included
+	DueAfter  *time.Time
+	DueBefore *time.Time
+	// HasDueDate selects the tasks with a due date
+	HasDueDate bool
+	// UpdatedSince selects the tasks changed since a time
+	UpdatedSince *time.Time
+}
+
+func (f TaskFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Role != "" {
+		query = query.Where("tasks.role = ?", f.Role)
+	}
+	if len(f.Statuses) > 0 {
+		query = query.Where("tasks.status IN ?", f.Statuses)
+	}
+	if f.AssigneeID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM task_assignments WHERE task_assignments.task_id = tasks.id AND task_assignments.user_id = ?)", f.AssigneeID)
+	}
+	if f.Unassigned {
+		query = query.Where(unassignedCondition)
+	}
+	if f.AuthorID != 0 {
+		query = query.Where("tasks.author_id = ?", f.AuthorID)
+	}
+	if f.DueAfter != nil {
+		query = query.Where("tasks.due_date >= ?", *f.DueAfter)
+	}
+	if f.DueBefore != nil {
+		query = query.Where("tasks.due_date <= ?", *f.DueBefore)
+	}
+	if f.HasDueDate {
+		query = query.Where("tasks.due_date IS NOT NULL")
+	}
+	if f.UpdatedSince != nil {
+		query = query.Where("tasks.updated_at >= ?", *f.UpdatedSince)
+	}
+	return query
+}
+
+// FindTasks returns a page of the tasks selected by a filter, by ID, with
+// their author and assignees, and how many tasks the filter selects in all
+func (d *DB) FindTasks(filter TaskFilter, offset int, limit int) ([]Task, int64, error) {
+	tasks := make([]Task, 0)
+	var total int64
+
+	if err := filter.apply(d.db.Model(&Task{})).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	if err := filter.apply(d.db.Preload("Author").Preload("AssignedUsers")).
+		Order("tasks.id").
+		Offset(offset).
+		Limit(limit).
+		Find(&tasks).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	return tasks, total, nil
+}
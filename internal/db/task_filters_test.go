@@ -0,0 +1,73 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindTasks(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	author := &User{Username: "Author", DiscordID: "author123"}
+	require.NoError(t, db.CreateUser(author))
+	alice := &User{Username: "Alice", DiscordID: "alice456"}
+	require.NoError(t, db.CreateUser(alice))
+
+	later := time.Now().Add(time.Hour)
+	date := func(day int) *time.Time {
+		d := time.Date(2026, 11, day, 0, 0, 0, 0, time.Local)
+		return &d
+	}
+	for _, task := range []*Task{
+		{Title: "Wiring", Role: "developer", Status: TASK_NOT_STARTED, DueDate: date(2), AuthorID: author.ID, AssignedUsers: []User{*alice}},
+		{Title: "Firmware", Role: "developer", Status: TASK_IN_PROGRESS, DueDate: date(10), AuthorID: alice.ID},
+		{Title: "Telemetry", Role: "developer", Status: TASK_COMPLETED, AuthorID: author.ID, AssignedUsers: []User{*alice}},
+		{Title: "Livery", Role: "designer", Status: TASK_NOT_STARTED, DueDate: date(5), AuthorID: author.ID},
+	} {
+		require.NoError(t, gormDB.Create(task).Error)
+	}
+
+	titles := func(tasks []Task) []string {
+		titles := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	for _, tt := range []struct {
+		name     string
+		filter   TaskFilter
+		expected []string
+	}{
+		{"all", TaskFilter{}, []string{"Wiring", "Firmware", "Telemetry", "Livery"}},
+		{"role", TaskFilter{Role: "developer"}, []string{"Wiring", "Firmware", "Telemetry"}},
+		{"statuses", TaskFilter{Statuses: []string{TASK_NOT_STARTED, TASK_IN_PROGRESS}}, []string{"Wiring", "Firmware", "Livery"}},
+		{"assignee", TaskFilter{AssigneeID: alice.ID}, []string{"Wiring", "Telemetry"}},
+		{"unassigned", TaskFilter{Unassigned: true, Role: "developer"}, []string{"Firmware"}},
+		{"author", TaskFilter{AuthorID: alice.ID}, []string{"Firmware"}},
+		{"due dates", TaskFilter{DueAfter: date(2), DueBefore: date(5)}, []string{"Wiring", "Livery"}},
+		{"has due date", TaskFilter{HasDueDate: true, Role: "developer"}, []string{"Wiring", "Firmware"}},
+		{"updated since", TaskFilter{UpdatedSince: &later}, []string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, total, err := db.FindTasks(tt.filter, 0, 10)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, titles(tasks))
+			assert.Equal(t, int64(len(tt.expected)), total)
+		})
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		tasks, total, err := db.FindTasks(TaskFilter{}, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), total)
+		assert.Equal(t, []string{"Firmware", "Telemetry"}, titles(tasks))
+		assert.Equal(t, "Author", tasks[1].Author.Username)
+		assert.Len(t, tasks[1].AssignedUsers, 1)
+	})
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTaskWebhookURLTaken = errors.New("a task webhook with this URL already exists")
+	ErrTaskWebhookNotFound = errors.New("task webhook not found")
+)
+
+func (d *DB) CreateTaskWebhook(url string, secret string, createdByID uint) (*TaskWebhook, error) {
+	if url == "" || secret == "" {
+		return nil, fmt.Errorf("URL and secret cannot be empty")
+	}
+
+	var taken int64
+	if err := d.db.Model(&TaskWebhook{}).Where("url = ?", url).Count(&taken).Error; err != nil {
+		return nil, fmt.Errorf("failed to check task webhook URL: %w", err)
+	}
+	if taken > 0 {
+		return nil, ErrTaskWebhookURLTaken
+	}
+
+	webhook := &TaskWebhook{URL: url, Secret: secret, CreatedByID: createdByID}
+	if err := d.db.Create(webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create task webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// GetTaskWebhooks returns every task webhook, with the user who created it
+func (d *DB) GetTaskWebhooks() ([]TaskWebhook, error) {
+	webhooks := make([]TaskWebhook, 0)
+
+	if err := d.db.Preload("CreatedBy").Order("id").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get task webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteTaskWebhook removes a task webhook and the deliveries still queued
+// for it
+func (d *DB) DeleteTaskWebhook(webhookID uint) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Delete(&TaskWebhook{}, webhookID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete task webhook: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrTaskWebhookNotFound
+		}
+
+		if err := tx.Unscoped().Where("webhook_id = ?", webhookID).Delete(&TaskWebhookDelivery{}).Error; err != nil {
+			return fmt.Errorf("failed to delete task webhook deliveries: %w", err)
+		}
+		return nil
+	})
+}
+
+// EnqueueTaskEvent queues the delivery of an event to every task webhook and
+// returns how many deliveries were queued
+func (d *DB) EnqueueTaskEvent(event string, payload []byte) (int, error) {
+	webhookIDs := make([]uint, 0)
+	if err := d.db.Model(&TaskWebhook{}).Order("id").Pluck("id", &webhookIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to get task webhooks: %w", err)
+	}
+	if len(webhookIDs) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	deliveries := make([]TaskWebhookDelivery, 0, len(webhookIDs))
+	for _, webhookID := range webhookIDs {
+		deliveries = append(deliveries, TaskWebhookDelivery{
+			WebhookID:     webhookID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        OUTBOUND_PENDING,
+			NextAttemptAt: now,
+		})
+	}
+	if err := d.db.Create(&deliveries).Error; err != nil {
+		return 0, fmt.Errorf("failed to enqueue task event: %w", err)
+	}
+
+	return len(deliveries), nil
+}
+
+// GetDueTaskWebhookDeliveries returns the pending deliveries that can be
+// attempted at now, with their webhook. Like the outbound messages of a
+// channel, the events of a webhook are delivered one at a time, in order.
+func (d *DB) GetDueTaskWebhookDeliveries(now time.Time, limit int) ([]TaskWebhookDelivery, error) {
+	deliveries := make([]TaskWebhookDelivery, 0)
+
+	oldestPerWebhook := d.db.Model(&TaskWebhookDelivery{}).
+		Select("MIN(id)").
+		Where("status = ?", OUTBOUND_PENDING).
+		Group("webhook_id")
+
+	if err := d.db.Preload("Webhook").
+		Where("id IN (?) AND next_attempt_at <= ?", oldestPerWebhook, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due task webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (d *DB) MarkTaskWebhookDeliverySent(delivery *TaskWebhookDelivery, sentAt time.Time) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TaskWebhookDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]any{
+				"status":     OUTBOUND_SENT,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"sent_at":    sentAt,
+				"last_error": "",
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&TaskWebhook{}).
+			Where("id = ?", delivery.WebhookID).
+			Updates(map[string]any{
+				"last_delivered_at": sentAt,
+				"last_error":        "",
+			}).Error
+	})
+}
+
+// RetryTaskWebhookDelivery records a failed attempt and schedules the next one
+func (d *DB) RetryTaskWebhookDelivery(delivery *TaskWebhookDelivery, nextAttemptAt time.Time, lastError string) error {
+	return d.updateFailedTaskWebhookDelivery(delivery, map[string]any{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	})
+}
+
+// FailTaskWebhookDelivery records a failed attempt and gives up on the delivery
+func (d *DB) FailTaskWebhookDelivery(delivery *TaskWebhookDelivery, lastError string) error {
+	return d.updateFailedTaskWebhookDelivery(delivery, map[string]any{
+		"status":     OUTBOUND_FAILED,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastError,
+	})
+}
+
+func (d *DB) updateFailedTaskWebhookDelivery(delivery *TaskWebhookDelivery, updates map[string]any) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&TaskWebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&TaskWebhook{}).
+			Where("id = ?", delivery.WebhookID).
+			Update("last_error", updates["last_error"]).Error
+	})
+}
+
+// PurgeSentTaskWebhookDeliveries permanently deletes the deliveries sent
+// before sentBefore
+func (d *DB) PurgeSentTaskWebhookDeliveries(sentBefore time.Time) (int64, error) {
+	result := d.db.Unscoped().
+		Where("status = ? AND sent_at < ?", OUTBOUND_SENT, sentBefore).
+		Delete(&TaskWebhookDelivery{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge sent task webhook deliveries: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
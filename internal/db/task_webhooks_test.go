@@ -0,0 +1,120 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskWebhooks(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	admin := &User{Username: "Admin", DiscordID: "admin123"}
+	require.NoError(t, db.CreateUser(admin))
+
+	webhook, err := db.CreateTaskWebhook("https://sheets.example.com/hook", "secret", admin.ID)
+	require.NoError(t, err)
+
+	_, err = db.CreateTaskWebhook("https://sheets.example.com/hook", "other", admin.ID)
+	assert.ErrorIs(t, err, ErrTaskWebhookURLTaken)
+	_, err = db.CreateTaskWebhook("", "secret", admin.ID)
+	assert.Error(t, err)
+
+	other, err := db.CreateTaskWebhook("https://ci.example.com/hook", "secret", admin.ID)
+	require.NoError(t, err)
+
+	webhooks, err := db.GetTaskWebhooks()
+	require.NoError(t, err)
+	require.Len(t, webhooks, 2)
+	assert.Equal(t, webhook.ID, webhooks[0].ID)
+	assert.Equal(t, "Admin", webhooks[0].CreatedBy.Username)
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := db.EnqueueTaskEvent(TASK_EVENT_CREATED, []byte("{}"))
+		require.NoError(t, err)
+
+		require.NoError(t, db.DeleteTaskWebhook(other.ID))
+		assert.ErrorIs(t, db.DeleteTaskWebhook(other.ID), ErrTaskWebhookNotFound)
+
+		// The queued deliveries of the webhook are dropped with it
+		var queued int64
+		require.NoError(t, gormDB.Model(&TaskWebhookDelivery{}).Where("webhook_id = ?", other.ID).Count(&queued).Error)
+		assert.Zero(t, queued)
+
+		require.NoError(t, gormDB.Where("1 = 1").Delete(&TaskWebhookDelivery{}).Error)
+	})
+}
+
+func TestTaskWebhookDeliveries(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	t.Run("no webhooks", func(t *testing.T) {
+		queued, err := db.EnqueueTaskEvent(TASK_EVENT_CREATED, []byte("{}"))
+		require.NoError(t, err)
+		assert.Zero(t, queued)
+	})
+
+	first, err := db.CreateTaskWebhook("https://sheets.example.com/hook", "secret", 1)
+	require.NoError(t, err)
+	second, err := db.CreateTaskWebhook("https://ci.example.com/hook", "secret", 1)
+	require.NoError(t, err)
+
+	queued, err := db.EnqueueTaskEvent(TASK_EVENT_CREATED, []byte(`{"event":"task.created"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, queued)
+	_, err = db.EnqueueTaskEvent(TASK_EVENT_DELETED, []byte(`{"event":"task.deleted"}`))
+	require.NoError(t, err)
+
+	// Only the oldest pending delivery of each webhook is due
+	deliveries, err := db.GetDueTaskWebhookDeliveries(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, first.ID, deliveries[0].WebhookID)
+	assert.Equal(t, "secret", deliveries[0].Webhook.Secret)
+	assert.Equal(t, TASK_EVENT_CREATED, deliveries[0].Event)
+	assert.Equal(t, TASK_EVENT_CREATED, deliveries[1].Event)
+
+	sentAt := time.Now().Truncate(time.Second)
+	require.NoError(t, db.MarkTaskWebhookDeliverySent(&deliveries[0], sentAt))
+	require.NoError(t, db.RetryTaskWebhookDelivery(&deliveries[1], time.Now().Add(time.Minute), "502 Bad Gateway"))
+
+	deliveries, err = db.GetDueTaskWebhookDeliveries(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, first.ID, deliveries[0].WebhookID)
+	assert.Equal(t, TASK_EVENT_DELETED, deliveries[0].Event)
+
+	webhooks, err := db.GetTaskWebhooks()
+	require.NoError(t, err)
+	require.NotNil(t, webhooks[0].LastDeliveredAt)
+	assert.True(t, sentAt.Equal(*webhooks[0].LastDeliveredAt))
+	assert.Equal(t, "502 Bad Gateway", webhooks[1].LastError)
+
+	// The retried delivery is due again later
+	deliveries, err = db.GetDueTaskWebhookDeliveries(time.Now().Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	retried := deliveries[1]
+	assert.Equal(t, second.ID, retried.WebhookID)
+	assert.Equal(t, 1, retried.Attempts)
+
+	require.NoError(t, db.FailTaskWebhookDelivery(&retried, "404 Not Found"))
+	deliveries, err = db.GetDueTaskWebhookDeliveries(time.Now().Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, TASK_EVENT_DELETED, deliveries[1].Event)
+
+	t.Run("purge", func(t *testing.T) {
+		purged, err := db.PurgeSentTaskWebhookDeliveries(sentAt)
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+
+		purged, err = db.PurgeSentTaskWebhookDeliveries(sentAt.Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+	})
+}
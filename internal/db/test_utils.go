@@ -11,6 +11,16 @@ import (
 var testDBCounter int64
 
 func CreateTestDB() *gorm.DB {
+	db := openTestDB()
+	if err := Migrate(db); err != nil {
+		panic(err)
+	}
+
+	return db
+}
+
+// openTestDB opens an empty test database, without its tables
+func openTestDB() *gorm.DB {
 	// Use a unique database name for each test to avoid concurrency issues
 	counter := atomic.AddInt64(&testDBCounter, 1)
 	dbName := fmt.Sprintf("file:test_%d.db?mode=memory&cache=shared", counter)
@@ -19,8 +29,13 @@ func CreateTestDB() *gorm.DB {
 	if err != nil {
 		panic(err)
 	}
-
-	db.AutoMigrate(&User{}, &Task{}, &TaskComment{}, &WebhookSubscription{}, &Repository{})
+	// Connections to a shared cache database fail with "database table is
+	// locked" instead of waiting for each other, so the tests use just one
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
 
 	return db
 }
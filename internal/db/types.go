@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -10,12 +12,87 @@ const (
 	TASK_COMPLETED   = "Completed"
 )
 
+const (
+	TASK_FIELD_TITLE       = "title"
+	TASK_FIELD_DESCRIPTION = "description"
+	TASK_FIELD_DUE_DATE    = "due_date"
+)
+
+// Events a member can receive a notification for
+const (
+	NOTIFICATION_EVENT_ASSIGNED = "assigned"
+	NOTIFICATION_EVENT_STATUS   = "status"
+	NOTIFICATION_EVENT_EDITED   = "edited"
+	NOTIFICATION_EVENT_COMMENT  = "comment"
+	NOTIFICATION_EVENT_CLAIMED  = "claimed"
+	NOTIFICATION_EVENT_DELETED  = "deleted"
+	NOTIFICATION_EVENT_REVIEW   = "review"
+)
+
+// How a member receives the notifications of an event
+const (
+	NOTIFICATION_METHOD_DM      = "dm"
+	NOTIFICATION_METHOD_MENTION = "mention"
+	NOTIFICATION_METHOD_NONE    = "none"
+)
+
+var NotificationEvents = []string{
+	NOTIFICATION_EVENT_ASSIGNED,
+	NOTIFICATION_EVENT_STATUS,
+	NOTIFICATION_EVENT_EDITED,
+	NOTIFICATION_EVENT_COMMENT,
+	NOTIFICATION_EVENT_CLAIMED,
+	NOTIFICATION_EVENT_DELETED,
+	NOTIFICATION_EVENT_REVIEW,
+}
+
+var NotificationMethods = []string{
+	NOTIFICATION_METHOD_DM,
+	NOTIFICATION_METHOD_MENTION,
+	NOTIFICATION_METHOD_NONE,
+}
+
+// Delivery states of an outbound message
+const (
+	OUTBOUND_PENDING = "pending"
+	OUTBOUND_SENT    = "sent"
+	OUTBOUND_FAILED  = "failed"
+)
+
+// Processing states of a GitHub webhook delivery
+const (
+	WEBHOOK_DELIVERY_RECEIVED   = "received"
+	WEBHOOK_DELIVERY_PROCESSING = "processing"
+	WEBHOOK_DELIVERY_PROCESSED  = "processed"
+	WEBHOOK_DELIVERY_IGNORED    = "ignored"
+	WEBHOOK_DELIVERY_FAILED     = "failed"
+)
+
+// Events of the tasks posted to the task webhooks
+const (
+	TASK_EVENT_CREATED        = "task.created"
+	TASK_EVENT_ASSIGNED       = "task.assigned"
+	TASK_EVENT_STATUS_CHANGED = "task.status_changed"
+	TASK_EVENT_DELETED        = "task.deleted"
+)
+
+// DueDateFormat is the layout used to read and display task due dates
+const DueDateFormat = "2006-01-02"
+
 type User struct {
 	gorm.Model
 
 	Username  string `gorm:"unique"`
 	DiscordID string `gorm:"unique"`
 
+	// GitHubLogin is the verified GitHub account of the user, lowercased.
+	// It is nil until the user links an account.
+	GitHubLogin *string `gorm:"column:github_login;uniqueIndex"`
+
+	// Locale is the language the user chose for the bot messages, e.g. "it".
+	// It is empty to follow the language of their Discord client.
+	Locale string
+
 	AssignedTasks []Task `gorm:"many2many:task_assignments"`
 	CreatedTasks  []Task `gorm:"foreignKey:AuthorID"`
 }
@@ -27,6 +104,14 @@ type Task struct {
 	Description string
 	Role        string
 	Status      string `gorm:"default:Not Started"`
+	DueDate     *time.Time
+	CompletedAt *time.Time
+
+	// IssueRepository and IssueNumber identify the GitHub issue opened from
+	// the task, if any. Its state is kept in sync with the task status.
+	IssueRepository string `gorm:"index:idx_task_issue"`
+	IssueNumber     int    `gorm:"index:idx_task_issue;not null;default:0"`
+	IssueURL        string
 
 	AuthorID uint
 	Author   User
@@ -34,6 +119,7 @@ type Task struct {
 	AssignedUsers []User `gorm:"many2many:task_assignments"`
 
 	Comments []TaskComment
+	Changes  []TaskChange
 }
 
 type TaskComment struct {
@@ -41,7 +127,76 @@ type TaskComment struct {
 
 	Text string
 
-	TaskID uint
+	// CommitSHA is set on the comments linking a commit to the task
+	CommitSHA string `gorm:"index"`
+
+	TaskID   uint
+	AuthorID uint
+	Author   User
+}
+
+type TaskChange struct {
+	gorm.Model
+
+	Field    string
+	OldValue string
+	NewValue string
+
+	TaskID   uint
+	AuthorID uint
+	Author   User
+}
+
+// TaskStatusChange records a status transition of a task, made at CreatedAt.
+// The transitions measure how long the tasks stay in progress.
+type TaskStatusChange struct {
+	gorm.Model
+
+	TaskID     uint `gorm:"index"`
+	FromStatus string
+	ToStatus   string
+}
+
+// GitHubLinkRequest is a pending link of a GitHub account, verified by
+// publishing Code on the GitHub account before ExpiresAt
+type GitHubLinkRequest struct {
+	gorm.Model
+
+	UserID    uint `gorm:"uniqueIndex"`
+	Login     string
+	Code      string
+	ExpiresAt time.Time
+}
+
+// ReviewRequest is a review requested to a GitHub user on an open pull request
+type ReviewRequest struct {
+	gorm.Model
+
+	Repository    string `gorm:"uniqueIndex:idx_review_request"`
+	Number        int    `gorm:"uniqueIndex:idx_review_request"`
+	ReviewerLogin string `gorm:"uniqueIndex:idx_review_request;index"`
+	Owner         string
+	Title         string
+	URL           string
+	AuthorLogin   string
+	RequestedAt   time.Time
+	RemindedAt    *time.Time
+}
+
+type DigestSchedule struct {
+	gorm.Model
+
+	ChannelID    string `gorm:"uniqueIndex:idx_digest_channel_id_role"`
+	Role         string `gorm:"uniqueIndex:idx_digest_channel_id_role"`
+	LastPostedAt *time.Time
+}
+
+type NotificationPreference struct {
+	gorm.Model
+
+	UserID uint   `gorm:"uniqueIndex:idx_notification_user_id_event"`
+	Event  string `gorm:"uniqueIndex:idx_notification_user_id_event"`
+	Method string `gorm:"default:dm"`
 }
 
 type WebhookSubscription struct {
@@ -50,6 +205,113 @@ type WebhookSubscription struct {
 	ChannelID    string `gorm:"uniqueIndex:idx_channel_id_repository_id"`
 	RepositoryID uint   `gorm:"uniqueIndex:idx_channel_id_repository_id"`
 	Repository   Repository
+
+	// BranchFilter limits the pushes posted to the branches matching it, as
+	// a path.Match pattern like "release/*". Empty matches every branch.
+	BranchFilter string
+
+	// LastDeliveredAt is when a message of the subscription was last posted
+	LastDeliveredAt *time.Time
+
+	// Subscriptions are disabled when their channel can't be reached anymore
+	Disabled       bool `gorm:"not null;default:false"`
+	DisabledReason string
+}
+
+type OutboundMessage struct {
+	gorm.Model
+
+	ChannelID     string `gorm:"index"`
+	Content       string
+	Status        string `gorm:"default:pending;index"`
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	SentAt        *time.Time
+
+	SubscriptionID *uint
+}
+
+type WebhookDelivery struct {
+	gorm.Model
+
+	DeliveryID  string `gorm:"uniqueIndex"`
+	Event       string
+	Repository  string
+	Payload     string
+	Status      string `gorm:"default:received;index"`
+	Outcome     string
+	ProcessedAt *time.Time
+}
+
+// APIToken grants access to the REST API. Only the SHA-256 hash of the
+// token is stored: the token itself is shown once, when it is created.
+type APIToken struct {
+	gorm.Model
+
+	// Name tells the admins what the token is used by, e.g. "grafana"
+	Name      string `gorm:"uniqueIndex"`
+	TokenHash string `gorm:"uniqueIndex"`
+	// Prefix is the start of the token, to recognize it without storing it
+	Prefix string
+	// Write lets the token create and update tasks, not only read them
+	Write bool
+
+	CreatedByID uint
+	CreatedBy   User
+
+	LastUsedAt *time.Time
+}
+
+// TaskWebhook is an HTTP endpoint the task events are posted to. Unlike API
+// tokens, the secret is stored as is, as it signs every delivery.
+type TaskWebhook struct {
+	gorm.Model
+
+	URL    string `gorm:"uniqueIndex"`
+	Secret string
+
+	CreatedByID uint
+	CreatedBy   User
+
+	// LastDeliveredAt is when an event was last accepted by the endpoint, and
+	// LastError why the last attempt failed, if it did
+	LastDeliveredAt *time.Time
+	LastError       string
+}
+
+// TaskWebhookDelivery is an event queued for a task webhook. Its states are
+// the ones of the outbound messages.
+type TaskWebhookDelivery struct {
+	gorm.Model
+
+	WebhookID uint `gorm:"index"`
+	Webhook   TaskWebhook
+
+	Event         string
+	Payload       string
+	Status        string `gorm:"default:pending;index"`
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	SentAt        *time.Time
+}
+
+// CalendarFeed is an iCalendar feed of the due dates of the open tasks of
+// a user, or of a role. Its URL holds a secret token, and like API tokens
+// only the SHA-256 hash of the token is stored.
+type CalendarFeed struct {
+	gorm.Model
+
+	TokenHash string `gorm:"uniqueIndex"`
+
+	// UserID is the user the feed belongs to. Role is the role whose tasks
+	// are in the feed, or empty for the tasks assigned to the user.
+	UserID uint `gorm:"index"`
+	User   User
+	Role   string
+
+	LastFetchedAt *time.Time
 }
 
 type Repository struct {
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// RecordWebhookDelivery stores a received delivery to be processed. It
+// returns false, without storing anything, if a delivery with the same ID has
+// already been received: GitHub redelivers events whose delivery timed out.
+func (d *DB) RecordWebhookDelivery(deliveryID string, event string, repository string, payload []byte) (*WebhookDelivery, bool, error) {
+	if deliveryID == "" {
+		return nil, false, fmt.Errorf("delivery ID cannot be empty")
+	}
+
+	delivery := &WebhookDelivery{
+		DeliveryID: deliveryID,
+		Event:      event,
+		Repository: repository,
+		Payload:    string(payload),
+		Status:     WEBHOOK_DELIVERY_RECEIVED,
+	}
+	result := d.db.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to record webhook delivery: %w", result.Error)
+	}
+
+	return delivery, result.RowsAffected == 1, nil
+}
+
+// GetPendingWebhookDeliveries returns the deliveries still to be processed, oldest first
+func (d *DB) GetPendingWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+	deliveries := make([]WebhookDelivery, 0)
+
+	if err := d.db.Where("status = ?", WEBHOOK_DELIVERY_RECEIVED).
+		Order("id").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDelivery marks a pending delivery as being processed, so that
+// it isn't processed again if its outcome can't be recorded. It returns false
+// if the delivery isn't pending anymore.
+func (d *DB) ClaimWebhookDelivery(deliveryID uint) (bool, error) {
+	result := d.db.Model(&WebhookDelivery{}).
+		Where("id = ? AND status = ?", deliveryID, WEBHOOK_DELIVERY_RECEIVED).
+		Update("status", WEBHOOK_DELIVERY_PROCESSING)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// FailInterruptedWebhookDeliveries marks the deliveries left being processed,
+// e.g. by a restart, as failed, and returns how many there were. They may
+// have run some of their side effects already, so they aren't processed
+// again.
+func (d *DB) FailInterruptedWebhookDeliveries() (int64, error) {
+	result := d.db.Model(&WebhookDelivery{}).
+		Where("status = ?", WEBHOOK_DELIVERY_PROCESSING).
+		Updates(map[string]any{
+			"status":       WEBHOOK_DELIVERY_FAILED,
+			"outcome":      "interrupted",
+			"payload":      "",
+			"processed_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail interrupted webhook deliveries: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// CompleteWebhookDelivery records the outcome of a processed delivery. The
+// payload isn't needed anymore and is dropped.
+func (d *DB) CompleteWebhookDelivery(deliveryID uint, status string, outcome string) error {
+	if status == WEBHOOK_DELIVERY_RECEIVED || status == WEBHOOK_DELIVERY_PROCESSING {
+		return fmt.Errorf("invalid delivery status: %s", status)
+	}
+
+	return d.db.Model(&WebhookDelivery{}).
+		Where("id = ?", deliveryID).
+		Updates(map[string]any{
+			"status":       status,
+			"outcome":      outcome,
+			"payload":      "",
+			"processed_at": time.Now(),
+		}).Error
+}
+
+func (d *DB) GetRecentWebhookDeliveries(limit int) ([]WebhookDelivery, error) {
+	deliveries := make([]WebhookDelivery, 0)
+
+	if err := d.db.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// PurgeWebhookDeliveries permanently deletes the processed deliveries
+// received before receivedBefore
+func (d *DB) PurgeWebhookDeliveries(receivedBefore time.Time) (int64, error) {
+	result := d.db.Unscoped().
+		Where("status NOT IN ? AND created_at < ?", []string{WEBHOOK_DELIVERY_RECEIVED, WEBHOOK_DELIVERY_PROCESSING}, receivedBefore).
+		Delete(&WebhookDelivery{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordWebhookDelivery(t *testing.T) {
+	t.Run("successful recording", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		delivery, created, err := db.RecordWebhookDelivery("delivery-1", "push", "test-repo", []byte(`{"ref":"refs/heads/main"}`))
+		assert.NoError(t, err)
+		assert.True(t, created)
+		require.NotNil(t, delivery)
+		assert.NotZero(t, delivery.ID)
+		assert.Equal(t, WEBHOOK_DELIVERY_RECEIVED, delivery.Status)
+		assert.Equal(t, `{"ref":"refs/heads/main"}`, delivery.Payload)
+	})
+
+	t.Run("redelivery is deduplicated", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, created, err := db.RecordWebhookDelivery("delivery-1", "push", "test-repo", []byte("{}"))
+		require.NoError(t, err)
+		require.True(t, created)
+
+		_, created, err = db.RecordWebhookDelivery("delivery-1", "push", "test-repo", []byte("{}"))
+		assert.NoError(t, err)
+		assert.False(t, created)
+
+		var count int64
+		gormDB.Model(&WebhookDelivery{}).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("empty delivery ID should fail", func(t *testing.T) {
+		gormDB := CreateTestDB()
+		db := NewDB(gormDB)
+
+		_, _, err := db.RecordWebhookDelivery("", "push", "test-repo", []byte("{}"))
+		assert.Error(t, err)
+	})
+}
+
+func TestCompleteWebhookDelivery(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	first, _, err := db.RecordWebhookDelivery("delivery-1", "push", "test-repo", []byte("{}"))
+	require.NoError(t, err)
+	second, _, err := db.RecordWebhookDelivery("delivery-2", "ping", "test-repo", []byte("{}"))
+	require.NoError(t, err)
+
+	pending, err := db.GetPendingWebhookDeliveries(10)
+	assert.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, first.ID, pending[0].ID)
+
+	err = db.CompleteWebhookDelivery(first.ID, WEBHOOK_DELIVERY_PROCESSED, "queued for 2 channel(s)")
+	assert.NoError(t, err)
+
+	err = db.CompleteWebhookDelivery(second.ID, WEBHOOK_DELIVERY_RECEIVED, "")
+	assert.Error(t, err)
+
+	pending, err = db.GetPendingWebhookDeliveries(10)
+	assert.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+
+	recent, err := db.GetRecentWebhookDeliveries(10)
+	assert.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, second.ID, recent[0].ID)
+	assert.Equal(t, WEBHOOK_DELIVERY_PROCESSED, recent[1].Status)
+	assert.Equal(t, "queued for 2 channel(s)", recent[1].Outcome)
+	assert.Empty(t, recent[1].Payload)
+	assert.NotNil(t, recent[1].ProcessedAt)
+}
+
+func TestPurgeWebhookDeliveries(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	processed, _, err := db.RecordWebhookDelivery("delivery-1", "push", "test-repo", []byte("{}"))
+	require.NoError(t, err)
+	_, _, err = db.RecordWebhookDelivery("delivery-2", "push", "test-repo", []byte("{}"))
+	require.NoError(t, err)
+	require.NoError(t, db.CompleteWebhookDelivery(processed.ID, WEBHOOK_DELIVERY_PROCESSED, ""))
+
+	// Pending deliveries are never purged
+	purged, err := db.PurgeWebhookDeliveries(time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	pending, err := db.GetPendingWebhookDeliveries(10)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+}
+
+func TestClaimWebhookDelivery(t *testing.T) {
+	gormDB := CreateTestDB()
+	db := NewDB(gormDB)
+
+	delivery, _, err := db.RecordWebhookDelivery("delivery-1", "push", "test-repo", []byte("{}"))
+	require.NoError(t, err)
+
+	claimed, err := db.ClaimWebhookDelivery(delivery.ID)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A claimed delivery isn't pending, nor claimed twice, nor purged
+	pending, err := db.GetPendingWebhookDeliveries(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	claimed, err = db.ClaimWebhookDelivery(delivery.ID)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	purged, err := db.PurgeWebhookDeliveries(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	assert.Error(t, db.CompleteWebhookDelivery(delivery.ID, WEBHOOK_DELIVERY_PROCESSING, ""))
+
+	// After a restart, it failed and isn't processed again
+	failed, err := db.FailInterruptedWebhookDeliveries()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), failed)
+	pending, err = db.GetPendingWebhookDeliveries(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	recent, err := db.GetRecentWebhookDeliveries(10)
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, WEBHOOK_DELIVERY_FAILED, recent[0].Status)
+	assert.Equal(t, "interrupted", recent[0].Outcome)
+
+	failed, err = db.FailInterruptedWebhookDeliveries()
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+}
@@ -1,5 +1,10 @@
 package utils
 
+import (
+	"regexp"
+	"strings"
+)
+
 func H1(text string) string {
 	return "# " + text
 }
@@ -31,3 +36,58 @@ func InlineCode(text string) string {
 func Link(text, url string) string {
 	return "[" + text + "](" + url + ")"
 }
+
+// markdownEscaper escapes the characters Discord interprets as inline
+// markdown. "<" is escaped so that text can't contain user or role mentions,
+// and a zero-width space breaks @everyone and @here.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"~", `\~`,
+	"`", "\\`",
+	"|", `\|`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	"@everyone", "@\u200beveryone",
+	"@here", "@\u200bhere",
+)
+
+// blockMarkdownRegexp matches the headings, lists and quotes, which are only
+// markdown at the start of a line
+var blockMarkdownRegexp = regexp.MustCompile(`(?m)^([ \t]*)(#+ |[-+] |>+ )`)
+
+// EscapeMarkdown escapes user-controlled text, so that it is shown as
+// written instead of being formatted, and can't mention anyone
+func EscapeMarkdown(text string) string {
+	return blockMarkdownRegexp.ReplaceAllString(markdownEscaper.Replace(text), `$1\$2`)
+}
+
+func EscapedBold(text string) string {
+	return Bold(EscapeMarkdown(text))
+}
+
+func EscapedItalic(text string) string {
+	return Italic(EscapeMarkdown(text))
+}
+
+// EscapedInlineCode formats text as code. Markdown is not interpreted in
+// code, but a backtick would end it early: text with backticks is wrapped
+// in double backticks, the way markdown allows.
+func EscapedInlineCode(text string) string {
+	if !strings.Contains(text, "`") {
+		return InlineCode(text)
+	}
+	for strings.Contains(text, "``") {
+		text = strings.ReplaceAll(text, "``", "`\u200b`")
+	}
+	return "`` " + text + " ``"
+}
+
+// EscapedLink formats a link whose text, and URL, can't break the markdown
+func EscapedLink(text, url string) string {
+	return Link(EscapeMarkdown(text), urlEscaper.Replace(url))
+}
+
+var urlEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
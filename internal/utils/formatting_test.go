@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Wire the BMS", "Wire the BMS"},
+		{"**bold** and _italic_", `\*\*bold\*\* and \_italic\_`},
+		{"a `code` ~~strike~~ ||spoiler||", "a \\`code\\` \\~\\~strike\\~\\~ \\|\\|spoiler\\|\\|"},
+		{"[link](https://example.com)", `\[link\](https://example.com)`},
+		{`C:\firmware`, `C:\\firmware`},
+		{"ping @everyone and @here", "ping @\u200beveryone and @\u200bhere"},
+		{"hi <@123> and <@&456>", `hi \<@123> and \<@&456>`},
+		{"# Heading\n> quote\n  - item\n2026-11-02", "\\# Heading\n\\> quote\n  \\- item\n2026-11-02"},
+		{"#12 -1 >2", "#12 -1 >2"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, EscapeMarkdown(tt.text), tt.text)
+	}
+}
+
+func TestEscapedFormatting(t *testing.T) {
+	assert.Equal(t, `**a\*b**`, EscapedBold("a*b"))
+	assert.Equal(t, `_snake\_case_`, EscapedItalic("snake_case"))
+
+	assert.Equal(t, "`feature/can`", EscapedInlineCode("feature/can"))
+	assert.Equal(t, "`` a`b ``", EscapedInlineCode("a`b"))
+	assert.Equal(t, "`` a`\u200b`\u200b`b ``", EscapedInlineCode("a```b"))
+
+	assert.Equal(t, `[\[WIP\] Fix](https://example.com/a%20b%29)`, EscapedLink("[WIP] Fix", "https://example.com/a b)"))
+}